@@ -10,7 +10,7 @@ import (
 )
 
 // DO NOT confuse with CORS allowed methods
-var routerHTTPMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE"}
+var routerHTTPMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS", "HEAD"}
 
 // CleanUpTrailingSlash trims spaces and removes trailing slashes from the URL path if it is longer than 1 character.
 func CleanUpTrailingSlash(p string) string {
@@ -53,19 +53,24 @@ func NewServerMuxRouter() ServerMuxRouter {
 
 // ServerMuxRouter defines the interface for a router that can group routes, handle HTTP requests, and add controllers and routes with specific HTTP methods.
 type ServerMuxRouter interface {
-	Group(string) ServerMuxRouter
+	Group(string, ...Middleware) ServerMuxRouter
 	Handler() http.Handler
 	AddController(Controller, Middleware) error
 	AddGet(string, http.HandlerFunc) error
 	AddPost(string, http.HandlerFunc) error
+	AddPut(string, http.HandlerFunc) error
 	AddPatch(string, http.HandlerFunc) error
 	AddDelete(string, http.HandlerFunc) error
+	AddOptions(string, http.HandlerFunc) error
+	AddHead(string, http.HandlerFunc) error
+	AddRoute(method, pattern string, handler http.HandlerFunc) error
 }
 
 // serverMuxRouterImpl is the concrete implementation of the ServerMuxRouter interface.
 type serverMuxRouterImpl struct {
-	mux   *http.ServeMux
-	group string
+	mux         *http.ServeMux
+	group       string
+	middlewares []Middleware
 }
 
 // Handler returns the underlying http.Handler for the router.
@@ -73,21 +78,27 @@ func (r *serverMuxRouterImpl) Handler() http.Handler {
 	return r.mux
 }
 
-// Group creates a new sub-router with the given group prefix.
-func (r *serverMuxRouterImpl) Group(group string) ServerMuxRouter {
+// Group creates a new sub-router with the given group prefix and middlewares.
+// The routes added to the sub-router are wrapped with the middlewares of its parent groups first, then with the given ones.
+func (r *serverMuxRouterImpl) Group(group string, middlewares ...Middleware) ServerMuxRouter {
 	group = strings.TrimSpace(group)
-	if group == "" {
+	if group == "" && len(middlewares) == 0 {
 		return r
 	}
 
-	newGroup, err := JoinURLPath("/", r.group, group)
-	if err != nil {
-		panic(err)
+	newGroup := r.group
+	if group != "" {
+		var err error
+		newGroup, err = JoinURLPath("/", r.group, group)
+		if err != nil {
+			panic(err)
+		}
 	}
 
 	return &serverMuxRouterImpl{
-		group: newGroup,
-		mux:   r.mux,
+		group:       newGroup,
+		mux:         r.mux,
+		middlewares: append(slices.Clone(r.middlewares), middlewares...),
 	}
 }
 
@@ -98,38 +109,50 @@ func (r *serverMuxRouterImpl) AddController(c Controller, m Middleware) error {
 
 // AddGet registers a GET route with the given pattern and handler.
 func (r *serverMuxRouterImpl) AddGet(pattern string, handler http.HandlerFunc) error {
-	pattern, err := JoinURLPath(r.group, pattern)
-	if err != nil {
-		return err
-	}
-	return r.addRouteWithMethod("GET", pattern, handler)
+	return r.AddRoute(http.MethodGet, pattern, handler)
 }
 
 // AddPost registers a POST route with the given pattern and handler.
 func (r *serverMuxRouterImpl) AddPost(pattern string, handler http.HandlerFunc) error {
-	pattern, err := JoinURLPath(r.group, pattern)
-	if err != nil {
-		return err
-	}
-	return r.addRouteWithMethod("POST", pattern, handler)
+	return r.AddRoute(http.MethodPost, pattern, handler)
+}
+
+// AddPut registers a PUT route with the given pattern and handler.
+func (r *serverMuxRouterImpl) AddPut(pattern string, handler http.HandlerFunc) error {
+	return r.AddRoute(http.MethodPut, pattern, handler)
 }
 
 // AddPatch registers a PATCH route with the given pattern and handler.
 func (r *serverMuxRouterImpl) AddPatch(pattern string, handler http.HandlerFunc) error {
-	pattern, err := JoinURLPath(r.group, pattern)
-	if err != nil {
-		return err
-	}
-	return r.addRouteWithMethod("PATCH", pattern, handler)
+	return r.AddRoute(http.MethodPatch, pattern, handler)
 }
 
 // AddDelete registers a DELETE route with the given pattern and handler.
 func (r *serverMuxRouterImpl) AddDelete(pattern string, handler http.HandlerFunc) error {
+	return r.AddRoute(http.MethodDelete, pattern, handler)
+}
+
+// AddOptions registers an OPTIONS route with the given pattern and handler.
+func (r *serverMuxRouterImpl) AddOptions(pattern string, handler http.HandlerFunc) error {
+	return r.AddRoute(http.MethodOptions, pattern, handler)
+}
+
+// AddHead registers a HEAD route with the given pattern and handler.
+func (r *serverMuxRouterImpl) AddHead(pattern string, handler http.HandlerFunc) error {
+	return r.AddRoute(http.MethodHead, pattern, handler)
+}
+
+// AddRoute registers a route with the given HTTP method, pattern and handler, wrapped with the group middlewares.
+// The method must be one of the methods supported by the router.
+func (r *serverMuxRouterImpl) AddRoute(method, pattern string, handler http.HandlerFunc) error {
 	pattern, err := JoinURLPath(r.group, pattern)
 	if err != nil {
 		return err
 	}
-	return r.addRouteWithMethod("DELETE", pattern, handler)
+	if handler != nil && len(r.middlewares) > 0 {
+		handler = Chain(r.middlewares...)(handler)
+	}
+	return r.addRouteWithMethod(method, pattern, handler)
 }
 
 // addRouteWithMethod adds a route with the specified HTTP method, path, and handler to the router.
@@ -150,7 +173,7 @@ func (r *serverMuxRouterImpl) addRouteWithMethod(method string, path string, han
 		return fmt.Errorf("handler cannot be nil")
 	}
 
-	routerPath := fmt.Sprintf("%s %s", method, path)
+	routerPath := fmt.Sprintf("%s %s", strings.ToUpper(method), path)
 	r.mux.HandleFunc(routerPath, handler)
 
 	log.Printf("Added route: %s\n", routerPath)
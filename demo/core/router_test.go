@@ -3,6 +3,7 @@ package core
 import (
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"testing"
 )
 
@@ -50,3 +51,103 @@ func TestServerMuxRouter_GroupAndAddGet(t *testing.T) {
 		t.Fatalf("expected status 200, got %d", rec.Code)
 	}
 }
+
+func TestServerMuxRouter_GroupAndAddPut(t *testing.T) {
+	router := NewServerMuxRouter()
+	todos := router.Group("api").Group("todos")
+
+	called := false
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		if id := r.PathValue("id"); id != "1" {
+			t.Errorf("expected id 1, got %q", id)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}
+
+	if err := todos.AddPut("/{id}", handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodPut, "/api/todos/1", nil)
+	rec := httptest.NewRecorder()
+	router.Handler().ServeHTTP(rec, req)
+
+	if !called {
+		t.Fatal("expected handler to be called")
+	}
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status 204, got %d", rec.Code)
+	}
+}
+
+func TestServerMuxRouter_AddRouteRejectsUnsupportedMethod(t *testing.T) {
+	router := NewServerMuxRouter()
+	api := router.Group("api")
+	handler := func(w http.ResponseWriter, _ *http.Request) {}
+
+	if err := api.AddRoute("TRACE", "/todos", handler); err == nil {
+		t.Fatal("expected an error for an unsupported method")
+	}
+	if err := api.AddRoute("options", "/todos", handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/todos", nil)
+	rec := httptest.NewRecorder()
+	router.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+}
+
+func TestServerMuxRouter_GroupMiddleware(t *testing.T) {
+	router := NewServerMuxRouter()
+
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next http.Handler) http.HandlerFunc {
+			return func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, name)
+				next.ServeHTTP(w, r)
+			}
+		}
+	}
+	handler := func(w http.ResponseWriter, _ *http.Request) {
+		calls = append(calls, "handler")
+	}
+
+	api := router.Group("api", record("api"))
+	if err := api.AddGet("/todos", handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := api.AddPost("/todos", handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	admin := api.Group("admin", record("admin"), record("auth"))
+	if err := admin.AddGet("/users", handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := router.Group("public").AddGet("/health", handler); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		method string
+		path   string
+		calls  []string
+	}{
+		{http.MethodGet, "/api/todos", []string{"api", "handler"}},
+		{http.MethodPost, "/api/todos", []string{"api", "handler"}},
+		{http.MethodGet, "/api/admin/users", []string{"api", "admin", "auth", "handler"}},
+		{http.MethodGet, "/public/health", []string{"handler"}},
+	}
+	for _, tt := range tests {
+		calls = nil
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		router.Handler().ServeHTTP(httptest.NewRecorder(), req)
+		if !slices.Equal(calls, tt.calls) {
+			t.Fatalf("%s %s: expected calls %v, got %v", tt.method, tt.path, tt.calls, calls)
+		}
+	}
+}
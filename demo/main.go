@@ -15,6 +15,7 @@ import (
 
 	"github.com/joho/godotenv"
 	"github.com/lcrux/go-di/di"
+	dihttp "github.com/lcrux/go-di/di/di-http"
 )
 
 func main() {
@@ -31,18 +32,17 @@ func main() {
 	if err := registerServices(container); err != nil {
 		log.Fatalf("Failed to register services: %v", err)
 	}
-
-	// Resolve the TodoController
-	todoController := di.MustResolve[controllers.TodoController](container, nil)
-	if todoController == nil {
-		panic("failed to resolve TodoController")
+	// Validate the registrations and freeze the container before serving
+	if err := container.Build(); err != nil {
+		log.Fatalf("Failed to build container: %v", err)
 	}
 
-	// Add the TodoController to the API router
+	// Resolve the TodoController and add it to the API router
 	apiRouter := r.Group("api")
-	if err := apiRouter.AddController(todoController, nil); err != nil {
+	if err := dihttp.MountController[controllers.TodoController](container, apiRouter, nil, nil); err != nil {
 		log.Fatalf("Failed to add controller to API router: %v", err)
 	}
+	todoController := di.MustResolve[controllers.TodoController](container, nil)
 
 	// Create a new lifecycle context and resolve the TodoController within that context
 	ctx := container.NewContext()
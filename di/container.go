@@ -5,8 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	dilogger "github.com/lcrux/go-di/di/di-logger"
 	diutils "github.com/lcrux/go-di/di/di-utils"
@@ -15,69 +18,430 @@ import (
 // backgroundContextKey is the key used to identify the background lifecycle context in the container.
 const backgroundContextKey = "__BACKGROUND_CONTEXT_KEY__"
 
+// defaultMaxResolutionDepth is the default maximum nesting of resolutions, see WithMaxResolutionDepth.
+const defaultMaxResolutionDepth = 100
+
 // containerReflectedKey is the reflected key for the Container type.
 var containerReflectedKey string = diutils.NameOfType(diutils.TypeOf[Container]())
 
 // lifecycleContextReflectedKey is the reflected key for the LifecycleContext type.
 var lifecycleContextReflectedKey = diutils.NameOfType(diutils.TypeOf[LifecycleContext]())
 
+// groupKeyPrefix is the prefix of the internal registry keys of group members.
+const groupKeyPrefix = "__GROUP__:"
+
+// loggerReflectedKey is the reflected key for the dilogger.Logger type.
+var loggerReflectedKey = diutils.NameOfType(diutils.TypeOf[dilogger.Logger]())
+
 // Container represents a dependency injection container that manages the lifecycle of services.
 type Container interface {
 	NewContext() LifecycleContext
+	NewNamedContext(name string) LifecycleContext
+	NewContextWithID(id string) (LifecycleContext, error)
 	RemoveContext(ctx LifecycleContext) error
+	RemoveContextErrors(ctx LifecycleContext) []error
 	BackgroundContext() LifecycleContext
-	Shutdown(...context.Context) []error
+	SetBackgroundContext(lc LifecycleContext, disposePrevious bool) error
+	ActiveContexts() []string
+	StartContextReaper(maxIdle, interval time.Duration) (stop func())
+	Count() int
+	ForEachRegistration(fn func(key string, serviceType reflect.Type, scope LifecycleScope) bool)
+	InstanceCounts() map[string]int
+	Shutdown(...context.Context) error
+	ShutdownFinal(...context.Context) error
+	OnShutdown(fn func(ctx context.Context) error)
+	Reset() error
+	Clone() Container
 	Resolve(key string, ctx LifecycleContext) (interface{}, error)
+	ResolveType(t reflect.Type, ctx LifecycleContext) (interface{}, error)
+	ResolveWithArgs(key string, ctx LifecycleContext, args ...interface{}) (interface{}, error)
+	ResolveMany(keys []string, ctx LifecycleContext) (map[string]interface{}, error)
+	ScopeOf(key string) (LifecycleScope, bool)
+	GetRegistration(key string) (RegistrationInfo, bool)
+	KeyFor(serviceType reflect.Type) string
+	InvalidateSingleton(key string) error
+	DisposeSingletons(ctx ...context.Context) []error
+	ResolutionPlan(key string, ctx LifecycleContext) ([]PlanStep, error)
 	Register(serviceType reflect.Type, key string, scope LifecycleScope, factoryFn interface{}) error
+	RegisterScopedWithTTL(serviceType reflect.Type, key string, ttl time.Duration, factoryFn interface{}) error
+	RegisterAlias(aliasKey, targetKey string) error
+	RegisterSpecial(t reflect.Type, provider func(c Container, ctx LifecycleContext) interface{}) error
+	Install(modules ...Module) error
+	ImportFrom(other Container, onConflict ConflictPolicy) error
+	RegisterToGroup(group string, order int, serviceType reflect.Type, factoryFn interface{}) error
+	ResolveGroup(group string, ctx LifecycleContext) ([]interface{}, error)
+	ResolveAll(serviceType reflect.Type, ctx LifecycleContext) ([]interface{}, error)
 	Validate() error
+	ValidateStrict() error
+	ValidateAll(ctx ...context.Context) []error
+	Freeze()
+	Build() error
+	PrecomputeDependencyTrees() error
+	WaitForReady(ctx context.Context) error
+	DependencyGraph() map[string][]string
+	DependencyGraphDOT() (string, error)
+	Describe() string
 	SetLogger(logger dilogger.Logger) error
+	AddObserver(observer Observer)
+	Metrics() ContainerMetrics
 }
 
 // containerEntry represents a registered service in the container.
 type containerEntry struct {
-	serviceType         reflect.Type      // The type of the service
-	key                 string            // The key associated with the service type
-	factoryFn           reflect.Value     // The factory function to create instances of the service
-	factoryFnParams     []reflect.Type    // The parameter types of the factory function
-	scope               LifecycleScope    // The scope of the service (Transient, Singleton, Scoped)
-	mutex               sync.Mutex        // Mutex to protect access to the container entry
-	dependencyTreeCache []*containerEntry // Cache for the dependency tree of this service
+	serviceType         reflect.Type                      // The type of the service
+	key                 string                            // The key associated with the service type
+	factoryFn           reflect.Value                     // The factory function to create instances of the service
+	factoryFnParams     []reflect.Type                    // The parameter types of the factory function
+	factoryFnParamKeys  []string                          // The registry keys of the factory function parameters, precomputed at registration
+	scope               LifecycleScope                    // The scope of the service (Transient, Singleton, Scoped)
+	order               int                               // The order of the service within its group, for group members
+	ttl                 time.Duration                     // The time to live of Scoped instances, 0 if they do not expire
+	lazy                bool                              // Whether the entry stands for a Lazy dependency in a dependency tree
+	provider            bool                              // Whether the entry stands for a provider dependency in a dependency tree
+	named               bool                              // Whether the entry stands for a Named dependency in a dependency tree
+	in                  bool                              // Whether the entry stands for an In parameter struct in a dependency tree
+	slice               bool                              // Whether the entry stands for a slice of the services assignable to its element type in a dependency tree
+	mutex               sync.Mutex                        // Mutex to protect access to the scoped initializers of the container entry
+	dependencyTreeCache atomic.Pointer[[]*containerEntry] // Cache for the dependency tree of this service, published atomically for concurrent resolutions
+
+	singleton   atomic.Pointer[instanceInit] // Once-backed initializer for the Singleton instance of this service
+	scopedInits map[string]*instanceInit     // Once-backed initializers for in-flight Scoped constructions, keyed by lifecycle context ID
+}
+
+// instanceInit guarantees that a factory runs exactly once per lifecycle context.
+//
+// The value and error are written inside once.Do, so they are safely published to every caller returning from Do.
+type instanceInit struct {
+	contextID string        // The ID of the lifecycle context the instance belongs to
+	once      sync.Once     // Ensures the factory function is invoked only once
+	value     reflect.Value // The constructed instance
+	err       error         // The error returned while constructing the instance, if any
+	ready     atomic.Bool   // Set once the instance is available, so cache hits can read it without locking
+}
+
+// singletonInitializer returns the singleton initializer bound to the given background context ID.
+// A new initializer is atomically installed when none exists yet or when the background context has been replaced.
+func (e *containerEntry) singletonInitializer(contextID string) *instanceInit {
+	for {
+		current := e.singleton.Load()
+		if current != nil && current.contextID == contextID {
+			return current
+		}
+		next := &instanceInit{contextID: contextID}
+		if e.singleton.CompareAndSwap(current, next) {
+			return next
+		}
+	}
+}
+
+// scopedInitializer returns the initializer for an in-flight Scoped construction within the given context ID.
+// Concurrent resolutions within the same context share the initializer, so the factory function runs only once.
+func (e *containerEntry) scopedInitializer(contextID string) *instanceInit {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.scopedInits == nil {
+		e.scopedInits = make(map[string]*instanceInit)
+	}
+	init, exists := e.scopedInits[contextID]
+	if !exists {
+		init = &instanceInit{contextID: contextID}
+		e.scopedInits[contextID] = init
+	}
+	return init
+}
+
+// releaseScopedInitializer removes the given Scoped initializer once its construction has completed.
+// Later resolutions are served from the lifecycle context cache, or retry the construction if it failed.
+func (e *containerEntry) releaseScopedInitializer(init *instanceInit) {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if e.scopedInits[init.contextID] == init {
+		delete(e.scopedInits, init.contextID)
+	}
+}
+
+// clone returns a copy of the container entry sharing its service type and factory function,
+// without its cached dependency tree and instance initializers.
+func (e *containerEntry) clone() *containerEntry {
+	return &containerEntry{
+		serviceType:        e.serviceType,
+		key:                e.key,
+		factoryFn:          e.factoryFn,
+		factoryFnParams:    e.factoryFnParams,
+		factoryFnParamKeys: e.factoryFnParamKeys,
+		scope:              e.scope,
+		order:              e.order,
+		ttl:                e.ttl,
+		lazy:               e.lazy,
+		provider:           e.provider,
+		named:              e.named,
+		in:                 e.in,
+	}
 }
 
 // NewContainer creates a new dependency injection container.
 // It initializes the container's registry and lifecycle contexts, including the background context.
-func NewContainer() Container {
+//
+// The container can be customized with options, such as WithLogger and WithMaxConcurrency.
+// Without options, the container uses a default logger and the default semaphore capacity.
+//
+// The container's logger is registered as a Singleton under the dilogger.Logger type,
+// so factories can declare a dilogger.Logger parameter to have it injected.
+func NewContainer(opts ...ContainerOption) Container {
 	container := &containerImpl{
 		registry:          diutils.NewAsyncMap[string, *containerEntry](),
 		lifecycleContexts: diutils.NewAsyncMap[string, LifecycleContext](),
-		logger:            dilogger.NewLogger(nil), // Initialize with a default logger, can be overridden by SetLogger
+		specials:          diutils.NewAsyncMap[string, specialType](),
+		groups:            make(map[string][]*containerEntry),
+		logger:            dilogger.NewLogger(nil), // Initialize with a default logger, can be overridden by WithLogger or SetLogger
+		maxDepth:          defaultMaxResolutionDepth,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(container)
+		}
 	}
 	// Create the background lifecycle context
-	container.lifecycleContexts.Set(backgroundContextKey, NewLifecycleContext())
+	container.lifecycleContexts.Set(backgroundContextKey, container.newLifecycleContext())
+
+	container.registerLogger()
 	return container
 }
 
+// registerLogger registers the container's logger so it can be injected into services.
+func (c *containerImpl) registerLogger() {
+	_ = c.Register(diutils.TypeOf[dilogger.Logger](), loggerReflectedKey, Singleton, func() dilogger.Logger {
+		return c.logger
+	})
+}
+
 // containerImpl is the concrete implementation of the Container interface.
 type containerImpl struct {
-	registry          diutils.AsyncMap[string, *containerEntry]  // Map to store registered services, keyed by their unique string keys
-	lifecycleContexts diutils.AsyncMap[string, LifecycleContext] // Map to store lifecycle contexts, keyed by their unique string keys (including the background context)
-	mutex             sync.RWMutex                               // Mutex to protect access when registering and validating services
-	logger            dilogger.Logger                            // Logger for logging container operations
+	registry             diutils.AsyncMap[string, *containerEntry]  // Map to store registered services, keyed by their unique string keys
+	lifecycleContexts    diutils.AsyncMap[string, LifecycleContext] // Map to store lifecycle contexts, keyed by their unique string keys (including the background context)
+	mutex                sync.RWMutex                               // Mutex to protect access when registering and validating services
+	groups               map[string][]*containerEntry               // Members of each group, sorted by their order
+	frozen               bool                                       // Whether the container is read-only, registrations are rejected once frozen
+	logger               dilogger.Logger                            // Logger for logging container operations
+	maxConcurrency       int                                        // Maximum number of concurrent operations, 0 uses the default semaphore capacity
+	strictScopes         bool                                       // Whether resolving a Scoped service within the background context is an error
+	leakThreshold        int                                        // Number of active contexts above which NewContext logs a warning, 0 disables the warning
+	maxDepth             int                                        // Maximum nesting of resolutions triggered by Lazy dependencies and providers
+	conventionalDisposal bool                                       // Whether instances exposing Stop or Shutdown methods are disposed like LifecycleListener instances
+	removeOnDisposeError bool                                       // Whether instances failing to be disposed are removed from the lifecycle contexts anyway
+	constructionTimeout  time.Duration                              // Maximum time a factory function may take, 0 disables the timeout
+	negativeCache        *negativeCache                             // Keys known not to be registered, nil unless enabled with WithNegativeCache
+	tracer               Tracer                                     // Tracer called around factory function invocations, nil disables tracing
+	metrics              *metricsCollector                          // Collector of resolution metrics, nil unless enabled by WithMetrics
+	shutdownMutex        sync.Mutex                                 // Mutex to serialize the start of shutdowns
+	lastShutdown         *shutdownCall                              // Last shutdown started, shared by concurrent and repeated calls
+	drainMutex           sync.RWMutex                               // Mutex to order the start of resolutions with the start of draining
+	draining             bool                                       // Whether new resolutions are rejected while a shutdown waits for the in-flight ones, guarded by drainMutex
+	closed               atomic.Bool                                // Whether the container is closed by ShutdownFinal, rejecting resolutions and registrations for good
+	inflight             sync.WaitGroup                             // In-flight resolutions, waited for by Shutdown before disposing the instances
+	hooksMutex           sync.Mutex                                 // Mutex to protect access to the shutdown hooks
+	shutdownHooks        []func(ctx context.Context) error          // Callbacks run by the next shutdown, in reverse order of registration
+	observers            atomic.Pointer[[]Observer]                 // Observers notified of the container events, replaced as a whole when an observer is added
+	specials             diutils.AsyncMap[string, specialType]      // Custom special types injected without a factory, keyed by the key of their type
+	reaping              sync.Map                                   // Lifecycle contexts being disposed by the context reaper, keyed by ID
+}
+
+// shutdownCall tracks a container shutdown, so concurrent and repeated calls can share its outcome.
+type shutdownCall struct {
+	done         chan struct{} // Closed when the shutdown is complete
+	err          error         // Error returned by the shutdown, nil if it succeeded
+	backgroundID string        // ID of the background context created by the shutdown, empty if it was canceled
+	final        bool          // Whether the shutdown completed with the container closed, so it is never repeated
 }
 
 // NewContext creates a new lifecycle context and adds it to the container.
 // It returns the newly created lifecycle context.
 func (c *containerImpl) NewContext() LifecycleContext {
-	ctx := NewLifecycleContext()
+	return c.NewNamedContext("")
+}
+
+// NewNamedContext creates a new lifecycle context labeled with the given name, such as "request" or "job:42",
+// and adds it to the container. The name is included in the log lines of the context and in ActiveContexts,
+// so request, session or job scopes can be told apart. It does not need to be unique.
+// It returns the newly created lifecycle context.
+func (c *containerImpl) NewNamedContext(name string) LifecycleContext {
+	ctx := c.newLifecycleContext()
+	if lctx, ok := ctx.(*lifecycleContextImpl); ok {
+		lctx.name = name
+	}
 	c.lifecycleContexts.Set(ctx.ID(), ctx)
+	c.warnContextLeak(ctx)
+	return ctx
+}
+
+// NewContextWithID creates a new lifecycle context identified by the given ID, instead of a random one,
+// and adds it to the container. This lets tests assert on stable IDs, and callers correlate scopes with
+// external request IDs.
+//
+// It returns an error if the ID is empty, reserved for the background context, or already used by a context
+// of the container.
+func (c *containerImpl) NewContextWithID(id string) (LifecycleContext, error) {
+	if strings.TrimSpace(id) == "" {
+		return nil, fmt.Errorf("context ID cannot be empty")
+	}
+	if id == backgroundContextKey || id == c.BackgroundContext().ID() {
+		return nil, fmt.Errorf("context ID %s is reserved for the background context", id)
+	}
+
+	ctx := NewLifecycleContextWithID(id)
+	c.adoptContext(ctx)
+	if _, loaded := c.lifecycleContexts.LoadOrStore(id, ctx); loaded {
+		return nil, fmt.Errorf("lifecycle context with ID %s already exists", id)
+	}
+	c.warnContextLeak(ctx)
+	return ctx, nil
+}
+
+// warnContextLeak logs a warning if the number of active contexts exceeds the threshold set with
+// WithContextLeakWarning, once the given context is added.
+func (c *containerImpl) warnContextLeak(ctx LifecycleContext) {
+	if c.leakThreshold > 0 {
+		// The background context is not counted as an active context
+		if active := c.lifecycleContexts.Len() - 1; active > c.leakThreshold {
+			c.logger.Warnf("%d lifecycle contexts are active, exceeding the threshold of %d, contexts may not be removed with RemoveContext, latest context: %s",
+				active, c.leakThreshold, contextLabel(ctx))
+		}
+	}
+}
+
+// contextLabel returns the ID of the given lifecycle context, preceded by its name if it has one.
+func contextLabel(ctx LifecycleContext) string {
+	if ctx.Name() == "" {
+		return ctx.ID()
+	}
+	return fmt.Sprintf("%s (%s)", ctx.Name(), ctx.ID())
+}
+
+// instanceTTL returns the time to live of the instances of the service registered with the given key, 0 if they do not expire.
+func (c *containerImpl) instanceTTL(key string) time.Duration {
+	if entry, exists := c.registry.Get(key); exists {
+		return entry.ttl
+	}
+	return 0
+}
+
+// ActiveContexts returns the sorted IDs of the lifecycle contexts created with NewContext and not removed yet.
+// The IDs of the contexts created with NewNamedContext are preceded by their name, as in "request (ID)".
+// The background context is not included.
+func (c *containerImpl) ActiveContexts() []string {
+	ids := make([]string, 0, c.lifecycleContexts.Len())
+	c.lifecycleContexts.Range(func(key string, ctx LifecycleContext) bool {
+		if key != backgroundContextKey {
+			ids = append(ids, contextLabel(ctx))
+		}
+		return true
+	})
+	sort.Strings(ids)
+	return ids
+}
+
+// Count returns the number of services registered with the container. Aliases and the built-in logger are not counted.
+func (c *containerImpl) Count() int {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	entries := make(map[*containerEntry]struct{})
+	c.registry.Range(func(key string, entry *containerEntry) bool {
+		if key != loggerReflectedKey {
+			entries[entry] = struct{}{}
+		}
+		return true
+	})
+	return len(entries)
+}
+
+// ForEachRegistration calls fn with the key, service type and lifecycle scope of each registered service, sorted by key,
+// stopping when fn returns false. Aliases are visited under their own key, and the built-in logger is not visited.
+//
+// The registrations are snapshotted under the read lock, which is released before fn is called,
+// so fn may call back into the container, but it does not see the services registered during the iteration.
+func (c *containerImpl) ForEachRegistration(fn func(key string, serviceType reflect.Type, scope LifecycleScope) bool) {
+	type registration struct {
+		key         string
+		serviceType reflect.Type
+		scope       LifecycleScope
+	}
+
+	c.mutex.RLock()
+	registrations := make([]registration, 0, c.registry.Len())
+	c.registry.Range(func(key string, entry *containerEntry) bool {
+		if key != loggerReflectedKey {
+			registrations = append(registrations, registration{key: key, serviceType: entry.serviceType, scope: entry.scope})
+		}
+		return true
+	})
+	c.mutex.RUnlock()
+
+	sort.Slice(registrations, func(i, j int) bool {
+		return registrations[i].key < registrations[j].key
+	})
+	for _, r := range registrations {
+		if !fn(r.key, r.serviceType, r.scope) {
+			return
+		}
+	}
+}
+
+// InstanceCounts returns a snapshot of the number of instances cached by each lifecycle context, keyed by context ID.
+// The background context, holding the Singleton instances, is included.
+func (c *containerImpl) InstanceCounts() map[string]int {
+	counts := make(map[string]int, c.lifecycleContexts.Len())
+	c.lifecycleContexts.Range(func(_ string, ctx LifecycleContext) bool {
+		counts[ctx.ID()] = ctx.Count()
+		return true
+	})
+	return counts
+}
+
+// newLifecycleContext creates a new lifecycle context that logs through the container's logger.
+func (c *containerImpl) newLifecycleContext() LifecycleContext {
+	ctx := NewLifecycleContext()
+	c.adoptContext(ctx)
 	return ctx
 }
 
+// adoptContext configures the given lifecycle context to log with the logger of the container,
+// notify its observers, and dispose its instances like the contexts created by the container.
+func (c *containerImpl) adoptContext(ctx LifecycleContext) {
+	_ = ctx.SetLogger(c.logger)
+	if lctx, ok := ctx.(*lifecycleContextImpl); ok {
+		lctx.onDispose = c.notifyDispose
+		lctx.ttlOf = c.instanceTTL
+		lctx.disposer = c.disposer()
+	}
+}
+
+// disposer returns the disposer of the instances of the container, passing the container to ContainerAwareListener instances.
+// The lifecycle contexts keep the container only to pass it to their instances, they never shut it down.
+func (c *containerImpl) disposer() disposer {
+	return disposer{container: c, conventional: c.conventionalDisposal, removeOnError: c.removeOnDisposeError}
+}
+
+// SetLogger sets the logger for the container and all its lifecycle contexts.
+// It also replaces the injectable dilogger.Logger singleton if it has already been resolved.
 func (c *containerImpl) SetLogger(logger dilogger.Logger) error {
 	if logger == nil {
 		return fmt.Errorf("logger cannot be nil")
 	}
 	c.logger = logger
+	// Replace the injectable logger singleton if it has already been resolved
+	bgCtx := c.BackgroundContext()
+	if _, exists := bgCtx.GetInstance(loggerReflectedKey); exists {
+		if err := bgCtx.SetInstance(loggerReflectedKey, reflect.ValueOf(&logger).Elem()); err != nil {
+			return fmt.Errorf("failed to replace the injectable logger: %w", err)
+		}
+		// Discard the initializer holding the previous logger, so resolutions read the replaced instance
+		if entry, exists := c.registry.Get(loggerReflectedKey); exists {
+			entry.singleton.Store(nil)
+		}
+	}
 	for _, ctx := range c.lifecycleContexts.Values() {
 		if err := ctx.SetLogger(logger); err != nil {
 			return fmt.Errorf("failed to set logger for context %s: %w", ctx.ID(), err)
@@ -94,65 +458,384 @@ func (c *containerImpl) BackgroundContext() LifecycleContext {
 	return nil
 }
 
+// SetBackgroundContext replaces the background context, which holds the Singleton instances, with the given lifecycle context,
+// for example a context created with NewLifecycleContext and pre-seeded with fakes, so tests control the singletons resolved.
+// The context is configured like the contexts created by the container, and the next resolutions read their singletons from it.
+//
+// If disposePrevious is true, the previous background context is shut down once replaced, and its disposal errors are returned.
+// Otherwise, it returns an error, without replacing it, if the previous background context holds instances.
+// It returns an error if the context is nil, closed, or already tracked by the container.
+func (c *containerImpl) SetBackgroundContext(lc LifecycleContext, disposePrevious bool) error {
+	if lc == nil {
+		return fmt.Errorf("background context cannot be nil")
+	}
+	if lc.IsClosed() {
+		return fmt.Errorf("cannot use closed lifecycle context %s as background context", lc.ID())
+	}
+	if _, tracked := c.lifecycleContexts.Get(lc.ID()); tracked {
+		return fmt.Errorf("lifecycle context %s is already tracked by the container", lc.ID())
+	}
+	c.adoptContext(lc)
+
+	// Swap the background context atomically, so resolutions never observe a missing background context
+	var previous LifecycleContext
+	var err error
+	c.lifecycleContexts.Update(backgroundContextKey, func(current LifecycleContext, ok bool) (LifecycleContext, bool) {
+		previous = current
+		if ok && !disposePrevious && current.Count() > 0 {
+			err = fmt.Errorf("background context %s holds %d instances", current.ID(), current.Count())
+			return current, true
+		}
+		return lc, true
+	})
+	if err != nil || previous == nil || previous == lc {
+		return err
+	}
+
+	c.logger.Debugf("Replaced background context %s with %s", previous.ID(), lc.ID())
+	if disposePrevious {
+		return previous.Shutdown()
+	}
+	return nil
+}
+
 // RemoveContext removes the given lifecycle context from the container and shuts it down.
 func (c *containerImpl) RemoveContext(lctx LifecycleContext) error {
 	if lctx == nil || lctx.IsClosed() {
 		return nil
 	}
+	if _, claimed := c.claimContext(lctx); !claimed {
+		return nil
+	}
 
-	c.lifecycleContexts.Delete(lctx.ID())
-
-	if errs := lctx.Shutdown(); len(errs) > 0 {
-		return fmt.Errorf(
-			"failed to shutdown lifecycle context %s: %v", lctx.ID(),
-			errors.Join(errs...),
-		)
+	if err := lctx.Shutdown(); err != nil {
+		return fmt.Errorf("failed to shutdown lifecycle context %s: %w", lctx.ID(), err)
 	}
 	return nil
 }
 
+// claimContext removes the given lifecycle context from the container, atomically, so it is shut down by a single caller.
+// It reports whether the context was tracked by the container, and whether the caller must shut it down,
+// which is the case for the contexts not created by the container, unless the context reaper is disposing them.
+func (c *containerImpl) claimContext(lctx LifecycleContext) (tracked, claimed bool) {
+	c.lifecycleContexts.Update(lctx.ID(), func(current LifecycleContext, ok bool) (LifecycleContext, bool) {
+		_, reaping := c.reaping.Load(lctx.ID())
+		tracked, claimed = ok, ok || !reaping
+		return current, false
+	})
+	return tracked, claimed
+}
+
+// StartContextReaper starts a goroutine disposing, every interval, the lifecycle contexts idle for longer than maxIdle,
+// for services creating scopes they forget to remove. A context is idle since its last access, see LifecycleContext.LastAccess,
+// so long-lived contexts still in use are kept. The background context is never disposed.
+//
+// Reaped contexts are removed and shut down like with RemoveContext, and the disposal errors are logged.
+// A context is only shut down once, even if RemoveContext is called concurrently.
+// It returns a function stopping the reaper, which is safe to call multiple times.
+func (c *containerImpl) StartContextReaper(maxIdle, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				c.reapContexts(maxIdle)
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// reapContexts removes and shuts down the lifecycle contexts older than maxIdle, except the background context.
+func (c *containerImpl) reapContexts(maxIdle time.Duration) {
+	expired := make([]LifecycleContext, 0)
+	c.lifecycleContexts.Range(func(key string, ctx LifecycleContext) bool {
+		if key != backgroundContextKey && time.Since(ctx.LastAccess()) > maxIdle {
+			expired = append(expired, ctx)
+		}
+		return true
+	})
+
+	for _, ctx := range expired {
+		// Claim the context, unless it was removed concurrently, and mark it as being reaped within the same update
+		claimed := false
+		c.lifecycleContexts.Update(ctx.ID(), func(current LifecycleContext, ok bool) (LifecycleContext, bool) {
+			if claimed = ok && current == ctx; claimed {
+				c.reaping.Store(ctx.ID(), ctx)
+			}
+			return current, ok && !claimed
+		})
+		if !claimed {
+			continue
+		}
+
+		c.logger.Warnf("Reaping lifecycle context %s, created %v ago and idle for %v", contextLabel(ctx),
+			ctx.Age().Round(time.Millisecond), time.Since(ctx.LastAccess()).Round(time.Millisecond))
+		if err := ctx.Shutdown(); err != nil {
+			c.logger.Errorf("Failed to shutdown reaped lifecycle context %s: %v", contextLabel(ctx), err)
+		}
+		c.reaping.Delete(ctx.ID())
+	}
+}
+
+// RemoveContextErrors removes the given lifecycle context from the container and shuts it down, like RemoveContext,
+// but returns the errors of the instances that failed to be disposed one by one instead of joining them.
+//
+// It is a no-op returning an empty slice if the context is nil, already closed, or not created by the container,
+// and it returns an empty slice if every instance was disposed.
+func (c *containerImpl) RemoveContextErrors(lctx LifecycleContext) []error {
+	if lctx == nil || lctx.IsClosed() {
+		return []error{}
+	}
+	if tracked, _ := c.claimContext(lctx); !tracked {
+		return []error{}
+	}
+
+	errs := ShutdownErrors(lctx.Shutdown())
+	if errs == nil {
+		return []error{}
+	}
+	return errs
+}
+
 // Shutdown gracefully shuts down the container and all its lifecycle contexts.
 //
-// It returns a slice of errors encountered during the shutdown process, if any.
+// It returns a *ShutdownError wrapping the errors encountered during the shutdown process, along with the IDs
+// of the lifecycle contexts they come from, or nil if there are none.
 // If the provided context is nil, a background context will be used.
-func (c *containerImpl) Shutdown(ctxs ...context.Context) []error {
+//
+// Shutdown is safe to call multiple times and concurrently: a call made while another shutdown is in progress
+// waits for it to finish and returns the same error, and a repeated call is a no-op returning the error
+// of the previous shutdown, unless the container has been used in the meantime.
+//
+// The container drains before disposing the instances: the resolutions started once Shutdown begins,
+// including those made by factory functions through the injected Container, fail with an error wrapping ErrShuttingDown,
+// while the in-flight resolutions are waited for, so no instance is stored in a lifecycle context being shut down.
+// The container resolves services again once the shutdown completes.
+func (c *containerImpl) Shutdown(ctxs ...context.Context) error {
 	// If no context is provided, use a background context
 	ctx := context.Background()
 	if len(ctxs) > 0 {
 		ctx = ctxs[0]
 	}
 
-	// errors stores the errors encountered during the shutdown process
-	var errors []error
+	c.shutdownMutex.Lock()
+	for call := c.lastShutdown; call != nil; call = c.lastShutdown {
+		select {
+		case <-call.done:
+			if call.final || (!c.closed.Load() && c.unusedSince(call)) {
+				c.shutdownMutex.Unlock()
+				c.logger.Debugf("Container already shut down, skipping")
+				return call.err
+			}
+		default:
+			c.shutdownMutex.Unlock()
+			c.logger.Debugf("Container shutdown already in progress, waiting for it to finish...")
+			select {
+			case <-call.done:
+			case <-ctx.Done():
+				return newShutdownError("", []error{fmt.Errorf("shutdown canceled while waiting for the ongoing shutdown: %w", ctx.Err())})
+			}
+			// A shutdown reopening the container was in progress when the container got closed, so shut it down again
+			if call.final || !c.closed.Load() {
+				return call.err
+			}
+			c.shutdownMutex.Lock()
+			continue
+		}
+		break
+	}
+	call := &shutdownCall{done: make(chan struct{})}
+	c.lastShutdown = call
+	c.shutdownMutex.Unlock()
+
+	defer close(call.done)
+	if err := c.drain(ctx); err != nil {
+		call.err = newShutdownError("", []error{fmt.Errorf("shutdown canceled while waiting for in-flight resolutions: %w", err)})
+		return call.err
+	}
+	final := c.closed.Load()
+	call.backgroundID, call.err = c.shutdown(ctx, final)
+	call.final = final && call.backgroundID != ""
+	c.endDraining()
+	return call.err
+}
+
+// ShutdownFinal shuts down the container like Shutdown, but leaves it closed instead of ready to resolve services again.
+//
+// Once ShutdownFinal is called, resolutions and registrations fail with an error wrapping ErrContainerClosed,
+// including the resolutions started by factory functions while the in-flight ones are drained, and the container
+// cannot be reopened. The background context is shut down and kept, so BackgroundContext never returns nil.
+// Repeated calls return the error of the shutdown that closed the container, unless it was canceled, in which case
+// the shutdown is attempted again.
+func (c *containerImpl) ShutdownFinal(ctxs ...context.Context) error {
+	c.closed.Store(true)
+	return c.Shutdown(ctxs...)
+}
+
+// OnShutdown registers a callback run by the next Shutdown once all the lifecycle contexts are disposed,
+// for teardown logic outside the services of the container, such as flushing a buffer or closing a file.
+//
+// The callbacks run in reverse order of registration with the shutdown context, and each callback runs once.
+// Their errors, and the panics they raise, are added to the error returned by Shutdown.
+func (c *containerImpl) OnShutdown(fn func(ctx context.Context) error) {
+	if fn == nil {
+		return
+	}
+	c.hooksMutex.Lock()
+	defer c.hooksMutex.Unlock()
+	c.shutdownHooks = append(c.shutdownHooks, fn)
+}
+
+// runShutdownHooks runs and removes the registered shutdown hooks, in reverse order of registration.
+// It returns the errors returned by the hooks, and the panics they raised.
+func (c *containerImpl) runShutdownHooks(ctx context.Context) []error {
+	c.hooksMutex.Lock()
+	hooks := c.shutdownHooks
+	c.shutdownHooks = nil
+	c.hooksMutex.Unlock()
+
+	var errs []error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		if err := runShutdownHook(ctx, hooks[i]); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// runShutdownHook runs the given shutdown hook, recovering from its panic.
+func runShutdownHook(ctx context.Context, hook func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("shutdown hook panicked: %v", r)
+		}
+	}()
+	if err := hook(ctx); err != nil {
+		return fmt.Errorf("shutdown hook failed: %w", err)
+	}
+	return nil
+}
+
+// beginResolution registers an in-flight resolution, which must be ended by calling c.inflight.Done,
+// so Shutdown waits for it to complete. It returns an error wrapping ErrShuttingDown while the container drains.
+func (c *containerImpl) beginResolution() error {
+	c.drainMutex.RLock()
+	defer c.drainMutex.RUnlock()
+
+	if c.closed.Load() {
+		return fmt.Errorf("cannot start resolution: %w", ErrContainerClosed)
+	}
+	if c.draining {
+		return fmt.Errorf("cannot start resolution: %w", ErrShuttingDown)
+	}
+	c.inflight.Add(1)
+	return nil
+}
+
+// drain rejects the new resolutions, and waits for the in-flight ones to complete or for the context to be canceled.
+// The new resolutions are rejected until endDraining is called, or until the in-flight resolutions complete if it is canceled.
+func (c *containerImpl) drain(ctx context.Context) error {
+	c.drainMutex.Lock()
+	c.draining = true
+	c.drainMutex.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		c.inflight.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		// The wait group cannot be reused before Wait returns, so new resolutions are rejected until then
+		go func() {
+			<-drained
+			c.endDraining()
+		}()
+		return ctx.Err()
+	}
+}
+
+// endDraining accepts the new resolutions again.
+func (c *containerImpl) endDraining() {
+	c.drainMutex.Lock()
+	c.draining = false
+	c.drainMutex.Unlock()
+}
+
+// unusedSince indicates whether the container has not been used since the given shutdown completed,
+// meaning that no lifecycle context has been created, the background context is still empty, and no shutdown hook is registered.
+func (c *containerImpl) unusedSince(call *shutdownCall) bool {
+	if call.backgroundID == "" || c.lifecycleContexts.Len() != 1 {
+		return false
+	}
+	c.hooksMutex.Lock()
+	hooks := len(c.shutdownHooks)
+	c.hooksMutex.Unlock()
+	if hooks > 0 {
+		return false
+	}
+	bgCtx := c.BackgroundContext()
+	return bgCtx != nil && bgCtx.ID() == call.backgroundID && bgCtx.Count() == 0
+}
+
+// shutdown shuts down all the lifecycle contexts of the container.
+// Unless final is set, the background context is replaced by a new one, so the container can be used again.
+//
+// It returns, unless the shutdown was canceled, the ID of the background context of the container and the error encountered.
+func (c *containerImpl) shutdown(ctx context.Context, final bool) (string, error) {
+	// shutdownErr stores the errors encountered during the shutdown process
+	shutdownErr := &ShutdownError{}
 	var errorsMutex sync.Mutex
-	setErrors := func(errs ...error) {
+	setError := func(contextID string, err error) {
 		errorsMutex.Lock()
 		defer errorsMutex.Unlock()
-		errors = append(errors, errs...)
+		shutdownErr.add(contextID, err)
+	}
+	result := func() error {
+		if len(shutdownErr.Causes) == 0 {
+			return nil
+		}
+		return shutdownErr
 	}
 
 	if checkIfCanceled(ctx) {
-		setErrors(fmt.Errorf("shutdown canceled before starting"))
-		return errors
+		setError("", fmt.Errorf("shutdown canceled before starting"))
+		return "", result()
 	}
 
 	c.logger.Debugf("Shutting down container and all lifecycle contexts...")
 
-	semaphore := diutils.NewSemaphore()
+	semaphore := diutils.NewSemaphore(c.maxConcurrency)
 	defer semaphore.Done()
 
 	lcKeys := c.lifecycleContexts.Keys()
 
 	wg := sync.WaitGroup{}
 	for _, lck := range lcKeys {
-		if checkIfCanceled(ctx) {
-			setErrors(fmt.Errorf("shutdown canceled before starting"))
-			return errors
+		// Stop acquiring new slots once the shutdown context is canceled
+		if err := semaphore.AcquireCtx(ctx); err != nil {
+			setError("", fmt.Errorf("shutdown canceled before lifecycle context %s: %w", lck, err))
+			break
 		}
 
-		semaphore.Acquire()
-
-		lcc, _ := c.lifecycleContexts.Get(lck)
+		// The context may have been removed since the keys were read, such as by RemoveContext or the context reaper
+		lcc, exists := c.lifecycleContexts.Get(lck)
+		if !exists {
+			semaphore.Release()
+			continue
+		}
 
 		wg.Add(1)
 		go func(lc LifecycleContext) {
@@ -160,28 +843,166 @@ func (c *containerImpl) Shutdown(ctxs ...context.Context) []error {
 			defer semaphore.Release()
 
 			if checkIfCanceled(ctx) {
-				setErrors(fmt.Errorf("shutdown canceled for lifecycle context %s", lc.ID()))
+				setError(lc.ID(), fmt.Errorf("shutdown canceled for lifecycle context %s", lc.ID()))
 				return
 			}
 
-			errors := lc.Shutdown(ctx)
-			setErrors(errors...)
+			if err := lc.Shutdown(ctx); err != nil {
+				setError(lc.ID(), err)
+			}
 		}(lcc)
 	}
 	wg.Wait()
 
-	if !checkIfCanceled(ctx) {
-		// Reset the lifecycle contexts after shutdown, keeps a clean background context to avoid nil references
-		c.lifecycleContexts = diutils.NewAsyncMap[string, LifecycleContext]()
-		c.lifecycleContexts.Set(backgroundContextKey, NewLifecycleContext())
+	if checkIfCanceled(ctx) {
+		return "", result()
+	}
+
+	for _, err := range c.runShutdownHooks(ctx) {
+		setError("", err)
+	}
+
+	// Replace the background context before removing the shut down contexts, so it is never missing.
+	// A closed container keeps its shut down background context instead.
+	bgCtx, _ := c.lifecycleContexts.Get(backgroundContextKey)
+	if !final {
+		bgCtx = c.newLifecycleContext()
+		c.lifecycleContexts.Set(backgroundContextKey, bgCtx)
+	}
+	for _, lck := range lcKeys {
+		if lck != backgroundContextKey {
+			c.lifecycleContexts.Delete(lck)
+		}
+	}
+
+	return bgCtx.ID(), result()
+}
+
+// Reset shuts down all lifecycle contexts and removes all registered services from the container.
+//
+// Unlike Shutdown, which keeps the registrations, Reset leaves the container as if it had just been created,
+// with a fresh background context, while preserving the options it was created with, such as the logger
+// and the maximum concurrency. It returns the error encountered while shutting down the lifecycle contexts.
+func (c *containerImpl) Reset() error {
+	err := c.Shutdown()
+
+	c.mutex.Lock()
+	for _, key := range c.registry.Keys() {
+		c.registry.Delete(key)
+	}
+	for _, key := range c.specials.Keys() {
+		c.specials.Delete(key)
+	}
+	c.groups = make(map[string][]*containerEntry)
+	c.frozen = false
+	c.mutex.Unlock()
+
+	c.registerLogger()
+
+	c.logger.Debugf("Container reset, all registrations removed")
+	return err
+}
+
+// Clone creates a new container with the registrations, aliases, and groups of the container,
+// and the options it was created with, such as the logger, the maximum concurrency, and the observers.
+//
+// The clone has its own background context, so its Singleton instances are created independently of the container.
+// Registrations made afterwards on either container do not affect the other one, and the clone is never frozen.
+func (c *containerImpl) Clone() Container {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	clone := &containerImpl{
+		registry:             diutils.NewAsyncMap[string, *containerEntry](),
+		lifecycleContexts:    diutils.NewAsyncMap[string, LifecycleContext](),
+		specials:             diutils.NewAsyncMap[string, specialType](),
+		groups:               make(map[string][]*containerEntry, len(c.groups)),
+		logger:               c.logger,
+		maxConcurrency:       c.maxConcurrency,
+		tracer:               c.tracer,
+		strictScopes:         c.strictScopes,
+		leakThreshold:        c.leakThreshold,
+		maxDepth:             c.maxDepth,
+		conventionalDisposal: c.conventionalDisposal,
+		removeOnDisposeError: c.removeOnDisposeError,
+		constructionTimeout:  c.constructionTimeout,
+	}
+	if c.negativeCache != nil {
+		clone.negativeCache = newNegativeCache()
+	}
+
+	// Share the observers, except the metrics collector, as the clone collects its own metrics
+	observers := make([]Observer, 0)
+	if current := c.observers.Load(); current != nil {
+		for _, observer := range *current {
+			if c.metrics == nil || observer != Observer(c.metrics) {
+				observers = append(observers, observer)
+			}
+		}
+	}
+	if c.metrics != nil {
+		clone.metrics = newMetricsCollector()
+		observers = append(observers, clone.metrics)
+	}
+	clone.observers.Store(&observers)
+
+	clone.lifecycleContexts.Set(backgroundContextKey, clone.newLifecycleContext())
+
+	// Clone each entry once, so aliases keep pointing to the same entry as their target
+	entries := make(map[*containerEntry]*containerEntry)
+	cloneEntry := func(entry *containerEntry) *containerEntry {
+		cloned, exists := entries[entry]
+		if !exists {
+			cloned = entry.clone()
+			entries[entry] = cloned
+		}
+		return cloned
 	}
+	c.registry.Range(func(key string, entry *containerEntry) bool {
+		// The logger is registered again, so the clone injects its own logger
+		if key != loggerReflectedKey {
+			clone.registry.Set(key, cloneEntry(entry))
+		}
+		return true
+	})
+	for group, members := range c.groups {
+		clonedMembers := make([]*containerEntry, 0, len(members))
+		for _, member := range members {
+			clonedMembers = append(clonedMembers, cloneEntry(member))
+		}
+		clone.groups[group] = clonedMembers
+	}
+
+	c.specials.Range(func(key string, special specialType) bool {
+		clone.specials.Set(key, special)
+		return true
+	})
 
-	return errors
+	clone.registerLogger()
+	return clone
 }
 
 // Register registers a service with the given type, key, scope, and factory function in the container.
 // It returns an error if the service cannot be registered.
 func (c *containerImpl) Register(serviceType reflect.Type, key string, scope LifecycleScope, factoryFn interface{}) error {
+	return c.registerWithTTL(serviceType, key, scope, 0, factoryFn)
+}
+
+// RegisterScopedWithTTL registers a Scoped service whose instances expire after the given time to live.
+//
+// An expired instance is evicted from its lifecycle context when it is next looked up: it is disposed,
+// if it implements LifecycleListener or io.Closer, and a new instance is created.
+// It returns an error if the time to live is not positive.
+func (c *containerImpl) RegisterScopedWithTTL(serviceType reflect.Type, key string, ttl time.Duration, factoryFn interface{}) error {
+	if ttl <= 0 {
+		return fmt.Errorf("ttl must be positive, got %v", ttl)
+	}
+	return c.registerWithTTL(serviceType, key, Scoped, ttl, factoryFn)
+}
+
+// registerWithTTL validates and registers a service, then notifies the observers.
+// A time to live of 0 means the instances of the service do not expire.
+func (c *containerImpl) registerWithTTL(serviceType reflect.Type, key string, scope LifecycleScope, ttl time.Duration, factoryFn interface{}) error {
 	if serviceType == nil {
 		return fmt.Errorf("serviceType cannot be nil")
 	}
@@ -192,115 +1013,885 @@ func (c *containerImpl) Register(serviceType reflect.Type, key string, scope Lif
 		return fmt.Errorf("factoryFn cannot be nil")
 	}
 
+	if err := c.register(serviceType, key, scope, ttl, factoryFn); err != nil {
+		return err
+	}
+
+	// Notify the observers once the registration lock is released
+	c.notify(func(o Observer) {
+		o.OnRegister(key, scope)
+	})
+	return nil
+}
+
+// register adds the container entry of a service to the registry under the registration lock.
+func (c *containerImpl) register(serviceType reflect.Type, key string, scope LifecycleScope, ttl time.Duration, factoryFn interface{}) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	if _, exists := c.registry.Get(key); exists {
-		return fmt.Errorf("service already registered with key: %s", key)
+	if _, err := c.addEntry(serviceType, key, scope, ttl, factoryFn); err != nil {
+		return err
 	}
+	c.invalidateDependencyTrees()
+	return nil
+}
 
-	// Convert the factory function to a reflect.Value and get its type
-	factoryFnValue := reflect.ValueOf(factoryFn)
-	factoryFnType := factoryFnValue.Type()
+// registerDefault validates and registers a service along with the alias of the given default key to it,
+// then notifies the observers. The service and the alias are registered under a single registration lock,
+// so the service is not registered when the default key is already taken.
+func (c *containerImpl) registerDefault(serviceType reflect.Type, key, defaultKey string, scope LifecycleScope, factoryFn interface{}) error {
+	if strings.TrimSpace(key) == "" {
+		return fmt.Errorf("key cannot be empty")
+	}
+	if factoryFn == nil {
+		return fmt.Errorf("factoryFn cannot be nil")
+	}
 
-	// Ensure the factory function is a valid function and returns exactly one value
-	if factoryFnValue.Kind() != reflect.Func || factoryFnType.NumOut() != 1 {
-		return fmt.Errorf("factoryFn must be a function that returns exactly one value")
+	if err := func() error {
+		c.mutex.Lock()
+		defer c.mutex.Unlock()
+
+		if _, exists := c.registry.Get(defaultKey); exists || c.isSpecial(defaultKey) {
+			return fmt.Errorf("cannot register %s as the default implementation of %s: a default is already registered", key, serviceType.String())
+		}
+		entry, err := c.addEntry(serviceType, key, scope, 0, factoryFn)
+		if err != nil {
+			return err
+		}
+		c.registry.Set(defaultKey, entry)
+		c.invalidateDependencyTrees()
+
+		c.logger.Debugf("Registered alias: %s for key: %s", defaultKey, key)
+		return nil
+	}(); err != nil {
+		return err
+	}
+
+	// Notify the observers once the registration lock is released
+	c.notify(func(o Observer) {
+		o.OnRegister(key, scope)
+	})
+	return nil
+}
+
+// addEntry creates the container entry of a service and adds it to the registry.
+// It must be called under the registration lock, and the dependency trees invalidated afterwards.
+func (c *containerImpl) addEntry(serviceType reflect.Type, key string, scope LifecycleScope, ttl time.Duration, factoryFn interface{}) (*containerEntry, error) {
+	if c.closed.Load() {
+		return nil, fmt.Errorf("cannot register service with key %s: %w", key, ErrContainerClosed)
+	}
+	if c.frozen {
+		return nil, fmt.Errorf("cannot register service with key %s: %w", key, ErrFrozen)
+	}
+	if _, exists := c.registry.Get(key); exists || c.isSpecial(key) {
+		return nil, fmt.Errorf("service already registered with key: %s", key)
+	}
+
+	entry, err := newContainerEntry(serviceType, key, scope, factoryFn)
+	if err != nil {
+		return nil, err
+	}
+	entry.ttl = ttl
+	c.registry.Set(key, entry)
+
+	c.logger.Debugf("Registered service: %s with key: %s scope: %v", serviceType.String(), key, scope)
+	return entry, nil
+}
+
+// invalidateDependencyTrees clears the cached dependency trees, so they are computed again on the next resolution,
+// and the keys remembered as unregistered by the negative cache. It must be called after the registry is changed.
+// A new registration may provide an optional field of the In parameter struct of any service.
+func (c *containerImpl) invalidateDependencyTrees() {
+	for _, entry := range c.registry.Values() {
+		entry.dependencyTreeCache.Store(nil)
+	}
+	if c.negativeCache != nil {
+		c.negativeCache.invalidate()
+	}
+}
+
+// newContainerEntry creates a container entry for the given service type, key, scope, and factory function.
+// It returns an error if the factory function is not a function returning exactly one value assignable to the service type.
+func newContainerEntry(serviceType reflect.Type, key string, scope LifecycleScope, factoryFn interface{}) (*containerEntry, error) {
+	// Convert the factory function to a reflect.Value and get its type
+	factoryFnValue := reflect.ValueOf(factoryFn)
+	factoryFnType := factoryFnValue.Type()
+
+	// Ensure the factory function is a valid function and returns exactly one value
+	if factoryFnValue.Kind() != reflect.Func || factoryFnType.NumOut() != 1 {
+		return nil, fmt.Errorf("factoryFn must be a function that returns exactly one value")
+	}
+
+	// Ensure the factory function returns a value that is assignable to the service type
+	if !factoryFnType.Out(0).AssignableTo(serviceType) {
+		return nil, fmt.Errorf("factoryFn must return a value of type %s, returning %s", serviceType.String(), factoryFnType.Out(0).String())
+	}
+
+	// Create a new registry entry for the service
+	entry := &containerEntry{
+		serviceType:        serviceType,
+		key:                key,
+		factoryFn:          factoryFnValue,
+		factoryFnParams:    make([]reflect.Type, factoryFnType.NumIn()),
+		factoryFnParamKeys: make([]string, factoryFnType.NumIn()),
+		scope:              scope,
+	}
+
+	// Store the parameter types of the factory function and their registry keys
+	for i := 0; i < factoryFnType.NumIn(); i++ {
+		entry.factoryFnParams[i] = factoryFnType.In(i)
+		entry.factoryFnParamKeys[i] = diutils.NameOfType(entry.factoryFnParams[i])
+	}
+	return entry, nil
+}
+
+// RegisterToGroup registers a service of the given type as a member of the named group.
+//
+// Group members are Transient and are registered under an internal key, so they do not collide with
+// the registrations of their service type. The order determines the position of the member when the group
+// is resolved, members with the same order keep their registration order.
+func (c *containerImpl) RegisterToGroup(group string, order int, serviceType reflect.Type, factoryFn interface{}) error {
+	if strings.TrimSpace(group) == "" {
+		return fmt.Errorf("group cannot be empty")
+	}
+	if serviceType == nil {
+		return fmt.Errorf("serviceType cannot be nil")
+	}
+	if factoryFn == nil {
+		return fmt.Errorf("factoryFn cannot be nil")
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed.Load() {
+		return fmt.Errorf("cannot register service to group %s: %w", group, ErrContainerClosed)
+	}
+	if c.frozen {
+		return fmt.Errorf("cannot register service to group %s: %w", group, ErrFrozen)
+	}
+
+	// The internal key is unique within the group, and cannot collide with a type key
+	key := groupMemberKey(group, len(c.groups[group]))
+	entry, err := newContainerEntry(serviceType, key, Transient, factoryFn)
+	if err != nil {
+		return err
+	}
+	entry.order = order
+	c.addGroupMember(group, entry)
+
+	c.logger.Debugf("Registered service: %s to group: %s with order: %d", serviceType.String(), group, order)
+	return nil
+}
+
+// addGroupMember registers the given entry under the next internal key of the group,
+// and inserts it after the members with a lower or equal order. It must be called with the container lock held.
+func (c *containerImpl) addGroupMember(group string, entry *containerEntry) {
+	members := c.groups[group]
+	entry.key = groupMemberKey(group, len(members))
+	c.registry.Set(entry.key, entry)
+	if c.negativeCache != nil {
+		c.negativeCache.invalidate()
+	}
+
+	index := sort.Search(len(members), func(i int) bool { return members[i].order > entry.order })
+	members = append(members, nil)
+	copy(members[index+1:], members[index:])
+	members[index] = entry
+	c.groups[group] = members
+}
+
+// groupMemberKey returns the internal registry key of the member of the group at the given registration index.
+func groupMemberKey(group string, index int) string {
+	return fmt.Sprintf("%s%s:%d", groupKeyPrefix, group, index)
+}
+
+// ResolveGroup resolves all the members of the named group within the provided lifecycle context.
+// It returns the instances sorted by their order, or an empty slice if the group has no members.
+func (c *containerImpl) ResolveGroup(group string, ctx LifecycleContext) ([]interface{}, error) {
+	if err := c.beginResolution(); err != nil {
+		return nil, err
+	}
+	defer c.inflight.Done()
+
+	ctx = c.resolveContext(ctx)
+
+	c.mutex.RLock()
+	members := append([]*containerEntry(nil), c.groups[group]...)
+	c.mutex.RUnlock()
+
+	instances := make([]interface{}, 0, len(members))
+	for _, entry := range members {
+		instance, err := c.resolveEntryWithDeps(entry.key, entry, ctx, 0, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve group %s: %w", group, err)
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// ResolveAll resolves every service registered with the given service type, whatever its key,
+// within the provided lifecycle context. It returns the instances sorted by key, or an empty slice if there are none.
+// Aliases are skipped, so each registration is resolved once, and group members are only resolved with their group.
+func (c *containerImpl) ResolveAll(serviceType reflect.Type, ctx LifecycleContext) ([]interface{}, error) {
+	if err := c.beginResolution(); err != nil {
+		return nil, err
+	}
+	defer c.inflight.Done()
+
+	ctx = c.resolveContext(ctx)
+
+	c.mutex.RLock()
+	entries := make([]*containerEntry, 0)
+	c.registry.Range(func(key string, entry *containerEntry) bool {
+		if entry.serviceType == serviceType && entry.key == key && !strings.HasPrefix(key, groupKeyPrefix) {
+			entries = append(entries, entry)
+		}
+		return true
+	})
+	c.mutex.RUnlock()
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+	instances := make([]interface{}, 0, len(entries))
+	for _, entry := range entries {
+		instance, err := c.resolveEntryWithDeps(entry.key, entry, ctx, 0, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve all %s: %w", serviceType.String(), err)
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+// RegisterAlias registers an alias key for the service registered with the target key.
+//
+// The alias resolves to the same registration as the target key, without duplicating the factory function,
+// so singleton and scoped instances are shared whichever key they are resolved with.
+// It returns an error if the target key is not registered or the alias key is already in use.
+func (c *containerImpl) RegisterAlias(aliasKey, targetKey string) error {
+	if strings.TrimSpace(aliasKey) == "" {
+		return fmt.Errorf("alias key cannot be empty")
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed.Load() {
+		return fmt.Errorf("cannot register alias %s: %w", aliasKey, ErrContainerClosed)
+	}
+	if c.frozen {
+		return fmt.Errorf("cannot register alias %s: %w", aliasKey, ErrFrozen)
+	}
+	entry, exists := c.registry.Get(targetKey)
+	if !exists {
+		return fmt.Errorf("cannot register alias %s: %w", aliasKey, &NotRegisteredError{Key: targetKey})
+	}
+	if _, exists := c.registry.Get(aliasKey); exists {
+		return fmt.Errorf("service already registered with key: %s", aliasKey)
+	}
+	c.registry.Set(aliasKey, entry)
+	c.invalidateDependencyTrees()
+
+	c.logger.Debugf("Registered alias: %s for key: %s", aliasKey, entry.key)
+	return nil
+}
+
+// Freeze marks the container as read-only.
+// Subsequent registrations return an error wrapping ErrFrozen, while services can still be resolved.
+func (c *containerImpl) Freeze() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.frozen = true
+	c.logger.Debugf("Container frozen, registrations are no longer accepted")
+}
+
+// Build validates the registrations, precomputes the dependency trees, and freezes the container.
+//
+// It is meant to be called once all services are registered, before the container starts serving resolutions.
+// It returns the validation or dependency tree error, if any, in which case the container is not frozen.
+func (c *containerImpl) Build() error {
+	if err := c.Validate(); err != nil {
+		return fmt.Errorf("failed to build container: %w", err)
+	}
+	if err := c.PrecomputeDependencyTrees(); err != nil {
+		return fmt.Errorf("failed to build container: %w", err)
+	}
+	c.Freeze()
+	return nil
+}
+
+// PrecomputeDependencyTrees computes and caches the dependency tree of every registered service.
+//
+// The trees are computed while holding the container write lock, so later resolutions only read the cached trees.
+// It returns the circular and missing dependency errors found, joined together.
+func (c *containerImpl) PrecomputeDependencyTrees() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	var errs []error
+	seen := make(map[*containerEntry]bool)
+	for _, key := range c.registry.Keys() {
+		entry, exists := c.registry.Get(key)
+		// Aliases share the entry, and so the dependency tree, of their target
+		if !exists || seen[entry] {
+			continue
+		}
+		seen[entry] = true
+
+		if _, err := c.getDependencyTree(entry.key); err != nil {
+			errs = append(errs, fmt.Errorf("failed to compute dependency tree for %s: %w", entry.serviceType.String(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Validate checks that all registered services have their dependencies (factory function parameters) also registered.
+// It returns an error if any service depends on an unregistered type.
+func (c *containerImpl) Validate() error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	registryEntries := c.registry.Values()
+
+	for _, entry := range registryEntries {
+		for i := range entry.factoryFnParamKeys {
+			if isInDependency(entry.factoryFnParams[i]) {
+				if err := c.validateInFields(entry, entry.factoryFnParams[i]); err != nil {
+					return err
+				}
+				continue
+			}
+			// Lazy dependencies and providers are resolved on demand, but the service they resolve must be registered
+			depKey := c.dependencyKey(entry, i)
+			if c.isSpecial(depKey) || c.isSliceDependency(entry.factoryFnParams[i], depKey) {
+				continue
+			}
+			if _, ok := c.registry.Get(depKey); !ok {
+				return fmt.Errorf("service %s depends on unregistered type %s: %w",
+					entry.serviceType.String(), entry.factoryFnParams[i].String(), ErrNotRegistered)
+			}
+		}
+	}
+	return nil
+}
+
+// validateInFields checks that the required fields of the given In parameter struct of the entry are provided by the container,
+// and that the services registered for the fields are assignable to their types.
+func (c *containerImpl) validateInFields(entry *containerEntry, paramType reflect.Type) error {
+	for _, field := range inFields(paramType) {
+		if !c.isProvided(field.key) {
+			if field.optional {
+				continue
+			}
+			return fmt.Errorf("service %s depends on unregistered key %s through field %s of %s: %w",
+				entry.serviceType.String(), field.key, field.name, paramType.String(), ErrNotRegistered)
+		}
+		if dep, ok := c.registry.Get(field.key); ok && !dep.serviceType.AssignableTo(field.typ) {
+			return fmt.Errorf("%w: service %s with key %s is not assignable to field %s of %s",
+				ErrTypeMismatch, dep.serviceType.String(), field.key, field.name, paramType.String())
+		}
+	}
+	return nil
+}
+
+// ValidateStrict validates the registrations more thoroughly than Validate, and reports all problems at once
+// instead of the first one, joined in a single error. For each registered service, it checks that:
+//
+//   - the factory function returns a value assignable to the registered service type, as registrations
+//     keyed by an interface, or imported from another container, may not have been checked against it;
+//   - every dependency of the factory function is registered or special, like Validate;
+//   - a Singleton service does not depend on a Scoped service, directly or through a Lazy dependency or a provider,
+//     which would capture an instance of the first context it is resolved within. These errors wrap ErrCaptiveDependency.
+func (c *containerImpl) ValidateStrict() error {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	keys := c.registry.Keys()
+	sort.Strings(keys)
+
+	var errs []error
+	for _, key := range keys {
+		entry, exists := c.registry.Get(key)
+		// Aliases share the entry of their target, which is validated under its own key
+		if !exists || key != entry.key {
+			continue
+		}
+
+		fnType := entry.factoryFn.Type()
+		if fnType.NumOut() == 0 || !fnType.Out(0).AssignableTo(entry.serviceType) {
+			errs = append(errs, fmt.Errorf("%w: factory for service %s with key %s does not return a value assignable to it",
+				ErrTypeMismatch, entry.serviceType.String(), key))
+		}
+
+		for i := range entry.factoryFnParamKeys {
+			if isInDependency(entry.factoryFnParams[i]) {
+				if err := c.validateInFields(entry, entry.factoryFnParams[i]); err != nil {
+					errs = append(errs, err)
+				}
+			} else if depKey := c.dependencyKey(entry, i); !c.isProvided(depKey) && !c.isSliceDependency(entry.factoryFnParams[i], depKey) {
+				errs = append(errs, fmt.Errorf("service %s depends on unregistered type %s: %w",
+					entry.serviceType.String(), entry.factoryFnParams[i].String(), ErrNotRegistered))
+				continue
+			}
+
+			if entry.scope != Singleton {
+				continue
+			}
+			for _, depKey := range c.dependencyKeys(entry, i) {
+				if dep, ok := c.registry.Get(depKey); ok && dep.scope == Scoped {
+					errs = append(errs, fmt.Errorf("singleton %s depends on scoped %s with key %s: %w",
+						entry.serviceType.String(), dep.serviceType.String(), depKey, ErrCaptiveDependency))
+				}
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateAll validates the registrations, like Validate, then resolves every registered service to run its factory function,
+// so factories failing or panicking at runtime are reported at startup. All errors are collected, the returned slice
+// is empty if every service was resolved.
+//
+// Singletons are created and kept in the container, as they would be on their first resolution.
+// Scoped services are resolved within a throwaway lifecycle context, disposed once the validation completes,
+// and disposable Transient instances are disposed right after their resolution.
+// The validation stops when the provided context is canceled, which also bounds the disposal of the throwaway context.
+func (c *containerImpl) ValidateAll(ctxs ...context.Context) []error {
+	if err := c.Validate(); err != nil {
+		return []error{err}
+	}
+
+	ctx := context.Background()
+	if len(ctxs) > 0 && ctxs[0] != nil {
+		ctx = ctxs[0]
+	}
+
+	keys := c.registry.Keys()
+	sort.Strings(keys)
+
+	lctx := c.newLifecycleContext()
+	var errs []error
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			errs = append(errs, fmt.Errorf("validation canceled: %w", err))
+			break
+		}
+
+		entry, exists := c.registry.Get(key)
+		if !exists || entry.key != key {
+			// Aliases are validated through the service they point to
+			continue
+		}
+		if err := c.validateResolution(ctx, entry, lctx); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if err := lctx.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to dispose validation context: %w", err))
+	}
+	return errs
+}
+
+// validateResolution resolves the given container entry within the provided lifecycle context.
+// It returns an error if the resolution fails, panics, or returns a nil instance.
+// Transient instances are disposed once resolved.
+func (c *containerImpl) validateResolution(ctx context.Context, entry *containerEntry, lctx LifecycleContext) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic while resolving %s: %v", entry.serviceType.String(), r)
+		}
+	}()
+
+	inst, err := c.Resolve(entry.key, lctx)
+	if err != nil {
+		return err
+	}
+
+	value := reflect.ValueOf(inst)
+	if inst == nil || isNilValue(value) {
+		return fmt.Errorf("factory for service %s returned a nil instance", entry.serviceType.String())
+	}
+	if d := c.disposer(); entry.scope == Transient && d.isDisposable(value) {
+		return d.dispose(ctx, entry.key, value)
+	}
+	return nil
+}
+
+// isNilValue indicates whether the given value is a nil pointer, interface, map, slice, channel, or function.
+func isNilValue(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Chan, reflect.Func:
+		return value.IsNil()
+	default:
+		return false
+	}
+}
+
+// Resolve resolves the service identified by the given key within the provided lifecycle context.
+// If no context is provided, the background context is used.
+// It returns the resolved service instance or an error if the service cannot be resolved.
+func (c *containerImpl) Resolve(key string, ctx LifecycleContext) (interface{}, error) {
+	return c.resolveTraced(key, ctx, nil)
+}
+
+// resolveTraced resolves the service identified by the given key, like Resolve, starting the construction spans
+// from the given trace context. A nil trace context starts root spans.
+func (c *containerImpl) resolveTraced(key string, ctx LifecycleContext, trace context.Context) (interface{}, error) {
+	if err := c.beginResolution(); err != nil {
+		return nil, err
+	}
+	defer c.inflight.Done()
+
+	return c.resolveAtDepth(key, ctx, 0, trace)
+}
+
+// ResolveType resolves the service registered with the key of the given type, as returned by KeyFor,
+// for callers that only have a reflect.Type at runtime, such as plugins.
+// If the context is nil, it uses the container's background context.
+//
+// It returns an error wrapping ErrTypeMismatch if the resolved instance is not assignable to the given type.
+func (c *containerImpl) ResolveType(t reflect.Type, ctx LifecycleContext) (interface{}, error) {
+	if t == nil {
+		return nil, fmt.Errorf("type cannot be nil")
+	}
+
+	key := c.KeyFor(t)
+	inst, err := c.Resolve(key, ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve service with key %v: %w", key, err)
+	}
+	if inst != nil && !reflect.TypeOf(inst).AssignableTo(t) {
+		return nil, fmt.Errorf("%w: resolved instance of type %T is not assignable to %v", ErrTypeMismatch, inst, t)
+	}
+	return inst, nil
+}
+
+// resolveAtDepth resolves the service identified by the given key, like Resolve, at the given resolution depth.
+//
+// The depth counts the resolutions nested within factory functions by Lazy dependencies and providers,
+// it returns an error wrapping ErrMaxResolutionDepth when it exceeds the maximum depth of the container.
+// The construction spans of the resolved services start from the given trace context.
+func (c *containerImpl) resolveAtDepth(key string, ctx LifecycleContext, depth int, trace context.Context) (interface{}, error) {
+	if depth > c.maxDepth {
+		return nil, fmt.Errorf("cannot resolve %s: %w (%d)", key, ErrMaxResolutionDepth, c.maxDepth)
+	}
+
+	ctx = c.resolveContext(ctx)
+
+	if v, ok := c.resolveSpecial(key, ctx); ok {
+		return v, nil
+	}
+
+	entry, err := c.getEntry(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.resolveEntryWithDeps(key, entry, ctx, depth, trace)
+}
+
+// resolveContext returns the provided lifecycle context if it is not nil.
+// Otherwise, it returns the container's background context.
+func (c *containerImpl) resolveContext(ctx LifecycleContext) LifecycleContext {
+	if ctx == nil {
+		return c.BackgroundContext()
+	}
+	return ctx
+}
+
+// canonicalKey returns the key the service identified by the given key, or alias, was registered with.
+// It returns the given key if no service is registered with it.
+func (c *containerImpl) canonicalKey(key string) string {
+	if entry, exists := c.registry.Get(key); exists {
+		return entry.key
+	}
+	return key
+}
+
+// ResolveMany resolves the services registered with the given keys within the provided lifecycle context.
+//
+// The dependency trees of the services are merged, so dependencies shared by several services are resolved once,
+// and independent services are resolved concurrently like independent dependencies.
+// It returns the resolved instances keyed by their requested keys. If a service cannot be resolved,
+// the other services are still resolved and the returned error joins the errors of the failing services.
+func (c *containerImpl) ResolveMany(keys []string, ctx LifecycleContext) (map[string]interface{}, error) {
+	if err := c.beginResolution(); err != nil {
+		return nil, err
+	}
+	defer c.inflight.Done()
+
+	ctx = c.resolveContext(ctx)
+
+	instances := make(map[string]interface{}, len(keys))
+	var errs []error
+
+	// Merge the dependency trees, each one is in dependency order, so their concatenation without duplicates is too
+	roots := make(map[string]*containerEntry, len(keys))
+	seen := make(map[*containerEntry]bool)
+	var dependencies []*containerEntry
+	for _, key := range keys {
+		if v, ok := c.resolveSpecial(key, ctx); ok {
+			instances[key] = v
+			continue
+		}
+		entry, err := c.getEntry(key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to resolve %s: %w", key, err))
+			continue
+		}
+		tree, err := c.getDependencyTree(key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to resolve %s: %w", entry.serviceType.String(), err))
+			continue
+		}
+		roots[key] = entry
+		for _, dependency := range tree {
+			if !seen[dependency] {
+				seen[dependency] = true
+				dependencies = append(dependencies, dependency)
+			}
+		}
+	}
+
+	resolved, err := c.resolveDependencies(dependencies, ctx, 0, nil)
+	if err != nil {
+		// Resolve the services one by one, so the services that do not depend on the failing one are still returned
+		for key := range roots {
+			instance, err := c.resolveAtDepth(key, ctx, 0, nil)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			instances[key] = instance
+		}
+		return instances, errors.Join(errs...)
+	}
+
+	for key, entry := range roots {
+		instances[key] = resolved[entry.key].Interface()
+	}
+	return instances, errors.Join(errs...)
+}
+
+// ResolveWithArgs creates an instance of the Transient service registered with the given key,
+// passing the given arguments to the factory function parameters that are not registered services.
+//
+// Each argument is matched to the first unmatched parameter it is assignable to, while the registered
+// dependencies are resolved within the provided lifecycle context as usual.
+// It returns an error if the service is not Transient, if a parameter is neither registered nor matched
+// by an argument, or if an argument is not used.
+func (c *containerImpl) ResolveWithArgs(key string, ctx LifecycleContext, args ...interface{}) (interface{}, error) {
+	if err := c.beginResolution(); err != nil {
+		return nil, err
+	}
+	defer c.inflight.Done()
+
+	ctx = c.resolveContext(ctx)
+
+	entry, err := c.getEntry(key)
+	if err != nil {
+		return nil, err
+	}
+	if entry.scope != Transient {
+		return nil, fmt.Errorf("cannot resolve %s service with key %s with arguments, only Transient services accept arguments", entry.scope, key)
+	}
+
+	used := make([]bool, len(args))
+	params := make([]reflect.Value, len(entry.factoryFnParams))
+	for i, paramType := range entry.factoryFnParams {
+		if c.isRegisteredParam(entry, i) {
+			param, err := c.resolveParam(entry, i, ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to resolve %s: %s: %w", entry.serviceType.String(), paramType.String(), err)
+			}
+			params[i] = param
+			continue
+		}
+
+		matched := false
+		for j, arg := range args {
+			if !used[j] && arg != nil && reflect.TypeOf(arg).AssignableTo(paramType) {
+				params[i], used[j], matched = reflect.ValueOf(arg), true, true
+				break
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("failed to resolve %s: no argument of type %s: %w",
+				entry.serviceType.String(), paramType.String(), &NotRegisteredError{Key: entry.factoryFnParamKeys[i]})
+		}
+	}
+	for j, arg := range args {
+		if !used[j] {
+			return nil, fmt.Errorf("argument %d of type %T does not match any parameter of the factory for %s", j, arg, entry.serviceType.String())
+		}
+	}
+
+	instance, err := c.callFactory(entry, params, ctx, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+	return instance.Interface(), nil
+}
+
+// isRegisteredParam indicates whether the parameter of the given container entry at index i is provided by the container,
+// either as a registered service, a special dependency, a Lazy dependency, or a provider.
+func (c *containerImpl) isRegisteredParam(entry *containerEntry, i int) bool {
+	if isInDependency(entry.factoryFnParams[i]) {
+		for _, field := range inFields(entry.factoryFnParams[i]) {
+			if !field.optional && !c.isProvided(field.key) {
+				return false
+			}
+		}
+		return true
+	}
+	if c.isSliceDependency(entry.factoryFnParams[i], entry.factoryFnParamKeys[i]) {
+		return true
+	}
+	return c.isProvided(c.dependencyKey(entry, i))
+}
+
+// resolveParam resolves the parameter of the given container entry at index i within the provided lifecycle context.
+func (c *containerImpl) resolveParam(entry *containerEntry, i int, ctx LifecycleContext) (reflect.Value, error) {
+	paramType := entry.factoryFnParams[i]
+	paramKey := entry.factoryFnParamKeys[i]
+
+	if v, ok := c.resolveSpecial(paramKey, ctx); ok {
+		return specialValue(paramType, v), nil
+	}
+	if isLazyDependency(paramType) {
+		return reflect.Zero(paramType).Interface().(lazyDependency).bind(c.atDepth(1, nil), ctx), nil
+	}
+	if _, ok := c.registry.Get(paramKey); !ok && isProviderDependency(paramType) {
+		return c.newProvider(paramType, ctx, 1, nil), nil
+	}
+	if isNamedDependency(paramType) {
+		_, targetKey := namedTarget(paramType)
+		inst, err := c.Resolve(targetKey, ctx)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		return wrapNamed(paramType, reflect.ValueOf(inst)), nil
 	}
-
-	// Ensure the factory function returns a value that is assignable to the service type
-	if !factoryFnType.Out(0).AssignableTo(serviceType) {
-		return fmt.Errorf("factoryFn must return a value of type %s, returning %s", serviceType.String(), factoryFnType.Out(0).String())
+	if isInDependency(paramType) {
+		return c.buildIn(paramType, func(key string) (reflect.Value, error) {
+			if v, ok := c.resolveSpecial(key, ctx); ok {
+				return reflect.ValueOf(v), nil
+			}
+			inst, err := c.Resolve(key, ctx)
+			if err != nil || inst == nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(inst), nil
+		})
 	}
-
-	// Create a new registry entry for the service
-	entry := &containerEntry{
-		serviceType:     serviceType,
-		key:             key,
-		factoryFn:       factoryFnValue,
-		factoryFnParams: make([]reflect.Type, factoryFnType.NumIn()),
-		scope:           scope,
+	if c.isSliceDependency(paramType, paramKey) {
+		return c.buildSlice(paramType, c.sliceElementKeys(paramType), func(key string) (reflect.Value, error) {
+			inst, err := c.Resolve(key, ctx)
+			if err != nil || inst == nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(inst), nil
+		})
 	}
-	c.registry.Set(key, entry)
 
-	// Store the parameter types of the factory function
-	for i := 0; i < factoryFnType.NumIn(); i++ {
-		entry.factoryFnParams[i] = factoryFnType.In(i)
+	inst, err := c.Resolve(paramKey, ctx)
+	if err != nil {
+		return reflect.Value{}, err
 	}
-
-	c.logger.Debugf("Registered service: %s with key: %s scope: %v", serviceType.String(), key, scope)
-	return nil
+	if inst == nil {
+		return reflect.Zero(paramType), nil
+	}
+	return reflect.ValueOf(inst), nil
 }
 
-// Validate checks that all registered services have their dependencies (factory function parameters) also registered.
-// It returns an error if any service depends on an unregistered type.
-func (c *containerImpl) Validate() error {
-	c.mutex.RLock()
-	defer c.mutex.RUnlock()
+// InvalidateSingleton disposes and removes the instance of the Singleton service registered with the given key,
+// so the next resolution creates a new instance, for example after a configuration change.
+//
+// The instance is disposed if it implements LifecycleListener or io.Closer, and the disposal error is returned.
+// Invalidating a singleton not created yet is a no-op. It returns an error if the service is not registered
+// or is not a Singleton.
+func (c *containerImpl) InvalidateSingleton(key string) error {
+	entry, err := c.getEntry(key)
+	if err != nil {
+		return fmt.Errorf("cannot invalidate singleton: %w", err)
+	}
+	if entry.scope != Singleton {
+		return fmt.Errorf("cannot invalidate %s service with key %s, only Singleton services can be invalidated", entry.scope, key)
+	}
 
-	registryEntries := c.registry.Values()
+	entry.mutex.Lock()
+	defer entry.mutex.Unlock()
 
-	for _, entry := range registryEntries {
-		for _, dep := range entry.factoryFnParams {
-			depKey := diutils.NameOfType(dep)
-			if depKey == containerReflectedKey || depKey == lifecycleContextReflectedKey {
-				continue
-			}
-			if _, ok := c.registry.Get(depKey); !ok {
-				return fmt.Errorf("service %s depends on unregistered type %s",
-					entry.serviceType.String(), dep.String())
-			}
-		}
+	// Discard the initializer holding the instance, then the cached instance, so the next resolution runs the factory again
+	entry.singleton.Store(nil)
+	if err := c.BackgroundContext().RemoveInstance(entry.key); err != nil {
+		return fmt.Errorf("failed to dispose singleton with key %s: %w", key, err)
 	}
+
+	c.logger.Debugf("Invalidated singleton: %s with key: %s", entry.serviceType.String(), key)
 	return nil
 }
 
-// Resolve resolves the service identified by the given key within the provided lifecycle context.
-// If no context is provided, the background context is used.
-// It returns the resolved service instance or an error if the service cannot be resolved.
-func (c *containerImpl) Resolve(key string, ctx LifecycleContext) (interface{}, error) {
-	ctx = c.resolveContext(ctx)
+// DisposeSingletons disposes all the Singleton instances, so the next resolutions create new ones,
+// for example to recycle the singletons after a configuration reload while the other lifecycle contexts keep serving.
+//
+// The background context holding the singletons is replaced with a fresh one, then shut down, which disposes
+// the instances implementing LifecycleListener or io.Closer. The registrations and the other contexts are kept.
+// It returns the errors encountered during the disposal, or nil.
+func (c *containerImpl) DisposeSingletons(ctxs ...context.Context) []error {
+	previous := c.BackgroundContext()
 
-	if v, ok := c.resolveSpecial(key, ctx); ok {
-		return v, nil
-	}
+	// Swap the background context first, so the resolutions made during the disposal create new singletons
+	c.lifecycleContexts.Set(backgroundContextKey, c.newLifecycleContext())
 
-	entry, err := c.getEntry(key)
-	if err != nil {
-		return nil, err
-	}
+	errs := ShutdownErrors(previous.Shutdown(ctxs...))
 
-	return c.resolveEntryWithDeps(key, entry, ctx)
+	c.logger.Debugf("Disposed singletons of background context %s", previous.ID())
+	return errs
 }
 
-// resolveContext returns the provided lifecycle context if it is not nil.
-// Otherwise, it returns the container's background context.
-func (c *containerImpl) resolveContext(ctx LifecycleContext) LifecycleContext {
-	if ctx == nil {
-		return c.BackgroundContext()
-	}
-	return ctx
+// KeyFor returns the key under which a service of the given type is registered by default,
+// the same key as returned by KeyOf for the corresponding type parameter.
+func (c *containerImpl) KeyFor(serviceType reflect.Type) string {
+	return diutils.NameOfType(serviceType)
 }
 
-// resolveSpecial checks if the given key corresponds to a special service (Container or LifecycleContext).
-// If it does, it returns the corresponding instance and true. Otherwise, it returns nil and false.
-func (c *containerImpl) resolveSpecial(key string, ctx LifecycleContext) (interface{}, bool) {
-	switch key {
-	case containerReflectedKey:
-		return c, true
-	case lifecycleContextReflectedKey:
-		return ctx, true
-	default:
-		return nil, false
+// ScopeOf returns the lifecycle scope of the service registered with the given key,
+// and a boolean indicating whether the service is registered.
+func (c *containerImpl) ScopeOf(key string) (LifecycleScope, bool) {
+	entry, exists := c.registry.Get(key)
+	if !exists {
+		return Transient, false
 	}
+	return entry.scope, true
 }
 
 // getEntry retrieves the container entry for the given key.
 // It returns an error if the entry does not exist.
 func (c *containerImpl) getEntry(key string) (*containerEntry, error) {
+	if c.negativeCache == nil {
+		entry, exists := c.registry.Get(key)
+		if !exists {
+			return nil, &NotRegisteredError{Key: key}
+		}
+		return entry, nil
+	}
+
+	if err, ok := c.negativeCache.get(key); ok {
+		return nil, err
+	}
+	// Read the generation first, so a miss racing with the registration of the key is recorded as stale
+	generation := c.negativeCache.current()
 	entry, exists := c.registry.Get(key)
 	if !exists {
-		return nil, fmt.Errorf("service with key '%s' not registered", key)
+		err := &NotRegisteredError{Key: key}
+		c.negativeCache.record(key, generation, err)
+		return nil, err
 	}
 	return entry, nil
 }
@@ -311,24 +1902,46 @@ func (c *containerImpl) resolveEntryWithDeps(
 	key string,
 	entry *containerEntry,
 	ctx LifecycleContext,
+	depth int,
+	trace context.Context,
 ) (interface{}, error) {
 	serviceType := entry.serviceType
 	c.logger.Debugf("Resolving service: %s with key: %s", serviceType.String(), key)
 
+	// Serve a singleton already created without resolving its dependencies again
+	if cached, ok := c.cachedSingleton(entry); ok {
+		if c.hasObservers() {
+			c.notify(func(o Observer) {
+				o.OnResolveStart(entry.key)
+			})
+			c.notify(func(o Observer) {
+				o.OnResolveEnd(entry.key, true, 0, nil)
+			})
+		}
+		return cached.Interface(), nil
+	}
+
 	// Get the dependency tree for the service
 	dependencies, err := c.getDependencyTree(key)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get dependency tree for %s: %w", serviceType.String(), err)
+		return nil, fmt.Errorf("failed to resolve %s: %w", serviceType.String(), err)
 	}
 
 	// Resolve the dependencies for the service
-	resolved, err := c.resolveDependencies(dependencies, ctx)
+	resolved, err := c.resolveDependencies(dependencies, ctx, depth, trace)
 	if err != nil {
-		return nil, fmt.Errorf("failed to resolve dependencies for %s: %w", serviceType.String(), err)
+		// Report the chain of services leading from the requested service to the failing dependency
+		var depErr *dependencyError
+		if errors.As(err, &depErr) {
+			if path := c.dependencyPath(key, depErr.key); len(path) > 0 {
+				return nil, fmt.Errorf("failed to resolve %s: %w", strings.Join(path, ": "), depErr.err)
+			}
+		}
+		return nil, fmt.Errorf("failed to resolve %s: %w", serviceType.String(), err)
 	}
 
 	// Retrieve the resolved instance for the requested service
-	value, exists := resolved[key]
+	value, exists := resolved[entry.key]
 	if !exists {
 		return nil, fmt.Errorf("failed to resolve service: %s", serviceType.String())
 	}
@@ -342,24 +1955,21 @@ func (c *containerImpl) resolveEntryWithDeps(
 // It detects circular dependencies and returns an error if any are found.
 func (c *containerImpl) getDependencyTree(key string) ([]*containerEntry, error) {
 
-	if entry, exists := c.registry.Get(key); exists && entry.dependencyTreeCache != nil {
-		return entry.dependencyTreeCache, nil
+	if entry, exists := c.registry.Get(key); exists {
+		if cached := entry.dependencyTreeCache.Load(); cached != nil {
+			return *cached, nil
+		}
 	}
 	seen := make(map[*containerEntry]bool)
 	visiting := make(map[*containerEntry]bool)
 	order := make([]*containerEntry, 0)
+	// path keeps the chain of services being visited, to report circular dependencies
+	path := make([]*containerEntry, 0)
 
 	var visit func(string) error
 	visit = func(k string) error {
-		// If the type is Container or LifecycleContext, we don't need to resolve its dependencies
-		if k == containerReflectedKey || k == lifecycleContextReflectedKey {
-			var typ reflect.Type
-			switch k {
-			case containerReflectedKey:
-				typ = diutils.TypeOf[Container]()
-			case lifecycleContextReflectedKey:
-				typ = diutils.TypeOf[LifecycleContext]()
-			}
+		// If the type is special, such as Container or LifecycleContext, we don't need to resolve its dependencies
+		if typ, ok := c.specialTypeOf(k); ok {
 			fakeEntry := &containerEntry{
 				serviceType: typ,
 				key:         k,
@@ -374,22 +1984,91 @@ func (c *containerImpl) getDependencyTree(key string) ([]*containerEntry, error)
 		// Retrieve the container entry for the current key
 		entry, exists := c.registry.Get(k)
 		if !exists {
-			return fmt.Errorf("service not found: %s", k)
+			return &NotRegisteredError{Key: k}
 		}
 
 		if visiting[entry] {
-			return fmt.Errorf("circular dependency detected for service: %s", entry.serviceType.String())
+			return newCircularDependencyError(path, entry)
 		}
 		if seen[entry] {
 			return nil
 		}
 		visiting[entry] = true
-
-		for _, dep := range entry.factoryFnParams {
-			if err := visit(diutils.NameOfType(dep)); err != nil {
-				return err
+		path = append(path, entry)
+
+		for i, depKey := range entry.factoryFnParamKeys {
+			// Lazy dependencies are resolved on demand, so they are not edges of the dependency tree
+			if isLazyDependency(entry.factoryFnParams[i]) {
+				order = append(order, &containerEntry{
+					serviceType: entry.factoryFnParams[i],
+					key:         depKey,
+					scope:       Transient,
+					lazy:        true,
+				})
+				continue
+			}
+			// Providers are resolved on demand too, unless the function type itself is registered,
+			// but the service they create must be registered
+			if _, ok := c.registry.Get(depKey); !ok && isProviderDependency(entry.factoryFnParams[i]) {
+				targetKey := providerTargetKey(entry.factoryFnParams[i])
+				if _, ok := c.registry.Get(targetKey); !ok && !c.isSpecial(targetKey) {
+					return fmt.Errorf("%s: %w", entry.factoryFnParams[i].String(), &NotRegisteredError{Key: targetKey})
+				}
+				order = append(order, &containerEntry{
+					serviceType: entry.factoryFnParams[i],
+					key:         depKey,
+					scope:       Transient,
+					provider:    true,
+				})
+				continue
+			}
+			// Named dependencies wrap the service registered with their qualified key, which is an edge of the dependency tree
+			if isNamedDependency(entry.factoryFnParams[i]) {
+				targetType, targetKey := namedTarget(entry.factoryFnParams[i])
+				if err := visit(targetKey); err != nil {
+					return fmt.Errorf("%s: %w", entry.factoryFnParams[i].String(), err)
+				}
+				order = append(order, &containerEntry{
+					serviceType:        entry.factoryFnParams[i],
+					key:                depKey,
+					factoryFnParams:    []reflect.Type{targetType},
+					factoryFnParamKeys: []string{targetKey},
+					scope:              Transient,
+					named:              true,
+				})
+				continue
+			}
+			// In parameter structs are filled with the services of their fields, which are edges of the dependency tree
+			if paramType := entry.factoryFnParams[i]; isInDependency(paramType) {
+				for _, field := range inFields(paramType) {
+					if field.optional && !c.isProvided(field.key) {
+						continue
+					}
+					if err := visit(field.key); err != nil {
+						return fmt.Errorf("%s.%s: %w", paramType.String(), field.name, err)
+					}
+				}
+				order = append(order, c.newInEntry(paramType, depKey))
+				continue
+			}
+			// Slices of an unregistered type are injected with the services assignable to their element type,
+			// which are edges of the dependency tree
+			if paramType := entry.factoryFnParams[i]; c.isSliceDependency(paramType, depKey) {
+				sliceEntry := c.newSliceEntry(paramType, depKey)
+				for _, elemKey := range sliceEntry.factoryFnParamKeys {
+					if err := visit(elemKey); err != nil {
+						return fmt.Errorf("%s: %w", paramType.String(), err)
+					}
+				}
+				order = append(order, sliceEntry)
+				continue
+			}
+			if err := visit(depKey); err != nil {
+				// Prefix the error with the dependency, so it reads as the chain of services from the requested one
+				return fmt.Errorf("%s: %w", entry.factoryFnParams[i].String(), err)
 			}
 		}
+		path = path[:len(path)-1]
 		visiting[entry] = false
 		seen[entry] = true
 		order = append(order, entry)
@@ -400,84 +2079,525 @@ func (c *containerImpl) getDependencyTree(key string) ([]*containerEntry, error)
 	}
 
 	if entry, exists := c.registry.Get(key); exists {
-		entry.dependencyTreeCache = order
+		entry.dependencyTreeCache.Store(&order)
 	}
 
 	return order, nil
 }
 
+// dependencyPath returns the service types on a dependency chain going from the service identified by rootKey
+// to the service identified by targetKey, both included. It returns nil if there is no such chain.
+func (c *containerImpl) dependencyPath(rootKey, targetKey string) []string {
+	seen := make(map[string]bool)
+
+	var find func(string) []string
+	find = func(key string) []string {
+		entry, exists := c.registry.Get(key)
+		if !exists || seen[key] {
+			return nil
+		}
+		seen[key] = true
+
+		if key == targetKey {
+			return []string{entry.serviceType.String()}
+		}
+		for i := range entry.factoryFnParamKeys {
+			for _, depKey := range c.dependencyKeys(entry, i) {
+				if path := find(depKey); path != nil {
+					return append([]string{entry.serviceType.String()}, path...)
+				}
+			}
+		}
+		return nil
+	}
+	return find(rootKey)
+}
+
+// newCircularDependencyError creates a CircularDependencyError for the cycle closed by the given entry,
+// keeping only the part of the visiting path that starts at the entry.
+func newCircularDependencyError(path []*containerEntry, entry *containerEntry) error {
+	cycle := make([]string, 0, len(path)+1)
+	for i, visited := range path {
+		if visited == entry {
+			for _, e := range path[i:] {
+				cycle = append(cycle, e.serviceType.String())
+			}
+			break
+		}
+	}
+	cycle = append(cycle, entry.serviceType.String())
+	return &CircularDependencyError{Path: cycle}
+}
+
 // resolveDependencies resolves the dependencies for the given container entries within the provided lifecycle context.
 // It returns a map of resolved instances keyed by their service keys, or an error if any dependency cannot be resolved.
-func (c *containerImpl) resolveDependencies(dependencies []*containerEntry, ctx LifecycleContext) (map[string]reflect.Value, error) {
-	resolved := make(map[string]reflect.Value)
+//
+// The dependencies are grouped into levels, where each entry only depends on entries of previous levels.
+// Entries within the same level are independent of each other and are resolved concurrently.
+// The resolved map is only written between levels, so every factory sees a consistent view of its dependencies.
+func (c *containerImpl) resolveDependencies(dependencies []*containerEntry, ctx LifecycleContext, depth int, trace context.Context) (map[string]reflect.Value, error) {
+	resolved := make(map[string]reflect.Value, len(dependencies))
+	for _, level := range c.dependencyLevels(dependencies) {
+		instances, err := c.resolveLevel(level, ctx, resolved, depth, trace)
+		if err != nil {
+			return nil, err
+		}
+
+		// Add the created instances to the resolved map
+		for i, entry := range level {
+			resolved[entry.key] = instances[i]
+		}
+	}
+	return resolved, nil
+}
+
+// dependencyLevels groups the given dependency order into levels.
+// An entry is placed one level above the deepest of its dependencies, so entries without dependencies are in the first level.
+func (c *containerImpl) dependencyLevels(dependencies []*containerEntry) [][]*containerEntry {
+	depths := make(map[string]int, len(dependencies))
+	levels := make([][]*containerEntry, 0)
 	for _, entry := range dependencies {
-		depType := entry.serviceType
-		// If the dependency is of type LifecycleContext, use the provided context
-		if entry.key == lifecycleContextReflectedKey {
-			resolved[entry.key] = reflect.ValueOf(ctx)
-			continue
+		depth := 0
+		for _, paramKey := range entry.factoryFnParamKeys {
+			paramDepth, ok := depths[paramKey]
+			if !ok {
+				// The dependency may be registered through an alias
+				paramDepth, ok = depths[c.canonicalKey(paramKey)]
+			}
+			if ok && paramDepth+1 > depth {
+				depth = paramDepth + 1
+			}
 		}
-		// If the dependency is of type Container, use the current container instance
-		if entry.key == containerReflectedKey {
-			resolved[entry.key] = reflect.ValueOf(c)
-			continue
+		depths[entry.key] = depth
+
+		// The dependency order is topological, so the depth never skips a level
+		if depth == len(levels) {
+			levels = append(levels, nil)
 		}
+		levels[depth] = append(levels[depth], entry)
+	}
+	return levels
+}
 
-		c.logger.Debugf("Resolving dependency: %s", depType.String())
-		// Resolve the current dependency within a locked context to ensure thread safety
-		instance, err := func() (reflect.Value, error) {
-			if entry.scope == Singleton || entry.scope == Scoped {
-				entry.mutex.Lock()
-				defer entry.mutex.Unlock()
+// resolveLevel resolves the independent container entries of a single dependency level.
+//
+// When the level holds more than one entry, the entries are resolved concurrently, bounded by a semaphore.
+// It returns the instances in the same order as the entries, or the first error in dependency order.
+// A panic raised by a factory function is propagated to the calling goroutine.
+func (c *containerImpl) resolveLevel(level []*containerEntry, ctx LifecycleContext, resolved map[string]reflect.Value, depth int, trace context.Context) ([]reflect.Value, error) {
+	instances := make([]reflect.Value, len(level))
+
+	if len(level) == 1 || c.maxConcurrency == 1 {
+		for i, entry := range level {
+			instance, err := c.resolveDependency(entry, ctx, resolved, depth, trace)
+			if err != nil {
+				return nil, err
 			}
+			instances[i] = instance
+		}
+		return instances, nil
+	}
+
+	errs := make([]error, len(level))
+	panics := make([]interface{}, len(level))
+
+	semaphore := diutils.NewSemaphore(c.maxConcurrency)
+	defer semaphore.Done()
+
+	wg := sync.WaitGroup{}
+	for i, entry := range level {
+		semaphore.Acquire()
+
+		wg.Add(1)
+		go func(i int, entry *containerEntry) {
+			defer wg.Done()
+			defer semaphore.Release()
+			defer func() {
+				panics[i] = recover()
+			}()
+
+			instances[i], errs[i] = c.resolveDependency(entry, ctx, resolved, depth, trace)
+		}(i, entry)
+	}
+	wg.Wait()
+
+	for i := range level {
+		if panics[i] != nil {
+			panic(panics[i])
+		}
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
+	}
+	return instances, nil
+}
 
-			var zero reflect.Value
-			// Check if the instance is already cached for Singleton or Scoped scope
-			cached, ok := c.loadInstance(ctx, entry)
-			if ok {
-				c.logger.Debugf("Using cached instance for: %s", depType.String())
-				return cached, nil
+// resolveDependency resolves a single container entry within the provided lifecycle context.
+// The special entries are resolved to the current container and context, or to the value supplied by their provider.
+func (c *containerImpl) resolveDependency(entry *containerEntry, ctx LifecycleContext, resolved map[string]reflect.Value, depth int, trace context.Context) (reflect.Value, error) {
+	// If the dependency is special, such as Container or LifecycleContext, use the value it stands for
+	if v, ok := c.resolveSpecial(entry.key, ctx); ok {
+		return specialValue(entry.serviceType, v), nil
+	}
+	// If the dependency is a Lazy, bind it to the current container and context, its resolution is nested one level deeper
+	if entry.lazy {
+		return reflect.Zero(entry.serviceType).Interface().(lazyDependency).bind(c.atDepth(depth+1, trace), ctx), nil
+	}
+	// If the dependency is a provider, create it for the current container and context, its resolutions are nested one level deeper
+	if entry.provider {
+		return c.newProvider(entry.serviceType, ctx, depth+1, trace), nil
+	}
+	// If the dependency is a Named, wrap the instance of its qualified service, resolved in a previous level
+	if entry.named {
+		instance, exists := resolved[entry.factoryFnParamKeys[0]]
+		if !exists {
+			instance = resolved[c.canonicalKey(entry.factoryFnParamKeys[0])]
+		}
+		return wrapNamed(entry.serviceType, instance), nil
+	}
+	// If the dependency is an In parameter struct, fill it with the instances of its fields, resolved in previous levels
+	if entry.in {
+		return c.buildIn(entry.serviceType, func(key string) (reflect.Value, error) {
+			instance, exists := resolved[key]
+			if !exists {
+				instance = resolved[c.canonicalKey(key)]
+			}
+			return instance, nil
+		})
+	}
+	// If the dependency is a slice, fill it with the instances of the services assignable to its element type
+	if entry.slice {
+		return c.buildSlice(entry.serviceType, entry.factoryFnParamKeys, func(key string) (reflect.Value, error) {
+			instance, exists := resolved[key]
+			if !exists {
+				return reflect.Value{}, fmt.Errorf("dependency %s of %s not resolved", key, entry.serviceType.String())
 			}
+			return instance, nil
+		})
+	}
 
-			// Resolve the dependencies for the factory function
-			params := make([]reflect.Value, 0, len(entry.factoryFnParams))
-			for _, paramType := range entry.factoryFnParams {
-				paramValue, exists := resolved[diutils.NameOfType(paramType)]
-				if !exists {
-					return zero, fmt.Errorf("dependency %s for service %s not resolved", paramType.String(), depType.String())
-				}
-				params = append(params, paramValue)
+	depType := entry.serviceType
+	c.logger.Debugf("Resolving dependency: %s", depType.String())
+	if err := c.checkScope(entry, ctx); err != nil {
+		return reflect.Value{}, &dependencyError{key: entry.key, err: err}
+	}
+	instance, err := c.observeInstance(entry, ctx, resolved, depth, trace)
+	if err != nil {
+		return reflect.Value{}, &dependencyError{key: entry.key, err: err}
+	}
+	return instance, nil
+}
+
+// checkScope reports a Scoped service resolved within the background context, where it would behave as a singleton.
+// It returns an error wrapping ErrScopeRequired with strict scopes, and only logs a debug message otherwise.
+func (c *containerImpl) checkScope(entry *containerEntry, ctx LifecycleContext) error {
+	if entry.scope != Scoped || (ctx != nil && ctx.ID() != c.BackgroundContext().ID()) {
+		return nil
+	}
+	if c.strictScopes {
+		return fmt.Errorf("cannot resolve %s within the background context: %w", entry.serviceType.String(), ErrScopeRequired)
+	}
+	c.logger.Debugf("Warning: Scoped service %s resolved within the background context, the instance is shared like a singleton", entry.serviceType.String())
+	return nil
+}
+
+// observeInstance resolves the instance for the given container entry, notifying the observers of the container
+// before and after the resolution.
+func (c *containerImpl) observeInstance(entry *containerEntry, ctx LifecycleContext, resolved map[string]reflect.Value, depth int, trace context.Context) (reflect.Value, error) {
+	if !c.hasObservers() {
+		instance, _, err := c.resolveInstance(entry, ctx, resolved, depth, trace)
+		return instance, err
+	}
+
+	c.notify(func(o Observer) {
+		o.OnResolveStart(entry.key)
+	})
+	start := time.Now()
+	instance, cached, err := c.resolveInstance(entry, ctx, resolved, depth, trace)
+	elapsed := time.Since(start)
+	c.notify(func(o Observer) {
+		o.OnResolveEnd(entry.key, cached, elapsed, err)
+	})
+	return instance, err
+}
+
+// resolveInstance returns the instance for the given container entry, either from the cache or by invoking its factory.
+// It also indicates whether the instance was served from the cache rather than created by this call.
+// The factory parameters are looked up in the resolved map, so all dependencies of the entry must be resolved first.
+//
+// For Singleton and Scoped entries, no lock is held while the factory function runs, so a factory may resolve
+// other services through the injected Container. Single construction is guaranteed by a sync.Once initializer,
+// bound to the background context for singletons and to the provided context for scoped instances.
+func (c *containerImpl) resolveInstance(entry *containerEntry, ctx LifecycleContext, resolved map[string]reflect.Value, depth int, trace context.Context) (reflect.Value, bool, error) {
+	if entry.scope == Transient {
+		instance, err := c.createInstance(entry, ctx, resolved, depth, trace)
+		if err != nil {
+			return reflect.Value{}, false, err
+		}
+		c.logger.Debugf("Created new instance for: %s", entry.serviceType.String())
+		return instance, false, nil
+	}
+
+	// Check if the instance is already cached for Singleton or Scoped scope
+	if cached, ok := c.cachedSingleton(entry); ok {
+		return cached, true, nil
+	}
+	if cached, ok := c.loadInstance(ctx, entry); ok {
+		c.logger.Debugf("Using cached instance for: %s", entry.serviceType.String())
+		return cached, true, nil
+	}
+
+	init, release := c.instanceInitializer(entry, ctx)
+	defer release()
+
+	created := false
+	init.once.Do(func() {
+		// Check the cache again, a concurrent resolution may have stored the instance in the meantime
+		if cached, ok := c.loadInstance(ctx, entry); ok {
+			init.value = cached
+			init.ready.Store(true)
+			return
+		}
+
+		instance, err := c.createInstance(entry, ctx, resolved, depth, trace)
+		if err == nil {
+			// Persist the created instance based on its lifecycle scope, keeping the instance that ends up stored
+			instance, err = c.persistInstance(ctx, entry, instance)
+		}
+		init.value, init.err = instance, err
+		created = true
+		if err == nil {
+			init.ready.Store(true)
+			c.logger.Debugf("Created new instance for: %s", entry.serviceType.String())
+		}
+	})
+
+	if init.err != nil {
+		return reflect.Value{}, false, init.err
+	}
+	return init.value, !created, nil
+}
+
+// cachedSingleton returns the instance of the given Singleton entry without locking,
+// once it has been created within the current background context.
+func (c *containerImpl) cachedSingleton(entry *containerEntry) (reflect.Value, bool) {
+	if entry.scope != Singleton {
+		return reflect.Value{}, false
+	}
+	init := entry.singleton.Load()
+	if init == nil || !init.ready.Load() || init.contextID != c.BackgroundContext().ID() {
+		return reflect.Value{}, false
+	}
+	return init.value, true
+}
+
+// instanceInitializer returns the initializer for the given Singleton or Scoped entry, and a function to release it.
+//
+// Singleton initializers live as long as the background context, unless the construction fails.
+// Scoped initializers are released once the construction completes, as the instance is then cached in the context.
+func (c *containerImpl) instanceInitializer(entry *containerEntry, ctx LifecycleContext) (*instanceInit, func()) {
+	if entry.scope == Singleton {
+		init := entry.singletonInitializer(c.BackgroundContext().ID())
+		return init, func() {
+			// Discard the failed initializer so the next resolution can retry
+			if init.err != nil {
+				entry.singleton.CompareAndSwap(init, nil)
 			}
+		}
+	}
+
+	if ctx == nil {
+		ctx = c.BackgroundContext()
+	}
+	init := entry.scopedInitializer(ctx.ID())
+	return init, func() {
+		entry.releaseScopedInitializer(init)
+	}
+}
+
+// createInstance invokes the factory function of the given container entry with its resolved dependencies.
+// It returns an error if a dependency is missing or the factory returns an invalid instance.
+func (c *containerImpl) createInstance(entry *containerEntry, ctx LifecycleContext, resolved map[string]reflect.Value, depth int, trace context.Context) (reflect.Value, error) {
+	var zero reflect.Value
+	depType := entry.serviceType
+
+	// Resolve the dependencies for the factory function
+	params := make([]reflect.Value, 0, len(entry.factoryFnParams))
+	for i, paramKey := range entry.factoryFnParamKeys {
+		paramValue, exists := resolved[paramKey]
+		if !exists {
+			// The dependency may be registered through an alias
+			paramValue, exists = resolved[c.canonicalKey(paramKey)]
+		}
+		if !exists {
+			return zero, fmt.Errorf("dependency %s for service %s not resolved", entry.factoryFnParams[i].String(), depType.String())
+		}
+		if paramKey == containerReflectedKey && entry.scope == Singleton {
+			// A singleton outlives the context it is resolved within, its factory resolves within the background context
+			paramValue = reflect.ValueOf(c)
+		}
+		params = append(params, paramValue)
+	}
 
-			// Call the factory function to create a new instance
-			instance := entry.factoryFn.Call(params)[0]
+	// Call the factory function to create a new instance
+	return c.callFactory(entry, params, ctx, depth, trace)
+}
 
-			// Verify that the created instance is valid and of the expected type
-			if !instance.IsValid() || !instance.Type().AssignableTo(entry.serviceType) {
-				return zero, fmt.Errorf(
-					"factory for service %s returned an instance of type %s, expected %s",
-					depType.String(),
-					instance.Type().String(),
-					entry.serviceType.String(),
-				)
+// callFactory invokes the factory function of the given container entry with the given parameters,
+// and verifies that the created instance is valid and of the expected type.
+//
+// When a tracer is configured, the invocation is wrapped in a span started from the given trace context,
+// and ended with the construction error, or with the panic raised by the factory function, which is propagated afterwards.
+// The Container, Lazy and provider parameters are bound to the span, so the services the factory function resolves
+// through them are traced as children of the span.
+func (c *containerImpl) callFactory(entry *containerEntry, params []reflect.Value, ctx LifecycleContext, depth int, trace context.Context) (instance reflect.Value, err error) {
+	if c.tracer != nil {
+		if trace == nil {
+			trace = context.Background()
+		}
+		span, end := c.tracer(trace, entry.key)
+		params = c.bindParamsToSpan(entry, params, ctx, depth, span)
+		defer func() {
+			if r := recover(); r != nil {
+				end(fmt.Errorf("panic in factory for service %s: %v", entry.serviceType.String(), r))
+				panic(r)
+			}
+			end(err)
+		}()
+	}
+	// Factory functions built by the container report their errors with a factoryError panic
+	defer func() {
+		if r := recover(); r != nil {
+			failure, ok := r.(factoryError)
+			if !ok {
+				panic(r)
 			}
+			instance, err = reflect.Value{}, failure.err
+		}
+	}()
+
+	if c.constructionTimeout > 0 {
+		if instance, err = c.callFactoryWithTimeout(entry, params); err != nil {
+			return reflect.Value{}, err
+		}
+	} else {
+		instance = entry.factoryFn.Call(params)[0]
+	}
+
+	// Verify that the created instance is valid and of the expected type
+	if !instance.IsValid() || !instance.Type().AssignableTo(entry.serviceType) {
+		return reflect.Value{}, fmt.Errorf(
+			"factory for service %s returned an instance of type %s, expected %s",
+			entry.serviceType.String(),
+			instance.Type().String(),
+			entry.serviceType.String(),
+		)
+	}
+	return instance, nil
+}
 
-			// Persist the created instance based on its lifecycle scope
-			if err := c.persistInstance(ctx, entry, instance); err != nil {
-				return zero, err
+// bindParamsToSpan returns a copy of the given factory parameters, where the Container, Lazy and provider parameters
+// start the construction spans of the services they resolve from the given span context.
+func (c *containerImpl) bindParamsToSpan(entry *containerEntry, params []reflect.Value, ctx LifecycleContext, depth int, span context.Context) []reflect.Value {
+	bound := append([]reflect.Value(nil), params...)
+	for i, paramType := range entry.factoryFnParams {
+		paramKey := entry.factoryFnParamKeys[i]
+		switch {
+		case paramKey == containerReflectedKey:
+			if entry.scope == Singleton {
+				// A singleton outlives the context it is resolved within, its factory resolves within the background context
+				bound[i] = reflect.ValueOf(c.tracedAt(nil, span))
+			} else {
+				bound[i] = reflect.ValueOf(c.tracedAt(ctx, span))
 			}
+		case isLazyDependency(paramType):
+			bound[i] = reflect.Zero(paramType).Interface().(lazyDependency).bind(c.atDepth(depth+1, span), ctx)
+		case isProviderDependency(paramType):
+			if _, ok := c.registry.Get(paramKey); !ok {
+				bound[i] = c.newProvider(paramType, ctx, depth+1, span)
+			}
+		}
+	}
+	return bound
+}
 
-			c.logger.Debugf("Created new instance for: %s", depType.String())
-			return instance, nil
+// factoryError is raised as a panic by the factory functions built by the container, such as the pooled ones,
+// to fail the resolution with its error, as factory functions return a single value.
+type factoryError struct {
+	err error
+}
+
+// factoryResult is the outcome of a factory function invoked by callFactoryWithTimeout.
+type factoryResult struct {
+	instance reflect.Value // The instance returned by the factory function
+	panicked bool          // Whether the factory function panicked
+	panic    interface{}   // The value the factory function panicked with
+}
+
+// Outcomes of a factory function invoked by callFactoryWithTimeout, whichever of the factory and the timeout comes first wins.
+const (
+	factoryPending int32 = iota
+	factoryCompleted
+	factoryAbandoned
+)
+
+// callFactoryWithTimeout invokes the factory function of the given container entry in its own goroutine,
+// and returns an error wrapping ErrConstructionTimeout if it does not return within the construction timeout.
+// A panic raised by the factory function in time is propagated to the caller.
+//
+// The instance returned by an abandoned factory function is discarded, and disposed if it is disposable.
+func (c *containerImpl) callFactoryWithTimeout(entry *containerEntry, params []reflect.Value) (reflect.Value, error) {
+	var state atomic.Int32
+	done := make(chan factoryResult, 1)
+
+	go func() {
+		var result factoryResult
+		defer func() {
+			if r := recover(); r != nil {
+				result = factoryResult{panicked: true, panic: r}
+			}
+			if state.CompareAndSwap(factoryPending, factoryCompleted) {
+				done <- result
+				return
+			}
+			c.discardInstance(entry, result)
 		}()
-		if err != nil {
-			return nil, fmt.Errorf("failed to resolve dependency %s: %w", depType.String(), err)
+		result.instance = entry.factoryFn.Call(params)[0]
+	}()
+
+	timer := time.NewTimer(c.constructionTimeout)
+	defer timer.Stop()
+
+	var result factoryResult
+	select {
+	case result = <-done:
+	case <-timer.C:
+		if state.CompareAndSwap(factoryPending, factoryAbandoned) {
+			return reflect.Value{}, fmt.Errorf("factory for service %s did not return within %v: %w",
+				entry.serviceType.String(), c.constructionTimeout, ErrConstructionTimeout)
 		}
+		// The factory function completed while the timer fired
+		result = <-done
+	}
+	if result.panicked {
+		panic(result.panic)
+	}
+	return result.instance, nil
+}
 
-		// Add the created instance to the resolved map
-		resolved[entry.key] = instance
+// discardInstance disposes the instance returned by a factory function abandoned after the construction timeout.
+func (c *containerImpl) discardInstance(entry *containerEntry, result factoryResult) {
+	if result.panicked {
+		c.logger.Warnf("Abandoned factory for service %s panicked: %v", entry.serviceType.String(), result.panic)
+		return
+	}
+	if !result.instance.IsValid() || !c.disposer().isDisposable(result.instance) {
+		c.logger.Debugf("Discarded instance of abandoned factory for service %s", entry.serviceType.String())
+		return
+	}
+	if err := c.disposer().dispose(context.Background(), entry.key, result.instance); err != nil {
+		c.logger.Errorf("Failed to dispose instance of abandoned factory for service %s: %v", entry.serviceType.String(), err)
 	}
-	return resolved, nil
 }
 
 // loadInstance attempts to load a cached instance of the given service type based on its scope.
@@ -492,7 +2612,7 @@ func (c *containerImpl) loadInstance(ctx LifecycleContext, entry *containerEntry
 		if cached, exists := bgCtx.GetInstance(entry.key); exists {
 			return cached, true
 		}
-	case Scoped:
+	case Scoped, ScopedOrSingleton:
 		// For Scoped scope, use the provided lifecycle context or fall back to the container's background lifecycle context
 		if ctx == nil {
 			ctx = c.BackgroundContext()
@@ -509,28 +2629,27 @@ func (c *containerImpl) loadInstance(ctx LifecycleContext, entry *containerEntry
 }
 
 // persistInstance stores the given instance in the appropriate cache based on its scope.
-func (c *containerImpl) persistInstance(ctx LifecycleContext, entry *containerEntry, instance reflect.Value) error {
+//
+// It returns the instance stored in the cache, which is the existing instance if a concurrent resolution,
+// or a call to SetInstance, stored one first. The instance is stored or loaded in a single atomic operation.
+func (c *containerImpl) persistInstance(ctx LifecycleContext, entry *containerEntry, instance reflect.Value) (reflect.Value, error) {
 	switch entry.scope {
-	case Singleton:
-		// For Singleton scope, use the container's background lifecycle context
-		bgCtx := c.BackgroundContext()
-		// Store the singleton instance in the container background lifecycle context if it doesn't already exist
-		if _, exists := bgCtx.GetInstance(entry.key); !exists {
-			if err := bgCtx.SetInstance(entry.key, instance); err != nil {
-				return err
-			}
-		}
-	case Scoped:
-		// For Scoped scope, use the provided lifecycle context or fall back to the container's background lifecycle context
-		if ctx == nil {
+	case Singleton, Scoped, ScopedOrSingleton:
+		// Singletons are stored in the container's background lifecycle context, and scoped instances
+		// in the provided lifecycle context, falling back to the background lifecycle context
+		if entry.scope == Singleton || ctx == nil {
 			ctx = c.BackgroundContext()
 		}
-		// Store the scoped instance in the current lifecycle context
-		if err := ctx.SetInstance(entry.key, instance); err != nil {
-			return err
+		stored, loaded, err := ctx.SetInstanceIfAbsent(entry.key, instance)
+		if err != nil {
+			return reflect.Value{}, err
 		}
+		if loaded {
+			c.logger.Debugf("Using instance stored concurrently for: %s", entry.serviceType.String())
+		}
+		return stored, nil
 	case Transient:
 		// For Transient scope, do not cache the instance; it will be created anew each time
 	}
-	return nil
+	return instance, nil
 }
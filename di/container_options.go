@@ -0,0 +1,125 @@
+package di
+
+import (
+	"context"
+	"time"
+
+	dilogger "github.com/lcrux/go-di/di/di-logger"
+)
+
+// ContainerOption configures a container created by NewContainer.
+type ContainerOption func(*containerImpl)
+
+// WithLogger sets the logger used by the container and its lifecycle contexts.
+// A nil logger is ignored and the default logger is used instead.
+func WithLogger(logger dilogger.Logger) ContainerOption {
+	return func(c *containerImpl) {
+		if logger != nil {
+			c.logger = logger
+		}
+	}
+}
+
+// WithMaxConcurrency sets the maximum number of concurrent operations performed by the container,
+// such as resolving independent dependencies and shutting down lifecycle contexts.
+//
+// A value less than or equal to 0 uses the default semaphore capacity (see GODI_SEMAPHORE_CAPACITY).
+// A value of 1 makes the container resolve dependencies sequentially.
+func WithMaxConcurrency(n int) ContainerOption {
+	return func(c *containerImpl) {
+		if n > 0 {
+			c.maxConcurrency = n
+		}
+	}
+}
+
+// WithStrictScopes makes the container return an error wrapping ErrScopeRequired when a Scoped service is resolved
+// within the background context, typically because no lifecycle context was passed to Resolve.
+// Without this option, the Scoped instance is cached in the background context, and a debug message is logged.
+func WithStrictScopes() ContainerOption {
+	return func(c *containerImpl) {
+		c.strictScopes = true
+	}
+}
+
+// WithContextLeakWarning makes NewContext log a warning when the number of active lifecycle contexts, not counting
+// the background context, exceeds the given threshold. It helps detecting contexts that are never removed with RemoveContext.
+// A value less than or equal to 0 disables the warning, which is the default.
+func WithContextLeakWarning(threshold int) ContainerOption {
+	return func(c *containerImpl) {
+		if threshold > 0 {
+			c.leakThreshold = threshold
+		}
+	}
+}
+
+// WithMaxResolutionDepth sets the maximum nesting of resolutions, 100 by default. A resolution is nested when a factory
+// function resolves a service through a Lazy dependency or a provider, which may recurse indefinitely, for example when
+// a service resolves itself through a provider. Exceeding the depth returns an error wrapping ErrMaxResolutionDepth
+// instead of overflowing the stack. A value less than or equal to 0 keeps the default depth.
+func WithMaxResolutionDepth(n int) ContainerOption {
+	return func(c *containerImpl) {
+		if n > 0 {
+			c.maxDepth = n
+		}
+	}
+}
+
+// WithConventionalDisposal makes the container dispose instances that implement neither LifecycleListener nor io.Closer,
+// but expose a Stop() error, Stop() or Shutdown(context.Context) error method, such as third-party servers and clients.
+// The method is called like EndLifecycle, its errors and panics are collected with the other disposal errors.
+func WithConventionalDisposal() ContainerOption {
+	return func(c *containerImpl) {
+		c.conventionalDisposal = true
+	}
+}
+
+// WithRemoveOnDisposeError makes the lifecycle contexts remove an instance from their cache even when its disposal fails,
+// so a cleared or reused context does not dispose it again, making disposal exactly-once. The error is still returned.
+// By default, an instance whose EndLifecycle or Close method fails remains in the cache.
+func WithRemoveOnDisposeError() ContainerOption {
+	return func(c *containerImpl) {
+		c.removeOnDisposeError = true
+	}
+}
+
+// WithConstructionTimeout bounds the time a factory function may take, so a factory blocking forever,
+// for example on a network dial without timeout, does not hang the resolution. Each factory function runs
+// in its own goroutine, and the resolution fails with an error wrapping ErrConstructionTimeout once the timeout elapses.
+// The instance eventually returned by an abandoned factory is discarded, and disposed if it is disposable.
+// A value less than or equal to 0 disables the timeout, which is the default.
+func WithConstructionTimeout(d time.Duration) ContainerOption {
+	return func(c *containerImpl) {
+		if d > 0 {
+			c.constructionTimeout = d
+		}
+	}
+}
+
+// WithNegativeCache makes the container remember the keys looked up while not registered, so repeated
+// resolutions of missing keys, such as optional services probed on hot paths, fail fast with the same error.
+// The remembered keys are forgotten whenever the registry changes, so a key registered later becomes resolvable.
+// Each distinct missing key is remembered until then, so the cache suits a bounded set of probed keys.
+func WithNegativeCache() ContainerOption {
+	return func(c *containerImpl) {
+		c.negativeCache = newNegativeCache()
+	}
+}
+
+// Tracer starts a span around the construction of the service registered with the given key.
+// It returns the context carrying the span and a function ending the span with the construction error, if any.
+type Tracer func(ctx context.Context, key string) (context.Context, func(error))
+
+// WithTracer sets the tracer called around each factory function invocation, so every constructed service
+// gets its own span. Cached Singleton and Scoped instances are not traced, as no factory function is invoked.
+// The spans of the services a factory function resolves through its injected Container, Lazy dependencies
+// and providers are started from the context returned for its own span, and ResolveCtx starts its spans
+// from the given context, so the construction spans nest within the caller's trace.
+// A nil tracer is ignored, and no tracing overhead is added when no tracer is configured.
+func WithTracer(tracer Tracer) ContainerOption {
+	return func(c *containerImpl) {
+		if tracer != nil {
+			c.tracer = tracer
+		}
+	}
+}
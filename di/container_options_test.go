@@ -0,0 +1,606 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	dilogger "github.com/lcrux/go-di/di/di-logger"
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+// recordingLogger returns a debug-level logger that records every message it emits.
+func recordingLogger() (dilogger.Logger, func() []string) {
+	var messages []string
+	var mutex sync.Mutex
+	record := func(format string, v ...interface{}) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		messages = append(messages, fmt.Sprintf(format, v...))
+	}
+	logger := dilogger.NewLogger(func(o *dilogger.LoggerOptions) {
+		o.LogLevel = dilogger.Info
+		o.Info = record
+		o.Debug = record
+		o.Warn = record
+		o.Error = record
+	})
+	return logger, func() []string {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return append([]string(nil), messages...)
+	}
+}
+
+func TestNewContainer_WithLogger(t *testing.T) {
+	logger, messages := recordingLogger()
+	c := NewContainer(WithLogger(logger))
+
+	if err := Register[*depA](c, Transient, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Resolve[*depA](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	found := false
+	for _, msg := range messages() {
+		if strings.Contains(msg, "Successfully resolved service") {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("expected resolution to be logged through the configured logger")
+	}
+}
+
+func TestNewContainer_WithLoggerAppliesToContexts(t *testing.T) {
+	logger, messages := recordingLogger()
+	c := NewContainer(WithLogger(logger))
+
+	ctx := c.NewContext()
+	if errs := ShutdownErrors(ctx.Shutdown()); len(errs) != 0 {
+		t.Fatalf("unexpected shutdown errors: %v", errs)
+	}
+
+	found := false
+	for _, msg := range messages() {
+		if strings.Contains(msg, ctx.ID()) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("expected lifecycle context to log through the configured logger")
+	}
+}
+
+func TestNewContainer_WithNilLoggerUsesDefault(t *testing.T) {
+	c := NewContainer(WithLogger(nil))
+
+	if c.(*containerImpl).logger == nil {
+		t.Fatal("expected default logger to be kept")
+	}
+}
+
+func TestNewContainer_WithMaxConcurrency(t *testing.T) {
+	c := NewContainer(WithMaxConcurrency(1))
+
+	if got := c.(*containerImpl).maxConcurrency; got != 1 {
+		t.Fatalf("expected max concurrency 1, got %d", got)
+	}
+
+	if err := Register[*depA](c, Transient, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func() *depB { return &depB{name: "b"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Transient, func(a *depA, b *depB) *depC { return &depC{a: a, b: b} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	instance, err := Resolve[*depC](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if instance.a == nil || instance.b == nil {
+		t.Fatal("expected all dependencies to be resolved sequentially")
+	}
+	if errs := ShutdownErrors(c.Shutdown()); len(errs) != 0 {
+		t.Fatalf("unexpected shutdown errors: %v", errs)
+	}
+}
+
+func TestNewContainer_WithInvalidMaxConcurrencyUsesDefault(t *testing.T) {
+	c := NewContainer(WithMaxConcurrency(-1))
+
+	if got := c.(*containerImpl).maxConcurrency; got != 0 {
+		t.Fatalf("expected default max concurrency, got %d", got)
+	}
+}
+
+func TestNewContainer_WithTracer(t *testing.T) {
+	var mutex sync.Mutex
+	var spans []string
+	tracer := func(ctx context.Context, key string) (context.Context, func(error)) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		spans = append(spans, "start "+key)
+		return ctx, func(err error) {
+			mutex.Lock()
+			defer mutex.Unlock()
+			spans = append(spans, fmt.Sprintf("end %s err=%t", key, err != nil))
+		}
+	}
+	c := NewContainer(WithTracer(tracer))
+
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func(a *depA) *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := Resolve[*depB](c, nil); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+	}
+
+	keyA, keyB := diutils.NameOf[*depA](), diutils.NameOf[*depB]()
+	expected := []string{
+		"start " + keyA, "end " + keyA + " err=false",
+		"start " + keyB, "end " + keyB + " err=false",
+		"start " + keyB, "end " + keyB + " err=false",
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if !reflect.DeepEqual(spans, expected) {
+		t.Fatalf("expected a span per constructed service %v, got %v", expected, spans)
+	}
+}
+
+func TestNewContainer_WithTracerNestsSpans(t *testing.T) {
+	type spanKey struct{}
+	var mutex sync.Mutex
+	var spans []string
+	tracer := func(ctx context.Context, key string) (context.Context, func(error)) {
+		parent, _ := ctx.Value(spanKey{}).(string)
+		mutex.Lock()
+		spans = append(spans, parent+" > "+key)
+		mutex.Unlock()
+		return context.WithValue(ctx, spanKey{}, key), func(error) {}
+	}
+	c := NewContainer(WithTracer(tracer))
+
+	if err := Register[*depA](c, Transient, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func(c Container) *depB {
+		if _, err := Resolve[*depA](c, nil); err != nil {
+			t.Errorf("unexpected resolve error: %v", err)
+		}
+		return &depB{}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Transient, func(provideA Provider[*depA], lazyB Lazy[*depB]) *depC {
+		if _, err := provideA(); err != nil {
+			t.Errorf("unexpected provider error: %v", err)
+		}
+		if _, err := lazyB.Get(); err != nil {
+			t.Errorf("unexpected lazy error: %v", err)
+		}
+		return &depC{}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	request := context.WithValue(context.Background(), spanKey{}, "request")
+	if _, err := ResolveCtx[*depC](c, request); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if _, err := Resolve[*depB](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	keyA, keyB, keyC := diutils.NameOf[*depA](), diutils.NameOf[*depB](), diutils.NameOf[*depC]()
+	expected := []string{
+		"request > " + keyC,
+		keyC + " > " + keyA,
+		keyC + " > " + keyB,
+		keyB + " > " + keyA,
+		" > " + keyB,
+		keyB + " > " + keyA,
+	}
+	mutex.Lock()
+	defer mutex.Unlock()
+	if !reflect.DeepEqual(spans, expected) {
+		t.Fatalf("expected nested spans %v, got %v", expected, spans)
+	}
+}
+
+func TestNewContainer_WithContextLeakWarning(t *testing.T) {
+	logger, messages := recordingLogger()
+	c := NewContainer(WithLogger(logger), WithContextLeakWarning(2))
+
+	countWarnings := func() int {
+		count := 0
+		for _, message := range messages() {
+			if strings.Contains(message, "exceeding the threshold of 2") {
+				count++
+			}
+		}
+		return count
+	}
+
+	first, second := c.NewContext(), c.NewContext()
+	if countWarnings() != 0 {
+		t.Fatal("expected no warning up to the threshold")
+	}
+	third := c.NewContext()
+	if countWarnings() != 1 {
+		t.Fatalf("expected a warning past the threshold, got %d", countWarnings())
+	}
+
+	active := c.ActiveContexts()
+	if len(active) != 3 {
+		t.Fatalf("expected 3 active contexts, got %v", active)
+	}
+	for _, ctx := range []LifecycleContext{first, second, third} {
+		if !strings.Contains(strings.Join(active, ","), ctx.ID()) {
+			t.Fatalf("expected context %s to be active, got %v", ctx.ID(), active)
+		}
+	}
+
+	if err := c.RemoveContext(third); err != nil {
+		t.Fatalf("unexpected remove error: %v", err)
+	}
+	if active := c.ActiveContexts(); len(active) != 2 {
+		t.Fatalf("expected 2 active contexts after removal, got %v", active)
+	}
+}
+
+func TestNewContainer_WithStrictScopes(t *testing.T) {
+	c := NewContainer(WithStrictScopes())
+
+	if err := Register[*depA](c, Scoped, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func(a *depA) *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if _, err := Resolve[*depA](c, nil); !errors.Is(err, ErrScopeRequired) {
+		t.Fatalf("expected scope required error, got: %v", err)
+	}
+	if _, err := Resolve[*depB](c, nil); !errors.Is(err, ErrScopeRequired) {
+		t.Fatalf("expected scope required error for a scoped dependency, got: %v", err)
+	}
+
+	ctx := c.NewContext()
+	defer c.RemoveContext(ctx)
+	if _, err := Resolve[*depB](c, ctx); err != nil {
+		t.Fatalf("unexpected resolve error within a lifecycle context: %v", err)
+	}
+}
+
+func TestNewContainer_LenientScopesLogWarning(t *testing.T) {
+	logger, messages := recordingLogger()
+	logger.SetLevel(dilogger.Debug)
+	c := NewContainer(WithLogger(logger))
+
+	if err := Register[*depA](c, Scoped, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Resolve[*depA](c, nil); err != nil {
+		t.Fatalf("expected the background context fallback, got: %v", err)
+	}
+
+	warned := false
+	for _, message := range messages() {
+		if strings.Contains(message, "resolved within the background context") {
+			warned = true
+		}
+	}
+	if !warned {
+		t.Fatal("expected a warning for the scoped service resolved within the background context")
+	}
+}
+
+type stopper struct {
+	stopped *int32
+	err     error
+}
+
+func (s *stopper) Stop() error {
+	atomic.AddInt32(s.stopped, 1)
+	return s.err
+}
+
+func TestNewContainer_WithConventionalDisposal(t *testing.T) {
+	stopped := int32(0)
+	c := NewContainer(WithConventionalDisposal())
+
+	if err := Register[*stopper](c, Scoped, func() *stopper { return &stopper{stopped: &stopped} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterWithKey[*stopper](c, "failing", Scoped, func() *stopper {
+		return &stopper{stopped: &stopped, err: errors.New("stop failed")}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	ctx := c.NewContext()
+	if _, err := Resolve[*stopper](c, ctx); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if _, err := ResolveWithKey[*stopper](c, "failing", ctx); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	err := c.RemoveContext(ctx)
+	if err == nil || !strings.Contains(err.Error(), "error in Stop") {
+		t.Fatalf("expected the Stop error to be collected, got: %v", err)
+	}
+	if got := atomic.LoadInt32(&stopped); got != 2 {
+		t.Fatalf("expected Stop to be called twice, got %d", got)
+	}
+}
+
+func TestNewContainer_ConventionalDisposalIsOptIn(t *testing.T) {
+	stopped := int32(0)
+	c := NewContainer()
+
+	if err := Register[*stopper](c, Scoped, func() *stopper { return &stopper{stopped: &stopped} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	ctx := c.NewContext()
+	if _, err := Resolve[*stopper](c, ctx); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if err := c.RemoveContext(ctx); err != nil {
+		t.Fatalf("unexpected remove error: %v", err)
+	}
+	if got := atomic.LoadInt32(&stopped); got != 0 {
+		t.Fatalf("expected Stop not to be called without the option, got %d", got)
+	}
+}
+
+func TestNewContainer_WithMaxResolutionDepth(t *testing.T) {
+	c := NewContainer(WithMaxResolutionDepth(5))
+
+	calls := 0
+	var depthErr error
+	if err := Register[*depA](c, Transient, func(self Provider[*depA]) *depA {
+		calls++
+		// Resolving the service from its own factory recurses until the maximum depth is exceeded
+		if _, err := self(); err != nil && depthErr == nil {
+			depthErr = err
+		}
+		return &depA{}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if _, err := Resolve[*depA](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if !errors.Is(depthErr, ErrMaxResolutionDepth) {
+		t.Fatalf("expected ErrMaxResolutionDepth, got: %v", depthErr)
+	}
+	if calls != 6 {
+		t.Fatalf("expected the factory to be called at depths 0 to 5, got %d calls", calls)
+	}
+}
+
+func TestNewContainer_MaxResolutionDepthWithLazy(t *testing.T) {
+	c := NewContainer()
+
+	var depthErr error
+	if err := Register[*lazyConsumer](c, Transient, func(self Lazy[*lazyConsumer]) *lazyConsumer {
+		if _, err := self.Get(); err != nil && depthErr == nil {
+			depthErr = err
+		}
+		return &lazyConsumer{}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if _, err := Resolve[*lazyConsumer](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if !errors.Is(depthErr, ErrMaxResolutionDepth) {
+		t.Fatalf("expected ErrMaxResolutionDepth with the default depth, got: %v", depthErr)
+	}
+}
+
+// failingCloser counts its Close calls and always fails.
+type failingCloser struct {
+	calls *int32
+}
+
+func (f *failingCloser) Close() error {
+	atomic.AddInt32(f.calls, 1)
+	return errors.New("close failed")
+}
+
+func TestNewContainer_WithRemoveOnDisposeError(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		opts          []ContainerOption
+		expectedCalls int32
+		expectedCount int
+	}{
+		{name: "Default", expectedCalls: 2, expectedCount: 1},
+		{name: "RemoveOnDisposeError", opts: []ContainerOption{WithRemoveOnDisposeError()}, expectedCalls: 1, expectedCount: 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			c := NewContainer(tc.opts...)
+			calls := int32(0)
+			if err := Register[*failingCloser](c, Scoped, func() *failingCloser { return &failingCloser{calls: &calls} }); err != nil {
+				t.Fatalf("unexpected register error: %v", err)
+			}
+			ctx := c.NewContext()
+			if _, err := Resolve[*failingCloser](c, ctx); err != nil {
+				t.Fatalf("unexpected resolve error: %v", err)
+			}
+
+			if err := ctx.Clear(); err == nil {
+				t.Fatal("expected the disposal error to be returned")
+			}
+			if ctx.Count() != tc.expectedCount {
+				t.Fatalf("expected %d cached instances after a failed disposal, got %d", tc.expectedCount, ctx.Count())
+			}
+
+			errs := ShutdownErrors(ctx.ShutdownSync())
+			if atomic.LoadInt32(&calls) != tc.expectedCalls {
+				t.Fatalf("expected %d Close calls, got %d", tc.expectedCalls, calls)
+			}
+			if len(errs) != int(tc.expectedCalls)-1 {
+				t.Fatalf("expected %d shutdown errors, got %v", tc.expectedCalls-1, errs)
+			}
+		})
+	}
+}
+
+// slowService is built by a factory outlasting the construction timeout, and counts its Close calls.
+type slowService struct {
+	closed *int32
+}
+
+func (s *slowService) Close() error {
+	atomic.AddInt32(s.closed, 1)
+	return nil
+}
+
+func TestNewContainer_WithNegativeCache(t *testing.T) {
+	c := NewContainer(WithNegativeCache())
+
+	first, err := c.Resolve(KeyOf[*depA](), nil)
+	if !errors.Is(err, ErrNotRegistered) || first != nil {
+		t.Fatalf("expected ErrNotRegistered, got %v", err)
+	}
+	_, again := c.Resolve(KeyOf[*depA](), nil)
+	if again != err {
+		t.Fatalf("expected the remembered error to be returned, got %v", again)
+	}
+
+	// Registering the probed key invalidates the cache
+	if err := Register[*depA](c, Transient, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	a, err := Resolve[*depA](c, nil)
+	if err != nil {
+		t.Fatalf("expected the key registered after being probed to resolve, got %v", err)
+	}
+	if a.name != "a" {
+		t.Fatalf("expected name a, got %q", a.name)
+	}
+
+	// So do aliases and imports
+	if _, err := c.Resolve("alias.a", nil); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected ErrNotRegistered, got %v", err)
+	}
+	if err := c.RegisterAlias("alias.a", KeyOf[*depA]()); err != nil {
+		t.Fatalf("unexpected alias error: %v", err)
+	}
+	if _, err := c.Resolve("alias.a", nil); err != nil {
+		t.Fatalf("expected the alias registered after being probed to resolve, got %v", err)
+	}
+
+	if _, err := Resolve[*depB](c, nil); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected ErrNotRegistered, got %v", err)
+	}
+	source := NewContainer()
+	if err := Register[*depB](source, Transient, func() *depB { return &depB{name: "b"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.ImportFrom(source, SkipExisting); err != nil {
+		t.Fatalf("unexpected import error: %v", err)
+	}
+	if _, err := Resolve[*depB](c, nil); err != nil {
+		t.Fatalf("expected the imported key to resolve, got %v", err)
+	}
+}
+
+func TestNewContainer_WithNegativeCacheConcurrentRegistration(t *testing.T) {
+	c := NewContainer(WithNegativeCache())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+				_, _ = c.Resolve(KeyOf[*depA](), nil)
+			}
+		}
+	}()
+	if err := Register[*depA](c, Transient, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	done <- struct{}{}
+	<-done
+
+	if _, err := Resolve[*depA](c, nil); err != nil {
+		t.Fatalf("expected the key registered during the probes to resolve, got %v", err)
+	}
+}
+
+func TestNewContainer_WithConstructionTimeout(t *testing.T) {
+	c := NewContainer(WithConstructionTimeout(20 * time.Millisecond))
+	closed := int32(0)
+	release := make(chan struct{})
+	if err := Register[*slowService](c, Singleton, func() *slowService {
+		<-release
+		return &slowService{closed: &closed}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	start := time.Now()
+	_, err := Resolve[*slowService](c, nil)
+	if !errors.Is(err, ErrConstructionTimeout) {
+		t.Fatalf("expected ErrConstructionTimeout, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("expected the resolution to fail after the timeout, took %v", elapsed)
+	}
+
+	// The abandoned factory is still blocked, a sibling resolution must not wait for it
+	resolved := make(chan error, 1)
+	go func() {
+		_, err := Resolve[*depA](c, nil)
+		resolved <- err
+	}()
+	select {
+	case err := <-resolved:
+		if err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the sibling resolution not to be blocked by the abandoned factory")
+	}
+
+	// The instance eventually returned by the abandoned factory is discarded and disposed
+	close(release)
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&closed) != 1 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if atomic.LoadInt32(&closed) != 1 {
+		t.Fatalf("expected the discarded instance to be disposed once, got %d", closed)
+	}
+}
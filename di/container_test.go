@@ -2,8 +2,18 @@ package di
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
+
+	dilogger "github.com/lcrux/go-di/di/di-logger"
+	diutils "github.com/lcrux/go-di/di/di-utils"
 )
 
 type depA struct {
@@ -122,7 +132,7 @@ func TestContainer_Shutdown_CollectsContextErrors(t *testing.T) {
 		t.Fatalf("unexpected resolve error: %v", err)
 	}
 
-	errs := c.Shutdown()
+	errs := ShutdownErrors(c.Shutdown())
 	if len(errs) != 2 {
 		t.Fatalf("expected 2 errors, got %d", len(errs))
 	}
@@ -184,7 +194,7 @@ func TestContainer_Shutdown_CanceledContextSkipsLifecycleEnd(t *testing.T) {
 	cancelCtx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	errs := c.Shutdown(cancelCtx)
+	errs := ShutdownErrors(c.Shutdown(cancelCtx))
 	if len(errs) == 0 {
 		t.Fatalf("expected at most 1 error, got %d", len(errs))
 	}
@@ -206,3 +216,1337 @@ func TestContainer_Validate_IgnoresContainerAndContextDependencies(t *testing.T)
 		t.Fatalf("expected validation to ignore container and context dependencies, got: %v", err)
 	}
 }
+
+func TestContainer_ValidateStrict_Valid(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Scoped, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Scoped, func(a *depA, b *depB, c Container) *depC { return &depC{a: a, b: b} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if err := c.ValidateStrict(); err != nil {
+		t.Fatalf("expected no validation error, got: %v", err)
+	}
+}
+
+func TestContainer_ValidateStrict_ReturnTypeMismatch(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	// Simulate a registration whose factory no longer matches its service type
+	entry, _ := c.(*containerImpl).registry.Get(diutils.NameOf[*depA]())
+	entry.factoryFn = reflect.ValueOf(func() *depB { return &depB{} })
+
+	if err := c.ValidateStrict(); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("expected type mismatch error, got: %v", err)
+	}
+}
+
+func TestContainer_ValidateStrict_MissingDependency(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depC](c, Transient, func(a *depA, b *depB) *depC { return &depC{a: a, b: b} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	err := c.ValidateStrict()
+	if !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected not registered error, got: %v", err)
+	}
+	// Both missing dependencies are reported, not just the first one
+	if !strings.Contains(err.Error(), "*di.depA") || !strings.Contains(err.Error(), "*di.depB") {
+		t.Fatalf("expected both missing dependencies to be reported, got: %v", err)
+	}
+}
+
+func TestContainer_ValidateStrict_CaptiveDependency(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Scoped, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Scoped, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Singleton, func(a *depA, b Lazy[*depB]) *depC { return &depC{a: a} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	err := c.ValidateStrict()
+	if !errors.Is(err, ErrCaptiveDependency) {
+		t.Fatalf("expected captive dependency error, got: %v", err)
+	}
+	if strings.Count(err.Error(), "depends on scoped") != 2 {
+		t.Fatalf("expected the direct and lazy captive dependencies to be reported, got: %v", err)
+	}
+	// Validate only checks that dependencies are registered
+	if err := c.Validate(); err != nil {
+		t.Fatalf("expected Validate to accept the registrations, got: %v", err)
+	}
+}
+
+func TestContainer_ValidateAll_ReportsFactoryFailures(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Singleton, func() *depA { panic("bad config") }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func() *depB { return nil }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depD](c, Transient, func() *depD { return &depD{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	errs := c.ValidateAll()
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(errs), errs)
+	}
+	joined := errors.Join(errs...).Error()
+	if !strings.Contains(joined, "bad config") || !strings.Contains(joined, "nil instance") {
+		t.Fatalf("expected the panic and the nil instance to be reported, got: %v", joined)
+	}
+}
+
+func TestContainer_ValidateAll_DisposesValidationInstances(t *testing.T) {
+	c := NewContainer()
+	scoped, transient := int32(0), int32(0)
+
+	if err := Register[*listenerDep](c, Scoped, func() *listenerDep { return &listenerDep{called: &scoped} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*listenerOk](c, Transient, func() *listenerOk { return &listenerOk{called: &transient} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if errs := c.ValidateAll(); len(errs) != 0 {
+		t.Fatalf("expected no validation error, got: %v", errs)
+	}
+	if atomic.LoadInt32(&scoped) != 1 || atomic.LoadInt32(&transient) != 1 {
+		t.Fatalf("expected the validation instances to be disposed, got scoped=%d transient=%d", scoped, transient)
+	}
+	if c.BackgroundContext().Has(diutils.NameOf[*listenerDep]()) {
+		t.Fatal("expected the scoped instance not to be cached in the background context")
+	}
+}
+
+type depWithLogger struct {
+	logger dilogger.Logger
+}
+
+func TestContainer_InjectsConfiguredLogger(t *testing.T) {
+	logger, _ := recordingLogger()
+	c := NewContainer(WithLogger(logger))
+
+	if err := Register[*depWithLogger](c, Transient, func(l dilogger.Logger) *depWithLogger {
+		return &depWithLogger{logger: l}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("expected logger dependency to be satisfied, got: %v", err)
+	}
+
+	resolved, err := Resolve[dilogger.Logger](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if resolved != logger {
+		t.Fatal("expected resolved logger to be the configured logger")
+	}
+
+	instance, err := Resolve[*depWithLogger](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if instance.logger != logger {
+		t.Fatal("expected configured logger to be injected")
+	}
+}
+
+func TestContainer_InjectsDefaultLogger(t *testing.T) {
+	c := NewContainer()
+
+	resolved, err := Resolve[dilogger.Logger](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if resolved == nil {
+		t.Fatal("expected default logger to be registered")
+	}
+}
+
+func TestContainer_SetLoggerReplacesInjectedLogger(t *testing.T) {
+	c := NewContainer()
+
+	if _, err := Resolve[dilogger.Logger](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	logger, _ := recordingLogger()
+	if err := c.SetLogger(logger); err != nil {
+		t.Fatalf("unexpected set logger error: %v", err)
+	}
+
+	resolved, err := Resolve[dilogger.Logger](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if resolved != logger {
+		t.Fatal("expected injected logger to be replaced by SetLogger")
+	}
+}
+
+type slowListener struct {
+	delay  time.Duration
+	called *int32
+}
+
+func (l *slowListener) EndLifecycle(_ ...context.Context) error {
+	atomic.AddInt32(l.called, 1)
+	time.Sleep(l.delay)
+	return nil
+}
+
+func TestContainer_Shutdown_CanceledWhileWaitingForSlot(t *testing.T) {
+	c := NewContainer(WithMaxConcurrency(1))
+	called := int32(0)
+
+	if err := Register[*slowListener](c, Scoped, func() *slowListener {
+		return &slowListener{delay: 100 * time.Millisecond, called: &called}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := Resolve[*slowListener](c, c.NewContext()); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	errs := ShutdownErrors(c.Shutdown(timeoutCtx))
+	found := false
+	for _, err := range errs {
+		if errors.Is(err, context.DeadlineExceeded) {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatalf("expected a deadline exceeded error, got %v", errs)
+	}
+	if got := atomic.LoadInt32(&called); got != 1 {
+		t.Fatalf("expected only one context to be shut down, got %d", got)
+	}
+}
+
+func TestContainer_Reset_RemovesRegistrations(t *testing.T) {
+	c := NewContainer()
+	called := int32(0)
+
+	if err := Register[*listenerDep](c, Scoped, func() *listenerDep {
+		return &listenerDep{called: &called}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	ctx := c.NewContext()
+	if _, err := Resolve[*listenerDep](c, ctx); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	bg := c.BackgroundContext()
+
+	if errs := ShutdownErrors(c.Reset()); len(errs) != 0 {
+		t.Fatalf("unexpected reset errors: %v", errs)
+	}
+
+	if called != 1 {
+		t.Fatalf("expected EndLifecycle to be called on reset, got %d", called)
+	}
+	if !ctx.IsClosed() {
+		t.Fatal("expected lifecycle context to be closed after reset")
+	}
+	if c.BackgroundContext().ID() == bg.ID() {
+		t.Fatal("expected background context to be reset")
+	}
+	if _, err := Resolve[*listenerDep](c, nil); err == nil {
+		t.Fatal("expected registrations to be removed after reset")
+	}
+	if err := Register[*listenerDep](c, Scoped, func() *listenerDep {
+		return &listenerDep{}
+	}); err != nil {
+		t.Fatalf("expected service to be registrable again after reset, got: %v", err)
+	}
+}
+
+func TestContainer_Reset_KeepsConfiguredLogger(t *testing.T) {
+	logger, _ := recordingLogger()
+	c := NewContainer(WithLogger(logger))
+
+	_ = c.Reset()
+
+	resolved, err := Resolve[dilogger.Logger](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if resolved != logger {
+		t.Fatal("expected configured logger to be retained after reset")
+	}
+}
+
+func TestContainer_Shutdown_ConcurrentCallsDisposeOnce(t *testing.T) {
+	c := NewContainer()
+	called := int32(0)
+
+	if err := Register[*listenerDep](c, Scoped, func() *listenerDep {
+		return &listenerDep{called: &called}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := Resolve[*listenerDep](c, c.NewContext()); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if errs := ShutdownErrors(c.Shutdown()); len(errs) != 0 {
+				t.Errorf("unexpected shutdown errors: %v", errs)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&called); got != 3 {
+		t.Fatalf("expected each context to be disposed once, got %d", got)
+	}
+}
+
+func TestContainer_Shutdown_RepeatedCallIsNoop(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*listenerErr](c, Scoped, func() *listenerErr {
+		return &listenerErr{}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Resolve[*listenerErr](c, c.NewContext()); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	errs := ShutdownErrors(c.Shutdown())
+	bg := c.BackgroundContext()
+
+	if again := ShutdownErrors(c.Shutdown()); len(again) != len(errs) || len(again) != 1 {
+		t.Fatalf("expected repeated shutdown to return the same errors, got %v and %v", errs, again)
+	}
+	if c.BackgroundContext().ID() != bg.ID() {
+		t.Fatal("expected repeated shutdown not to reset the background context")
+	}
+
+	// Using the container again makes the next shutdown effective
+	ctx := c.NewContext()
+	if errs := ShutdownErrors(c.Shutdown()); len(errs) != 0 {
+		t.Fatalf("unexpected shutdown errors: %v", errs)
+	}
+	if !ctx.IsClosed() {
+		t.Fatal("expected shutdown to close contexts created after the previous shutdown")
+	}
+}
+
+func TestContainer_OnShutdown(t *testing.T) {
+	c := NewContainer()
+	called := int32(0)
+	if err := Register[*listenerOk](c, Singleton, func() *listenerOk { return &listenerOk{called: &called} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Resolve[*listenerOk](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	type shutdownKey struct{}
+	ctx := context.WithValue(context.Background(), shutdownKey{}, "shutdown")
+	var order []string
+	errFlush := errors.New("flush failed")
+	c.OnShutdown(func(hookCtx context.Context) error {
+		if hookCtx.Value(shutdownKey{}) != "shutdown" {
+			t.Error("expected the hook to run with the shutdown context")
+		}
+		order = append(order, "first")
+		return nil
+	})
+	c.OnShutdown(func(context.Context) error {
+		order = append(order, "second")
+		return errFlush
+	})
+	c.OnShutdown(func(context.Context) error {
+		if atomic.LoadInt32(&called) != 1 {
+			t.Error("expected the hooks to run once the lifecycle contexts are disposed")
+		}
+		order = append(order, "third")
+		panic("boom")
+	})
+	c.OnShutdown(nil)
+
+	err := c.Shutdown(ctx)
+	if strings.Join(order, ",") != "third,second,first" {
+		t.Fatalf("expected the hooks to run in LIFO order, got %v", order)
+	}
+	if !errors.Is(err, errFlush) {
+		t.Fatalf("expected the hook error to be returned, got %v", err)
+	}
+	if errs := ShutdownErrors(err); len(errs) != 2 || !strings.Contains(errs[0].Error(), "panicked: boom") {
+		t.Fatalf("expected the hook error and panic to be collected, got %v", errs)
+	}
+
+	// Each hook runs once, and a hook registered afterwards makes the next shutdown effective
+	order = nil
+	c.OnShutdown(func(context.Context) error {
+		order = append(order, "later")
+		return nil
+	})
+	if err := c.Shutdown(); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if len(order) != 1 || order[0] != "later" {
+		t.Fatalf("expected only the hook registered afterwards to run, got %v", order)
+	}
+}
+
+func TestContainer_ShutdownFinal(t *testing.T) {
+	c := NewContainer()
+	called := int32(0)
+	if err := Register[*listenerOk](c, Singleton, func() *listenerOk { return &listenerOk{called: &called} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Resolve[*listenerOk](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	bgCtx := c.BackgroundContext()
+
+	if err := c.ShutdownFinal(); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if atomic.LoadInt32(&called) != 1 {
+		t.Fatalf("expected the singleton to be disposed once, got %d", called)
+	}
+	if c.BackgroundContext() != bgCtx || !bgCtx.IsClosed() {
+		t.Fatal("expected the closed background context to be kept")
+	}
+
+	if _, err := Resolve[*listenerOk](c, nil); !errors.Is(err, ErrContainerClosed) {
+		t.Fatalf("expected resolution to fail with ErrContainerClosed, got %v", err)
+	}
+	if _, err := Resolve[*listenerOk](c, c.NewContext()); !errors.Is(err, ErrContainerClosed) {
+		t.Fatalf("expected resolution within a new context to fail with ErrContainerClosed, got %v", err)
+	}
+	if err := Register[*depA](c, Transient, func() *depA { return &depA{} }); !errors.Is(err, ErrContainerClosed) {
+		t.Fatalf("expected registration to fail with ErrContainerClosed, got %v", err)
+	}
+
+	// Shutdown and ShutdownFinal are no-ops once the container is closed
+	if err := c.Shutdown(); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if err := c.ShutdownFinal(); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if atomic.LoadInt32(&called) != 1 {
+		t.Fatalf("expected the singleton to be disposed once, got %d", called)
+	}
+	if _, err := Resolve[*listenerOk](c, nil); !errors.Is(err, ErrContainerClosed) {
+		t.Fatalf("expected the container to stay closed, got %v", err)
+	}
+}
+
+func TestContainer_ShutdownFinal_AfterShutdown(t *testing.T) {
+	c := NewContainer()
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.Shutdown(); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if _, err := Resolve[*depA](c, nil); err != nil {
+		t.Fatalf("expected the container to be usable after Shutdown, got %v", err)
+	}
+
+	if err := c.ShutdownFinal(); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if !c.BackgroundContext().IsClosed() {
+		t.Fatal("expected the background context to be shut down")
+	}
+	if _, err := Resolve[*depA](c, nil); !errors.Is(err, ErrContainerClosed) {
+		t.Fatalf("expected resolution to fail with ErrContainerClosed, got %v", err)
+	}
+}
+
+func TestContainer_Shutdown_WaitsForInFlightResolutions(t *testing.T) {
+	c := NewContainer()
+	called := int32(0)
+	started, release := make(chan struct{}), make(chan struct{})
+
+	if err := Register[*listenerOk](c, Scoped, func() *listenerOk {
+		close(started)
+		<-release
+		return &listenerOk{called: &called}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depA](c, Transient, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	ctx := c.NewContext()
+	resolved := make(chan error, 1)
+	go func() {
+		_, err := Resolve[*listenerOk](c, ctx)
+		resolved <- err
+	}()
+	<-started
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- c.Shutdown()
+	}()
+
+	// New resolutions are rejected once the shutdown begins
+	deadline := time.Now().Add(time.Second)
+	var err error
+	for time.Now().Before(deadline) {
+		if _, err = Resolve[*depA](c, nil); errors.Is(err, ErrShuttingDown) {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if !errors.Is(err, ErrShuttingDown) {
+		t.Fatalf("expected ErrShuttingDown while the container drains, got %v", err)
+	}
+	select {
+	case err := <-shutdown:
+		t.Fatalf("expected the shutdown to wait for the in-flight resolution, got %v", err)
+	default:
+	}
+
+	close(release)
+	if err := <-resolved; err != nil {
+		t.Fatalf("expected the in-flight resolution to complete, got %v", err)
+	}
+	if err := <-shutdown; err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if atomic.LoadInt32(&called) != 1 {
+		t.Fatalf("expected the instance of the in-flight resolution to be disposed, got %d calls", called)
+	}
+
+	// The container resolves services again once the shutdown completes
+	if _, err := Resolve[*depA](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error after shutdown: %v", err)
+	}
+}
+
+func TestContainer_Shutdown_ConcurrentResolutions(t *testing.T) {
+	c := NewContainer()
+	called := int32(0)
+
+	if err := Register[*listenerOk](c, Singleton, func() *listenerOk { return &listenerOk{called: &called} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depA](c, Scoped, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if _, err := Resolve[*listenerOk](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	stop := make(chan struct{})
+	errs := make(chan error, 8)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if _, err := Resolve[*listenerOk](c, nil); err != nil && !errors.Is(err, ErrShuttingDown) {
+					errs <- err
+					return
+				}
+				if _, err := Resolve[*depA](c, nil); err != nil && !errors.Is(err, ErrShuttingDown) {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		if err := c.Shutdown(); err != nil {
+			t.Fatalf("unexpected shutdown error: %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("expected resolutions to succeed or be rejected, got %v", err)
+	}
+	if err := c.Shutdown(); err != nil {
+		t.Fatalf("unexpected shutdown error: %v", err)
+	}
+	if calls := atomic.LoadInt32(&called); calls == 0 {
+		t.Fatal("expected the singletons to be disposed")
+	}
+}
+
+func TestContainer_Freeze_RejectsRegistrations(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	c.Freeze()
+
+	if err := Register[*depB](c, Transient, func() *depB { return &depB{} }); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("expected frozen error, got: %v", err)
+	}
+	if err := RegisterWithKey[*depB](c, "depB", Transient, func() *depB { return &depB{} }); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("expected frozen error, got: %v", err)
+	}
+	if err := c.RegisterAlias("alias", diutils.NameOf[*depA]()); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("expected frozen error, got: %v", err)
+	}
+
+	if _, err := Resolve[*depA](c, nil); err != nil {
+		t.Fatalf("expected resolution to work on a frozen container, got: %v", err)
+	}
+
+	_ = c.Reset()
+	if err := Register[*depB](c, Transient, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("expected reset to unfreeze the container, got: %v", err)
+	}
+}
+
+func TestContainer_Build(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depC](c, Transient, func(a *depA, b *depB) *depC { return &depC{a: a, b: b} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.Build(); err == nil {
+		t.Fatal("expected build to fail validation")
+	}
+
+	// A failed build does not freeze the container
+	if err := Register[*depA](c, Transient, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.Build(); err != nil {
+		t.Fatalf("unexpected build error: %v", err)
+	}
+	if err := Register[*depD](c, Transient, func(c *depC) *depD { return &depD{c: c} }); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("expected frozen error, got: %v", err)
+	}
+	if _, err := Resolve[*depC](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+}
+
+func TestContainer_ScopeOf(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Scoped, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if scope, ok := c.ScopeOf(diutils.NameOf[*depA]()); !ok || scope != Scoped {
+		t.Fatalf("expected Scoped for a registered service, got %v (registered: %t)", scope, ok)
+	}
+	if _, ok := c.ScopeOf(diutils.NameOf[*depB]()); ok {
+		t.Fatal("expected an unregistered service to be reported as missing")
+	}
+}
+
+func TestContainer_Clone(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.RegisterAlias("alias-a", diutils.NameOf[*depA]()); err != nil {
+		t.Fatalf("unexpected alias error: %v", err)
+	}
+	original, err := Resolve[*depA](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	clone := c.Clone()
+	cloned, err := Resolve[*depA](clone, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if cloned == original {
+		t.Fatal("expected the clone to create its own singleton")
+	}
+	viaAlias, err := ResolveWithKey[*depA](clone, "alias-a", nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if viaAlias != cloned {
+		t.Fatal("expected the cloned alias to resolve the cloned singleton")
+	}
+
+	// Registrations made on the clone do not leak into the original container
+	if err := Register[*depB](clone, Transient, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Resolve[*depB](c, nil); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected the clone registration not to affect the original, got: %v", err)
+	}
+}
+
+func TestContainer_InvalidateSingleton(t *testing.T) {
+	c := NewContainer()
+	called := int32(0)
+
+	if err := Register[*listenerDep](c, Singleton, func() *listenerDep { return &listenerDep{called: &called} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	first, err := Resolve[*listenerDep](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	if err := c.InvalidateSingleton(diutils.NameOf[*listenerDep]()); err != nil {
+		t.Fatalf("unexpected invalidate error: %v", err)
+	}
+	if atomic.LoadInt32(&called) != 1 {
+		t.Fatalf("expected the invalidated singleton to be disposed, got %d", called)
+	}
+
+	second, err := Resolve[*listenerDep](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if first == second {
+		t.Fatal("expected a new singleton after invalidation")
+	}
+}
+
+func TestContainer_InvalidateSingleton_Errors(t *testing.T) {
+	c := NewContainer()
+
+	if err := c.InvalidateSingleton(diutils.NameOf[*depA]()); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected not registered error, got: %v", err)
+	}
+	if err := Register[*depA](c, Transient, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.InvalidateSingleton(diutils.NameOf[*depA]()); err == nil {
+		t.Fatal("expected error when invalidating a transient service")
+	}
+}
+
+func TestContainer_CountAndInstanceCounts(t *testing.T) {
+	c := NewContainer()
+	if c.Count() != 0 {
+		t.Fatalf("expected no registrations, got %d", c.Count())
+	}
+
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Scoped, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Scoped, func(a *depA, b *depB) *depC { return &depC{a: a, b: b} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.RegisterAlias("alias", KeyOf[*depA]()); err != nil {
+		t.Fatalf("unexpected alias error: %v", err)
+	}
+	if c.Count() != 3 {
+		t.Fatalf("expected 3 registrations, got %d", c.Count())
+	}
+
+	first := c.NewContext()
+	second := c.NewContext()
+	if _, err := Resolve[*depC](c, first); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if _, err := Resolve[*depB](c, second); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	counts := c.InstanceCounts()
+	if len(counts) != 3 {
+		t.Fatalf("expected counts for 3 contexts, got %v", counts)
+	}
+	if counts[first.ID()] != 2 || counts[second.ID()] != 1 || counts[c.BackgroundContext().ID()] != 1 {
+		t.Fatalf("unexpected instance counts: %v", counts)
+	}
+
+	if err := c.RemoveContext(first); err != nil {
+		t.Fatalf("unexpected remove error: %v", err)
+	}
+	if _, ok := c.InstanceCounts()[first.ID()]; ok {
+		t.Fatal("expected the removed context not to be counted")
+	}
+}
+
+func TestContainer_ForEachRegistration(t *testing.T) {
+	c := NewContainer()
+	if err := RegisterWithKey[*depA](c, "a", Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterWithKey[*depB](c, "b", Scoped, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterWithKey[*depC](c, "c", Transient, func() *depC { return &depC{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	var visited []string
+	c.ForEachRegistration(func(key string, serviceType reflect.Type, scope LifecycleScope) bool {
+		visited = append(visited, fmt.Sprintf("%s %s %s", key, serviceType, scope))
+		// Calling back into the container does not deadlock
+		if _, ok := c.ScopeOf(key); !ok {
+			t.Errorf("expected %s to be registered", key)
+		}
+		return true
+	})
+	expected := []string{"a *di.depA Singleton", "b *di.depB Scoped", "c *di.depC Transient"}
+	if !reflect.DeepEqual(visited, expected) {
+		t.Fatalf("expected %v, got %v", expected, visited)
+	}
+
+	count := 0
+	c.ForEachRegistration(func(string, reflect.Type, LifecycleScope) bool {
+		count++
+		return false
+	})
+	if count != 1 {
+		t.Fatalf("expected the iteration to stop after the first registration, got %d", count)
+	}
+}
+
+type flushingListener struct {
+	ended    *int32
+	messages *[]string
+}
+
+func (l *flushingListener) EndLifecycle(_ ...context.Context) error {
+	atomic.AddInt32(l.ended, 1)
+	return nil
+}
+
+func (l *flushingListener) EndLifecycleWithContainer(c Container, _ context.Context) error {
+	logger, err := Resolve[dilogger.Logger](c, nil)
+	if err != nil {
+		return err
+	}
+	logger.Infof("flushing")
+	*l.messages = append(*l.messages, "flushed")
+	return nil
+}
+
+func TestContainer_ContainerAwareListenerResolvesDuringDisposal(t *testing.T) {
+	logger, messages := recordingLogger()
+	c := NewContainer(WithLogger(logger))
+
+	ended := int32(0)
+	var flushed []string
+	if err := Register[*flushingListener](c, Scoped, func() *flushingListener {
+		return &flushingListener{ended: &ended, messages: &flushed}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	ctx := c.NewContext()
+	if _, err := Resolve[*flushingListener](c, ctx); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if err := c.RemoveContext(ctx); err != nil {
+		t.Fatalf("unexpected remove error: %v", err)
+	}
+
+	if len(flushed) != 1 {
+		t.Fatalf("expected the listener to be disposed with the container once, got %d", len(flushed))
+	}
+	if atomic.LoadInt32(&ended) != 0 {
+		t.Fatal("expected EndLifecycleWithContainer to be preferred over EndLifecycle")
+	}
+	logged := false
+	for _, message := range messages() {
+		if strings.Contains(message, "flushing") {
+			logged = true
+		}
+	}
+	if !logged {
+		t.Fatal("expected the listener to log through the resolved logger")
+	}
+	if _, err := Resolve[dilogger.Logger](c, nil); err != nil {
+		t.Fatalf("expected the container to remain usable after the disposal, got %v", err)
+	}
+}
+
+func TestContainer_RemoveContextErrors(t *testing.T) {
+	c := NewContainer()
+	if err := RegisterWithKey[*listenerErr](c, "first", Scoped, func() *listenerErr { return &listenerErr{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterWithKey[*listenerPanic](c, "second", Scoped, func() *listenerPanic { return &listenerPanic{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterWithKey[*depA](c, "third", Scoped, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	ctx := c.NewContext()
+	for _, key := range []string{"first", "second", "third"} {
+		if _, err := c.Resolve(key, ctx); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+	}
+
+	errs := c.RemoveContextErrors(ctx)
+	if len(errs) != 2 {
+		t.Fatalf("expected 2 errors, got %d: %v", len(errs), errs)
+	}
+	messages := errs[0].Error() + errs[1].Error()
+	if !strings.Contains(messages, "first") || !strings.Contains(messages, "second") {
+		t.Fatalf("expected an error for each failing instance, got %v", errs)
+	}
+	for _, err := range errs {
+		var shutdownErr *ShutdownError
+		if errors.As(err, &shutdownErr) {
+			t.Fatalf("expected the raw instance errors, got %v", err)
+		}
+	}
+	if !ctx.IsClosed() {
+		t.Fatal("expected the context to be closed")
+	}
+
+	if errs := c.RemoveContextErrors(ctx); errs == nil || len(errs) != 0 {
+		t.Fatalf("expected an empty slice for a closed context, got %v", errs)
+	}
+	if errs := c.RemoveContextErrors(NewLifecycleContext()); errs == nil || len(errs) != 0 {
+		t.Fatalf("expected an empty slice for an unknown context, got %v", errs)
+	}
+	if errs := c.RemoveContextErrors(c.NewContext()); errs == nil || len(errs) != 0 {
+		t.Fatalf("expected an empty slice without disposal errors, got %v", errs)
+	}
+}
+
+func TestContainer_DisposeSingletons(t *testing.T) {
+	c := NewContainer()
+	called := int32(0)
+	if err := Register[*listenerOk](c, Singleton, func() *listenerOk { return &listenerOk{called: &called} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Scoped, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	singleton, err := Resolve[*listenerOk](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	ctx := c.NewContext()
+	scoped, err := Resolve[*depB](c, ctx)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	if errs := c.DisposeSingletons(); len(errs) != 0 {
+		t.Fatalf("unexpected dispose errors: %v", errs)
+	}
+	if atomic.LoadInt32(&called) != 1 {
+		t.Fatalf("expected EndLifecycle to be called once, got %d", called)
+	}
+
+	if ctx.IsClosed() {
+		t.Fatal("expected the scoped context to survive")
+	}
+	if again, err := Resolve[*depB](c, ctx); err != nil || again != scoped {
+		t.Fatalf("expected the scoped instance to be kept, got %v, %v", again, err)
+	}
+	if recreated, err := Resolve[*listenerOk](c, nil); err != nil || recreated == singleton {
+		t.Fatalf("expected a new singleton instance, got %v, %v", recreated, err)
+	}
+	if _, ok := c.ScopeOf(KeyOf[*listenerOk]()); !ok {
+		t.Fatal("expected the registrations to be kept")
+	}
+}
+
+func TestContainer_SetBackgroundContext_SeedsSingletons(t *testing.T) {
+	c := NewContainer()
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "real"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Singleton, func(a *depA) *depC { return &depC{a: a} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	fake := &depA{name: "fake"}
+	bg := NewLifecycleContext()
+	if err := bg.SetInstance(KeyOf[*depA](), reflect.ValueOf(fake)); err != nil {
+		t.Fatalf("unexpected set error: %v", err)
+	}
+	if err := c.SetBackgroundContext(bg, false); err != nil {
+		t.Fatalf("unexpected set background context error: %v", err)
+	}
+
+	if c.BackgroundContext() != bg {
+		t.Fatal("expected the background context to be replaced")
+	}
+	instance, err := Resolve[*depC](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if instance.a != fake {
+		t.Fatalf("expected the seeded singleton to be injected, got %v", instance.a)
+	}
+	if !bg.Has(KeyOf[*depC]()) {
+		t.Fatal("expected the singletons created afterwards to be stored in the new background context")
+	}
+}
+
+func TestContainer_SetBackgroundContext_PreviousInstances(t *testing.T) {
+	c := NewContainer()
+	called := int32(0)
+	if err := Register[*listenerOk](c, Singleton, func() *listenerOk { return &listenerOk{called: &called} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	previous := c.BackgroundContext()
+	if _, err := Resolve[*listenerOk](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	if err := c.SetBackgroundContext(NewLifecycleContext(), false); err == nil {
+		t.Fatal("expected an error when the previous background context holds instances")
+	}
+	if c.BackgroundContext() != previous || previous.IsClosed() {
+		t.Fatal("expected the background context to be kept")
+	}
+
+	if err := c.SetBackgroundContext(NewLifecycleContext(), true); err != nil {
+		t.Fatalf("unexpected set background context error: %v", err)
+	}
+	if !previous.IsClosed() || atomic.LoadInt32(&called) != 1 {
+		t.Fatalf("expected the previous background context to be disposed, got %d calls", called)
+	}
+
+	if err := c.SetBackgroundContext(nil, true); err == nil {
+		t.Fatal("expected an error for a nil context")
+	}
+	if err := c.SetBackgroundContext(c.NewContext(), true); err == nil {
+		t.Fatal("expected an error for a context tracked by the container")
+	}
+	if err := c.SetBackgroundContext(previous, true); err == nil {
+		t.Fatal("expected an error for a closed context")
+	}
+}
+
+func TestContainer_SetBackgroundContext_ConcurrentResolutions(t *testing.T) {
+	c := NewContainer()
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	stop := make(chan struct{})
+	errs := make(chan error, 8)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				if _, err := Resolve[*depA](c, nil); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		if err := c.SetBackgroundContext(NewLifecycleContext(), true); err != nil {
+			t.Fatalf("unexpected set background context error: %v", err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("expected the resolutions to succeed while the background context is replaced, got %v", err)
+	}
+}
+
+func TestContainer_NewNamedContext(t *testing.T) {
+	logger, messages := recordingLogger()
+	c := NewContainer(WithLogger(logger))
+	if err := Register[*depA](c, Scoped, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	ctx := c.NewNamedContext("request")
+	unnamed := c.NewContext()
+	if ctx.Name() != "request" || unnamed.Name() != "" {
+		t.Fatalf("unexpected context names %q and %q", ctx.Name(), unnamed.Name())
+	}
+	if _, err := Resolve[*depA](c, ctx); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	label := fmt.Sprintf("[Context request (%s)]", ctx.ID())
+	found := false
+	for _, message := range messages() {
+		if strings.Contains(message, label) {
+			found = true
+			break
+		}
+	}
+	if !found {
+		t.Fatalf("expected the log output to contain %q, got %v", label, messages())
+	}
+
+	active := c.ActiveContexts()
+	expected := []string{fmt.Sprintf("request (%s)", ctx.ID()), unnamed.ID()}
+	sort.Strings(expected)
+	if !reflect.DeepEqual(active, expected) {
+		t.Fatalf("expected active contexts %v, got %v", expected, active)
+	}
+}
+
+func TestContainer_NewContextWithID(t *testing.T) {
+	c := NewContainer()
+	if err := Register[*depA](c, Scoped, func() *depA { return &depA{name: "scoped"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	ctx, err := c.NewContextWithID("request-42")
+	if err != nil {
+		t.Fatalf("unexpected context error: %v", err)
+	}
+	if ctx.ID() != "request-42" {
+		t.Fatalf("expected ID request-42, got %s", ctx.ID())
+	}
+	a, err := Resolve[*depA](c, ctx)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if !ctx.Has(KeyOf[*depA]()) {
+		t.Fatal("expected the scoped instance to be stored in the context")
+	}
+	if again, _ := Resolve[*depA](c, ctx); again != a {
+		t.Fatal("expected the scoped instance to be shared within the context")
+	}
+	if active := c.ActiveContexts(); len(active) != 1 || active[0] != "request-42" {
+		t.Fatalf("expected the context to be active, got %v", active)
+	}
+
+	if err := c.RemoveContext(ctx); err != nil {
+		t.Fatalf("unexpected remove error: %v", err)
+	}
+	if _, err := c.NewContextWithID("request-42"); err != nil {
+		t.Fatalf("expected the ID of a removed context to be reusable, got %v", err)
+	}
+}
+
+func TestContainer_NewContextWithID_RejectsInvalidIDs(t *testing.T) {
+	c := NewContainer()
+	if _, err := c.NewContextWithID("request-42"); err != nil {
+		t.Fatalf("unexpected context error: %v", err)
+	}
+
+	for _, id := range []string{"request-42", "", " ", backgroundContextKey, c.BackgroundContext().ID()} {
+		if ctx, err := c.NewContextWithID(id); err == nil || ctx != nil {
+			t.Fatalf("expected an error for ID %q, got %v", id, err)
+		}
+	}
+	if active := c.ActiveContexts(); len(active) != 1 {
+		t.Fatalf("expected the rejected contexts not to be tracked, got %v", active)
+	}
+}
+
+func TestContainer_StartContextReaper(t *testing.T) {
+	c := NewContainer()
+	called := int32(0)
+	if err := Register[*listenerOk](c, Scoped, func() *listenerOk { return &listenerOk{called: &called} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	abandoned := c.NewContext()
+	if _, err := Resolve[*listenerOk](c, abandoned); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	stop := c.StartContextReaper(20*time.Millisecond, 5*time.Millisecond)
+	deadline := time.Now().Add(time.Second)
+	for !abandoned.IsClosed() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	stop()
+	stop()
+
+	if !abandoned.IsClosed() {
+		t.Fatal("expected the abandoned context to be reaped")
+	}
+	if atomic.LoadInt32(&called) != 1 {
+		t.Fatalf("expected EndLifecycle to be called once, got %d", called)
+	}
+	if active := c.ActiveContexts(); len(active) != 0 {
+		t.Fatalf("expected no active context, got %v", active)
+	}
+	if c.BackgroundContext().IsClosed() {
+		t.Fatal("expected the background context not to be reaped")
+	}
+
+	// Contexts are no longer reaped once the reaper is stopped
+	ctx := c.NewContext()
+	time.Sleep(40 * time.Millisecond)
+	if ctx.IsClosed() {
+		t.Fatal("expected the context to survive after the reaper is stopped")
+	}
+	if err := c.RemoveContext(ctx); err != nil {
+		t.Fatalf("unexpected remove error: %v", err)
+	}
+}
+
+func TestContainer_StartContextReaper_KeepsActiveContexts(t *testing.T) {
+	c := NewContainer()
+	if err := Register[*depA](c, Scoped, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	active, idle := c.NewContext(), c.NewContext()
+	stop := c.StartContextReaper(30*time.Millisecond, 5*time.Millisecond)
+	defer stop()
+
+	// The active context outlives maxIdle, but is accessed more often than that
+	deadline := time.Now().Add(time.Second)
+	for !idle.IsClosed() && time.Now().Before(deadline) {
+		if _, err := Resolve[*depA](c, active); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if !idle.IsClosed() {
+		t.Fatal("expected the idle context to be reaped")
+	}
+	if active.IsClosed() {
+		t.Fatal("expected the active context not to be reaped")
+	}
+	if err := c.RemoveContext(active); err != nil {
+		t.Fatalf("unexpected remove error: %v", err)
+	}
+}
+
+func TestContainer_Shutdown_ConcurrentReaper(t *testing.T) {
+	for run := 0; run < 50; run++ {
+		c := NewContainer()
+		for i := 0; i < 200; i++ {
+			c.NewContext()
+		}
+
+		stop := c.StartContextReaper(time.Nanosecond, time.Microsecond)
+		if err := c.Shutdown(); err != nil {
+			t.Fatalf("unexpected shutdown error: %v", err)
+		}
+		stop()
+	}
+}
+
+func TestContainer_Shutdown_ConcurrentRemoveContext(t *testing.T) {
+	for run := 0; run < 50; run++ {
+		c := NewContainer()
+		contexts := make([]LifecycleContext, 200)
+		for i := range contexts {
+			contexts[i] = c.NewContext()
+		}
+
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, ctx := range contexts {
+				_ = c.RemoveContext(ctx)
+			}
+		}()
+		if err := c.Shutdown(); err != nil {
+			t.Fatalf("unexpected shutdown error: %v", err)
+		}
+		wg.Wait()
+	}
+}
+
+func TestContainer_StartContextReaper_ConcurrentRemove(t *testing.T) {
+	c := NewContainer()
+	called := int32(0)
+	if err := Register[*listenerOk](c, Scoped, func() *listenerOk { return &listenerOk{called: &called} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	contexts := make([]LifecycleContext, 50)
+	for i := range contexts {
+		contexts[i] = c.NewContext()
+		if _, err := Resolve[*listenerOk](c, contexts[i]); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	stop := c.StartContextReaper(time.Millisecond, time.Millisecond)
+	defer stop()
+
+	var wg sync.WaitGroup
+	for _, ctx := range contexts {
+		wg.Add(1)
+		go func(ctx LifecycleContext) {
+			defer wg.Done()
+			_ = c.RemoveContext(ctx)
+			_ = c.NewContext()
+		}(ctx)
+	}
+	wg.Wait()
+
+	deadline := time.Now().Add(time.Second)
+	for atomic.LoadInt32(&called) < int32(len(contexts)) && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	if got := atomic.LoadInt32(&called); got != int32(len(contexts)) {
+		t.Fatalf("expected each context to be disposed once, got %d disposals for %d contexts", got, len(contexts))
+	}
+}
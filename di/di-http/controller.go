@@ -0,0 +1,33 @@
+package dihttp
+
+import (
+	"fmt"
+
+	"github.com/lcrux/go-di/di"
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+// Controller is implemented by controllers registering their routes with a router of type R,
+// wrapping their handlers with a middleware of type M.
+type Controller[R, M any] interface {
+	RegisterRoutes(router R, middleware M) error
+}
+
+// MountController resolves the controller of type T from the container and registers its routes with the router.
+// If the context is nil, it uses the container's background context.
+//
+// The router and middleware types are inferred from the controller's RegisterRoutes method, so only T is needed:
+//
+//	err := dihttp.MountController[controllers.TodoController](container, apiRouter, nil, nil)
+//
+// It returns an error if the controller cannot be resolved or if its routes cannot be registered.
+func MountController[T Controller[R, M], R, M any](c di.Container, router R, ctx di.LifecycleContext, middleware M) error {
+	controller, err := di.Resolve[T](c, ctx)
+	if err != nil {
+		return fmt.Errorf("failed to resolve controller %v: %w", diutils.TypeOf[T](), err)
+	}
+	if err := controller.RegisterRoutes(router, middleware); err != nil {
+		return fmt.Errorf("failed to register routes of controller %v: %w", diutils.TypeOf[T](), err)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package dihttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lcrux/go-di/di"
+)
+
+type greeter struct {
+	greeting string
+}
+
+type greetingController struct {
+	greeter *greeter
+}
+
+func (c *greetingController) RegisterRoutes(mux *http.ServeMux, middleware func(http.Handler) http.Handler) error {
+	mux.Handle("GET /greeting", middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(c.greeter.greeting))
+	})))
+	return nil
+}
+
+func TestMountController_RegistersResolvedController(t *testing.T) {
+	c := di.NewContainer()
+	defer c.Shutdown()
+	if err := di.Register[*greeter](c, di.Singleton, func() *greeter { return &greeter{greeting: "hello"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := di.Register[*greetingController](c, di.Transient, func(g *greeter) *greetingController {
+		return &greetingController{greeter: g}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	mux := http.NewServeMux()
+	wrapped := false
+	middleware := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			wrapped = true
+			next.ServeHTTP(w, r)
+		})
+	}
+	if err := MountController[*greetingController](c, mux, nil, middleware); err != nil {
+		t.Fatalf("unexpected mount error: %v", err)
+	}
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/greeting", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "hello" {
+		t.Fatalf("expected 200 hello, got %d %q", rec.Code, rec.Body.String())
+	}
+	if !wrapped {
+		t.Fatal("expected the handler to be wrapped with the middleware")
+	}
+}
+
+func TestMountController_ReturnsResolutionError(t *testing.T) {
+	c := di.NewContainer()
+	defer c.Shutdown()
+	if err := di.Register[*greetingController](c, di.Transient, func(g *greeter) *greetingController {
+		return &greetingController{greeter: g}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	var middleware func(http.Handler) http.Handler
+	err := MountController[*greetingController](c, http.NewServeMux(), nil, middleware)
+	if !errors.Is(err, di.ErrNotRegistered) {
+		t.Fatalf("expected ErrNotRegistered, got %v", err)
+	}
+}
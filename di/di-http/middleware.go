@@ -0,0 +1,42 @@
+package dihttp
+
+import (
+	"net/http"
+
+	"github.com/lcrux/go-di/di"
+	dilogger "github.com/lcrux/go-di/di/di-logger"
+)
+
+// ScopeMiddleware returns a middleware creating a lifecycle context for each request.
+//
+// The lifecycle context is attached to the request context with di.AttachScope, so handlers can retrieve it
+// with ContextFrom, or di.ScopeFromContext down the call chain, and resolve Scoped services within the request.
+// Once the handler returns, or panics, the lifecycle context is removed from the container and its instances
+// are disposed.
+func ScopeMiddleware(c di.Container) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			lctx := c.NewContext()
+			defer removeContext(c, lctx)
+
+			next.ServeHTTP(w, r.WithContext(di.AttachScope(r.Context(), lctx)))
+		})
+	}
+}
+
+// ContextFrom returns the lifecycle context created by ScopeMiddleware for the given request.
+// It returns nil if the middleware was not installed.
+func ContextFrom(r *http.Request) di.LifecycleContext {
+	lctx, _ := di.ScopeFromContext(r.Context())
+	return lctx
+}
+
+// removeContext removes the given request-scoped lifecycle context from the container,
+// logging the disposal errors with the container's logger.
+func removeContext(c di.Container, lctx di.LifecycleContext) {
+	if err := c.RemoveContext(lctx); err != nil {
+		if logger, resolveErr := di.Resolve[dilogger.Logger](c, nil); resolveErr == nil {
+			logger.Errorf("[Context ID: %s] Failed to remove request lifecycle context: %v", lctx.ID(), err)
+		}
+	}
+}
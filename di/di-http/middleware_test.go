@@ -0,0 +1,91 @@
+package dihttp
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/lcrux/go-di/di"
+)
+
+type requestService struct {
+	ended *int32
+}
+
+func (s *requestService) EndLifecycle(_ ...context.Context) error {
+	atomic.AddInt32(s.ended, 1)
+	return nil
+}
+
+func newTestContainer(t *testing.T, ended *int32) di.Container {
+	t.Helper()
+	c := di.NewContainer()
+	if err := di.Register[*requestService](c, di.Scoped, func() *requestService {
+		return &requestService{ended: ended}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	return c
+}
+
+func TestScopeMiddleware_DisposesScopedInstances(t *testing.T) {
+	ended := int32(0)
+	c := newTestContainer(t, &ended)
+
+	handler := ScopeMiddleware(c)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lctx := ContextFrom(r)
+		if lctx == nil {
+			t.Fatal("expected a request lifecycle context")
+		}
+		if _, err := di.Resolve[*requestService](c, lctx); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+		if atomic.LoadInt32(&ended) != 0 {
+			t.Fatal("expected the scoped service to live until the end of the request")
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("expected status %d, got %d", http.StatusNoContent, rec.Code)
+	}
+	if atomic.LoadInt32(&ended) != 1 {
+		t.Fatalf("expected EndLifecycle to be called once after the request, got %d", ended)
+	}
+}
+
+func TestScopeMiddleware_DisposesOnPanic(t *testing.T) {
+	ended := int32(0)
+	c := newTestContainer(t, &ended)
+
+	handler := ScopeMiddleware(c)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := di.Resolve[*requestService](c, ContextFrom(r)); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+		panic("handler failure")
+	}))
+
+	func() {
+		defer func() {
+			if r := recover(); r == nil {
+				t.Fatal("expected the handler panic to be propagated")
+			}
+		}()
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}()
+
+	if atomic.LoadInt32(&ended) != 1 {
+		t.Fatalf("expected EndLifecycle to be called after the panic, got %d", ended)
+	}
+}
+
+func TestContextFrom_WithoutMiddleware(t *testing.T) {
+	if lctx := ContextFrom(httptest.NewRequest(http.MethodGet, "/", nil)); lctx != nil {
+		t.Fatal("expected no lifecycle context without the middleware")
+	}
+}
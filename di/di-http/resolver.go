@@ -0,0 +1,34 @@
+package dihttp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/lcrux/go-di/di"
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+// ErrNoRequestScope is returned when a Scoped service is resolved for a request without a lifecycle context,
+// typically because ScopeMiddleware is not installed.
+var ErrNoRequestScope = errors.New("no request lifecycle context, is ScopeMiddleware installed?")
+
+// Resolve resolves a service of type T within the lifecycle context of the given request.
+//
+// If the request has no lifecycle context, the container's background context is used for Transient and
+// Singleton services, while Scoped services fail with an error wrapping ErrNoRequestScope.
+func Resolve[T any](c di.Container, r *http.Request) (T, error) {
+	var zero T
+	if c == nil {
+		return zero, di.ErrNilContainer
+	}
+
+	lctx := ContextFrom(r)
+	if lctx == nil {
+		key := diutils.NameOf[T]()
+		if scope, ok := c.ScopeOf(key); ok && scope == di.Scoped {
+			return zero, fmt.Errorf("cannot resolve scoped service with key %s: %w", key, ErrNoRequestScope)
+		}
+	}
+	return di.Resolve[T](c, lctx)
+}
@@ -0,0 +1,75 @@
+package dihttp
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/lcrux/go-di/di"
+)
+
+func TestResolve_ConcurrentRequestsGetDistinctScopedInstances(t *testing.T) {
+	ended := int32(0)
+	c := newTestContainer(t, &ended)
+
+	var mutex sync.Mutex
+	instances := make([]*requestService, 0, 2)
+	started := sync.WaitGroup{}
+	started.Add(2)
+
+	handler := ScopeMiddleware(c)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		first, err := Resolve[*requestService](c, r)
+		if err != nil {
+			t.Errorf("unexpected resolve error: %v", err)
+			return
+		}
+		second, err := Resolve[*requestService](c, r)
+		if err != nil {
+			t.Errorf("unexpected resolve error: %v", err)
+			return
+		}
+		if first != second {
+			t.Error("expected the same scoped instance within a request")
+		}
+
+		mutex.Lock()
+		instances = append(instances, first)
+		mutex.Unlock()
+
+		// Keep both requests in flight at the same time
+		started.Done()
+		started.Wait()
+	}))
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+		}()
+	}
+	wg.Wait()
+
+	if len(instances) != 2 || instances[0] == instances[1] {
+		t.Fatal("expected distinct scoped instances for concurrent requests")
+	}
+}
+
+func TestResolve_ScopedWithoutMiddleware(t *testing.T) {
+	ended := int32(0)
+	c := newTestContainer(t, &ended)
+	if err := di.Register[*http.Client](c, di.Singleton, func() *http.Client { return &http.Client{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	if _, err := Resolve[*requestService](c, r); !errors.Is(err, ErrNoRequestScope) {
+		t.Fatalf("expected no request scope error, got: %v", err)
+	}
+	if _, err := Resolve[*http.Client](c, r); err != nil {
+		t.Fatalf("expected singletons to resolve from the background context, got: %v", err)
+	}
+}
@@ -1,9 +1,17 @@
 package dilogger
 
 import (
+	"encoding/json"
+	"fmt"
 	"log"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync/atomic"
+	"time"
 )
 
 // LogLevel represents the level of logging.
@@ -14,10 +22,14 @@ const (
 	Debug
 	Warn
 	Error
+	Fatal
 )
 
 var defaultLogLevel LogLevel
 
+// exitFunc terminates the program after a Fatal message has been logged, it can be replaced in tests.
+var exitFunc = os.Exit
+
 func init() {
 	envLogLevel := strings.ToUpper(strings.TrimSpace(os.Getenv("GODI_LOG_LEVEL")))
 	switch envLogLevel {
@@ -29,6 +41,8 @@ func init() {
 		defaultLogLevel = Warn
 	case "ERROR":
 		defaultLogLevel = Error
+	case "FATAL":
+		defaultLogLevel = Fatal
 	default:
 		defaultLogLevel = Error
 		if envLogLevel != "" {
@@ -37,20 +51,39 @@ func init() {
 	}
 }
 
+// LogFormat selects the output format of the default logging functions.
+type LogFormat int
+
+const (
+	// FormatText emits "[GO-DI:LEVEL] message" lines through the standard log package.
+	FormatText LogFormat = iota
+	// FormatJSON emits one JSON object per line, such as {"level":"info","msg":"...","ts":"..."},
+	// to the output of the standard log package, with the fields of the logger as additional keys.
+	FormatJSON
+)
+
 // LoggerFunc defines the signature for logging functions used in LoggerOptions.
 type LoggerFunc func(string, ...interface{})
 
 // LoggerOptions allows users to customize the logging behavior by providing their own logging functions and setting the log level.
 type LoggerOptions struct {
 	LogLevel LogLevel
-	Info     LoggerFunc
-	Warn     LoggerFunc
-	Debug    LoggerFunc
-	Error    LoggerFunc
+	// Format selects the output format of the default logging functions, FormatText by default.
+	// The custom logging functions are called as is, whatever the format.
+	Format LogFormat
+	// IncludeCaller prepends the file:line of the code that emitted the message to the output of the
+	// default logging functions, or adds it under the "caller" key in JSON. It is off by default,
+	// because looking up the caller has a cost on every message.
+	IncludeCaller bool
+	Info          LoggerFunc
+	Warn          LoggerFunc
+	Debug         LoggerFunc
+	Error         LoggerFunc
+	Fatal         LoggerFunc
 }
 
 // Logger allows for flexible logging implementations that can be swapped out as needed.
-// It defines methods for different log levels: Info, Warn, Debug, and Error.
+// It defines methods for different log levels: Info, Warn, Debug, Error, and Fatal.
 //
 // This is useful for dependency injection in applications where logging behavior may vary.
 type Logger interface {
@@ -58,10 +91,22 @@ type Logger interface {
 	Debugf(string, ...interface{})
 	Warnf(string, ...interface{})
 	Errorf(string, ...interface{})
+	// Fatalf logs the message and then terminates the program with exit code 1.
+	Fatalf(string, ...interface{})
+	// With returns a logger that attaches the given fields to every subsequent message.
+	// The fields are merged with the fields of the current logger, which is left unchanged.
+	With(fields map[string]interface{}) Logger
+	// SetLevel changes the log level of the logger at run time.
+	// It is safe to call concurrently with logging calls.
+	SetLevel(LogLevel)
+	// Level returns the current log level of the logger.
+	Level() LogLevel
 }
 
 type loggerImpl struct {
 	options *LoggerOptions
+	fields  map[string]interface{}
+	level   atomic.Int32
 }
 
 // isLoggerFunc checks if the provided function matches the expected signature for logging functions.
@@ -103,7 +148,9 @@ func NewLogger(builder func(*LoggerOptions)) Logger {
 
 	opts := buildLoggerOptions(options)
 
-	return &loggerImpl{options: opts}
+	logger := &loggerImpl{options: opts}
+	logger.level.Store(int32(opts.LogLevel))
+	return logger
 }
 
 // buildLoggerOptions merges the provided LoggerOptions with the default options.
@@ -112,9 +159,13 @@ func buildLoggerOptions(options *LoggerOptions) *LoggerOptions {
 		LogLevel: defaultLogLevel,
 	}
 	if options != nil {
-		if options.LogLevel >= Info && options.LogLevel <= Error {
+		if options.LogLevel >= Info && options.LogLevel <= Fatal {
 			loggerOpts.LogLevel = options.LogLevel
 		}
+		if options.Format == FormatText || options.Format == FormatJSON {
+			loggerOpts.Format = options.Format
+		}
+		loggerOpts.IncludeCaller = options.IncludeCaller
 		if isLoggerFunc(options.Info) {
 			loggerOpts.Info = options.Info
 		}
@@ -127,53 +178,177 @@ func buildLoggerOptions(options *LoggerOptions) *LoggerOptions {
 		if isLoggerFunc(options.Error) {
 			loggerOpts.Error = options.Error
 		}
+		if isLoggerFunc(options.Fatal) {
+			loggerOpts.Fatal = options.Fatal
+		}
 	}
 	return loggerOpts
 }
 
-func (l *loggerImpl) Infof(format string, v ...interface{}) {
-	if l.options.LogLevel > Info {
+// With returns a logger that attaches the given fields to every subsequent message.
+//
+// The fields are rendered as a "[key=value ...]" prefix, sorted by key, and passed to the LoggerFunc
+// as format verbs and arguments, so custom logging functions keep receiving a printf-style call.
+// The returned logger shares the options of the current logger, which is left unchanged.
+func (l *loggerImpl) With(fields map[string]interface{}) Logger {
+	merged := make(map[string]interface{}, len(l.fields)+len(fields))
+	for k, v := range l.fields {
+		merged[k] = v
+	}
+	for k, v := range fields {
+		merged[k] = v
+	}
+	logger := &loggerImpl{options: l.options, fields: merged}
+	logger.level.Store(int32(l.Level()))
+	return logger
+}
+
+// SetLevel changes the log level of the logger at run time.
+// Invalid log levels are ignored, loggers derived with With keep their own level.
+func (l *loggerImpl) SetLevel(level LogLevel) {
+	if level < Info || level > Fatal {
 		return
 	}
-	var fn LoggerFunc = defaultInfoLogger
-	if l.options.Info != nil {
-		fn = l.options.Info
+	l.level.Store(int32(level))
+}
+
+// Level returns the current log level of the logger.
+func (l *loggerImpl) Level() LogLevel {
+	return LogLevel(l.level.Load())
+}
+
+// withFields prepends the logger fields to the given format string and arguments.
+func (l *loggerImpl) withFields(format string, v []interface{}) (string, []interface{}) {
+	if len(l.fields) == 0 {
+		return format, v
+	}
+
+	keys := make([]string, 0, len(l.fields))
+	for k := range l.fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	prefix := make([]string, 0, len(keys))
+	args := make([]interface{}, 0, len(keys)+len(v))
+	for _, k := range keys {
+		// Escape the key, so it is not interpreted as a format verb
+		prefix = append(prefix, strings.ReplaceAll(k, "%", "%%")+"=%v")
+		args = append(args, l.fields[k])
 	}
-	fn(format, v...)
+	return "[" + strings.Join(prefix, " ") + "] " + format, append(args, v...)
 }
 
-func (l *loggerImpl) Debugf(format string, v ...interface{}) {
-	if l.options.LogLevel > Debug {
+func (l *loggerImpl) Infof(format string, v ...interface{}) {
+	if l.Level() > Info {
 		return
 	}
+	l.emit(Info, l.options.Info, defaultInfoLogger, format, v)
+}
 
-	var fn LoggerFunc = defaultDebugLogger
-	if l.options.Debug != nil {
-		fn = l.options.Debug
+func (l *loggerImpl) Debugf(format string, v ...interface{}) {
+	if l.Level() > Debug {
+		return
 	}
-	fn(format, v...)
+	l.emit(Debug, l.options.Debug, defaultDebugLogger, format, v)
 }
 
 func (l *loggerImpl) Warnf(format string, v ...interface{}) {
-	if l.options.LogLevel > Warn {
+	if l.Level() > Warn {
 		return
 	}
-	var fn LoggerFunc = defaultWarnLogger
-	if l.options.Warn != nil {
-		fn = l.options.Warn
-	}
-	fn(format, v...)
+	l.emit(Warn, l.options.Warn, defaultWarnLogger, format, v)
 }
 
 func (l *loggerImpl) Errorf(format string, v ...interface{}) {
-	if l.options.LogLevel > Error {
+	if l.Level() > Error {
 		return
 	}
-	var fn LoggerFunc = defaultErrorLogger
-	if l.options.Error != nil {
-		fn = l.options.Error
+	l.emit(Error, l.options.Error, defaultErrorLogger, format, v)
+}
+
+// Fatalf logs the message and then terminates the program with exit code 1.
+// The Fatal level is the highest level, so the message is emitted whatever the configured log level is.
+func (l *loggerImpl) Fatalf(format string, v ...interface{}) {
+	if l.Level() > Fatal {
+		return
+	}
+	l.emit(Fatal, l.options.Fatal, defaultFatalLogger, format, v)
+	exitFunc(1)
+}
+
+// callerSkip is the number of frames between runtime.Caller in emit and the code calling the logger:
+// emit itself and the logging method (Infof, Debugf, ...) that called it.
+const callerSkip = 2
+
+// emit logs the message with the custom logging function if there is one, or with the default one
+// in the format of the logger otherwise. It must only be called directly by the logging methods,
+// so that callerSkip points at their caller.
+func (l *loggerImpl) emit(level LogLevel, custom LoggerFunc, defaultFn LoggerFunc, format string, v []interface{}) {
+	if custom != nil {
+		format, v = l.withFields(format, v)
+		custom(format, v...)
+		return
+	}
+	caller := ""
+	if l.options.IncludeCaller {
+		caller = callerLocation(callerSkip + 1)
+	}
+	if l.options.Format == FormatJSON {
+		writeJSON(level, fmt.Sprintf(format, v...), caller, l.fields)
+		return
 	}
-	fn(format, v...)
+	format, v = l.withFields(format, v)
+	if caller != "" {
+		format = "%s: " + format
+		v = append([]interface{}{caller}, v...)
+	}
+	defaultFn(format, v...)
+}
+
+// callerLocation returns the "file:line" of the frame skip levels above its caller,
+// with the base name of the file, or "???:0" when the frame is not available.
+func callerLocation(skip int) string {
+	_, file, line, ok := runtime.Caller(skip)
+	if !ok {
+		return "???:0"
+	}
+	return filepath.Base(file) + ":" + strconv.Itoa(line)
+}
+
+// levelNames are the names of the log levels in the JSON output.
+var levelNames = map[LogLevel]string{
+	Info:  "info",
+	Debug: "debug",
+	Warn:  "warn",
+	Error: "error",
+	Fatal: "fatal",
+}
+
+// writeJSON writes the message as a JSON line to the output of the standard log package.
+// The fields are added as keys, unless they collide with the level, message, timestamp or caller keys,
+// and the values that cannot be encoded in JSON are rendered with fmt. The caller is omitted when empty.
+func writeJSON(level LogLevel, msg string, caller string, fields map[string]interface{}) {
+	entry := make(map[string]interface{}, len(fields)+4)
+	for k, v := range fields {
+		if _, err := json.Marshal(v); err != nil {
+			v = fmt.Sprint(v)
+		}
+		entry[k] = v
+	}
+	entry["level"] = levelNames[level]
+	entry["msg"] = msg
+	entry["ts"] = time.Now().UTC().Format(time.RFC3339Nano)
+	if caller != "" {
+		entry["caller"] = caller
+	}
+
+	line, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("[GO-DI:ERROR] Failed to encode log entry: %v\n", err)
+		return
+	}
+	_, _ = log.Writer().Write(append(line, '\n'))
 }
 
 func defaultInfoLogger(format string, v ...interface{}) {
@@ -188,3 +363,7 @@ func defaultWarnLogger(format string, v ...interface{}) {
 func defaultErrorLogger(format string, v ...interface{}) {
 	log.Printf("[GO-DI:ERROR] "+format+"\n", v...)
 }
+
+func defaultFatalLogger(format string, v ...interface{}) {
+	log.Printf("[GO-DI:FATAL] "+format+"\n", v...)
+}
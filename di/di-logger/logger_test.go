@@ -1,10 +1,16 @@
 package dilogger
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"log"
+	"runtime"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestLoggerImpl_LogLevelFiltering(t *testing.T) {
@@ -154,3 +160,353 @@ func TestLoggerImpl_SpecialCharacters(t *testing.T) {
 		t.Fatalf("Expected '%s', got '%s'", message, got)
 	}
 }
+
+func TestLoggerImpl_WithFields(t *testing.T) {
+	var got string
+	logger := NewLogger(func(o *LoggerOptions) {
+		o.LogLevel = Info
+		o.Info = func(format string, v ...interface{}) { got = fmt.Sprintf(format, v...) }
+	})
+
+	logger.With(map[string]interface{}{"request_id": "abc", "attempt": 2}).Infof("Handled %s", "request")
+
+	expected := "[attempt=2 request_id=abc] Handled request"
+	if got != expected {
+		t.Fatalf("Expected '%s', got '%s'", expected, got)
+	}
+}
+
+func TestLoggerImpl_WithFieldsIsChainable(t *testing.T) {
+	var got string
+	logger := NewLogger(func(o *LoggerOptions) {
+		o.LogLevel = Info
+		o.Info = func(format string, v ...interface{}) { got = fmt.Sprintf(format, v...) }
+	})
+
+	parent := logger.With(map[string]interface{}{"service": "todo"})
+	child := parent.With(map[string]interface{}{"request_id": "abc"})
+
+	child.Infof("child message")
+	if expected := "[request_id=abc service=todo] child message"; got != expected {
+		t.Fatalf("Expected '%s', got '%s'", expected, got)
+	}
+
+	parent.Infof("parent message")
+	if expected := "[service=todo] parent message"; got != expected {
+		t.Fatalf("Expected parent logger to be unchanged, got '%s'", got)
+	}
+
+	logger.Infof("root message")
+	if expected := "root message"; got != expected {
+		t.Fatalf("Expected root logger to be unchanged, got '%s'", got)
+	}
+}
+
+func TestLoggerImpl_WithFieldsEscapesKeys(t *testing.T) {
+	var got string
+	logger := NewLogger(func(o *LoggerOptions) {
+		o.LogLevel = Info
+		o.Info = func(format string, v ...interface{}) { got = fmt.Sprintf(format, v...) }
+	})
+
+	logger.With(map[string]interface{}{"100%": true}).Infof("done")
+
+	if expected := "[100%=true] done"; got != expected {
+		t.Fatalf("Expected '%s', got '%s'", expected, got)
+	}
+}
+
+func TestLoggerImpl_SetLevel(t *testing.T) {
+	called := false
+	logger := NewLogger(func(o *LoggerOptions) {
+		o.LogLevel = Error
+		o.Debug = func(_ string, _ ...interface{}) { called = true }
+	})
+
+	logger.Debugf("Should not log debug")
+	if called {
+		t.Fatal("Debug should not be called at Error level")
+	}
+
+	logger.SetLevel(Debug)
+	if logger.Level() != Debug {
+		t.Fatalf("Expected level %d, got %d", Debug, logger.Level())
+	}
+	logger.Debugf("Should log debug")
+	if !called {
+		t.Fatal("Debug should be called after changing the level to Debug")
+	}
+}
+
+func TestLoggerImpl_SetLevelIgnoresInvalidLevel(t *testing.T) {
+	logger := NewLogger(func(o *LoggerOptions) {
+		o.LogLevel = Warn
+	})
+
+	logger.SetLevel(LogLevel(42))
+	if logger.Level() != Warn {
+		t.Fatalf("Expected level to remain %d, got %d", Warn, logger.Level())
+	}
+}
+
+func TestLoggerImpl_WithKeepsOwnLevel(t *testing.T) {
+	logger := NewLogger(func(o *LoggerOptions) {
+		o.LogLevel = Warn
+	})
+	child := logger.With(map[string]interface{}{"k": "v"})
+
+	child.SetLevel(Debug)
+	if logger.Level() != Warn {
+		t.Fatalf("Expected parent level to remain %d, got %d", Warn, logger.Level())
+	}
+	if child.Level() != Debug {
+		t.Fatalf("Expected child level %d, got %d", Debug, child.Level())
+	}
+}
+
+func TestLoggerImpl_SetLevelConcurrentLogging(t *testing.T) {
+	var count int64
+	var mu sync.Mutex
+	record := func(_ string, _ ...interface{}) {
+		mu.Lock()
+		count++
+		mu.Unlock()
+	}
+	logger := NewLogger(func(o *LoggerOptions) {
+		o.LogLevel = Info
+		o.Info = record
+		o.Debug = record
+		o.Warn = record
+		o.Error = record
+	})
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.Infof("info")
+				logger.Debugf("debug")
+				logger.Errorf("error")
+			}
+		}()
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				logger.SetLevel(LogLevel((i + j) % 4))
+				_ = logger.Level()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// Error messages are always emitted, whatever the level is
+	if count < 400 {
+		t.Fatalf("Expected at least 400 messages, got %d", count)
+	}
+}
+
+// stubExit replaces exitFunc for the duration of the test and returns a pointer to the recorded exit code.
+func stubExit(t *testing.T) *int {
+	t.Helper()
+	code := -1
+	original := exitFunc
+	exitFunc = func(c int) { code = c }
+	t.Cleanup(func() { exitFunc = original })
+	return &code
+}
+
+func TestLoggerImpl_Fatalf(t *testing.T) {
+	code := stubExit(t)
+	var got string
+	logger := NewLogger(func(o *LoggerOptions) {
+		o.LogLevel = Error
+		o.Fatal = func(format string, v ...interface{}) { got = fmt.Sprintf(format, v...) }
+	})
+
+	logger.Fatalf("Fatal message: %d", 42)
+
+	if got != "Fatal message: 42" {
+		t.Fatalf("Expected fatal message to be logged at Error level, got '%s'", got)
+	}
+	if *code != 1 {
+		t.Fatalf("Expected exit code 1, got %d", *code)
+	}
+}
+
+func TestLoggerImpl_FatalLevelFiltering(t *testing.T) {
+	stubExit(t)
+	called := make(map[string]bool)
+	logger := NewLogger(func(o *LoggerOptions) {
+		o.LogLevel = Fatal
+		o.Error = func(_ string, _ ...interface{}) { called["error"] = true }
+		o.Fatal = func(_ string, _ ...interface{}) { called["fatal"] = true }
+	})
+
+	logger.Errorf("Should not log error")
+	logger.Fatalf("Should log fatal")
+
+	if called["error"] {
+		t.Errorf("Error should not be called at Fatal level")
+	}
+	if !called["fatal"] {
+		t.Errorf("Fatal should be called at Fatal level")
+	}
+	if logger.Level() != Fatal {
+		t.Errorf("Expected level %d, got %d", Fatal, logger.Level())
+	}
+}
+
+// captureOutput redirects the standard log output to a buffer for the duration of the test.
+func captureOutput(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	var buf bytes.Buffer
+	original := log.Writer()
+	log.SetOutput(&buf)
+	t.Cleanup(func() { log.SetOutput(original) })
+	return &buf
+}
+
+func TestLoggerImpl_JSONFormat(t *testing.T) {
+	stubExit(t)
+	buf := captureOutput(t)
+	logger := NewLogger(func(o *LoggerOptions) {
+		o.LogLevel = Info
+		o.Format = FormatJSON
+	}).With(map[string]interface{}{"request_id": 7, "msg": "ignored"})
+
+	logger.Infof("Info %d", 1)
+	logger.Debugf("Debug %d", 2)
+	logger.Warnf("Warn %d", 3)
+	logger.Errorf("Error %d", 4)
+	logger.Fatalf("Fatal %d", 5)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	expected := []struct{ level, msg string }{
+		{"info", "Info 1"}, {"debug", "Debug 2"}, {"warn", "Warn 3"}, {"error", "Error 4"}, {"fatal", "Fatal 5"},
+	}
+	if len(lines) != len(expected) {
+		t.Fatalf("Expected %d lines, got %d: %q", len(expected), len(lines), buf.String())
+	}
+	for i, line := range lines {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("Expected valid JSON, got %q: %v", line, err)
+		}
+		if entry["level"] != expected[i].level || entry["msg"] != expected[i].msg {
+			t.Errorf("Expected level %q and msg %q, got %v", expected[i].level, expected[i].msg, entry)
+		}
+		if entry["request_id"] != float64(7) {
+			t.Errorf("Expected request_id field 7, got %v", entry["request_id"])
+		}
+		ts, ok := entry["ts"].(string)
+		if !ok {
+			t.Fatalf("Expected a ts string, got %v", entry["ts"])
+		}
+		if _, err := time.Parse(time.RFC3339Nano, ts); err != nil {
+			t.Errorf("Expected an RFC 3339 timestamp, got %q", ts)
+		}
+	}
+}
+
+func TestLoggerImpl_JSONFormatKeepsCustomFuncs(t *testing.T) {
+	buf := captureOutput(t)
+	var got string
+	logger := NewLogger(func(o *LoggerOptions) {
+		o.LogLevel = Info
+		o.Format = FormatJSON
+		o.Info = func(format string, v ...interface{}) { got = fmt.Sprintf(format, v...) }
+	})
+
+	logger.Infof("Custom %s", "message")
+
+	if got != "Custom message" {
+		t.Fatalf("Expected custom function to be called, got '%s'", got)
+	}
+	if buf.Len() != 0 {
+		t.Fatalf("Expected no default output, got %q", buf.String())
+	}
+}
+
+func TestLoggerImpl_TextFormatUnchanged(t *testing.T) {
+	buf := captureOutput(t)
+	logger := NewLogger(func(o *LoggerOptions) {
+		o.LogLevel = Info
+	}).With(map[string]interface{}{"k": "v"})
+
+	logger.Infof("Text %d", 1)
+
+	if !strings.Contains(buf.String(), "[GO-DI:INFO] [k=v] Text 1\n") {
+		t.Fatalf("Expected text output, got %q", buf.String())
+	}
+	if strings.Contains(buf.String(), "{") {
+		t.Fatalf("Expected no JSON in text output, got %q", buf.String())
+	}
+}
+
+// thisLine returns the line number of its caller, to compute the line of the next statement.
+func thisLine() int {
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func TestLoggerImpl_IncludeCaller(t *testing.T) {
+	stubExit(t)
+	buf := captureOutput(t)
+	logger := NewLogger(func(o *LoggerOptions) {
+		o.LogLevel = Info
+		o.IncludeCaller = true
+	})
+
+	line := thisLine() + 1
+	logger.With(map[string]interface{}{"k": "v"}).Infof("Text message")
+
+	expected := "logger_test.go:" + strconv.Itoa(line) + ": [k=v] Text message\n"
+	if !strings.Contains(buf.String(), "[GO-DI:INFO] "+expected) {
+		t.Fatalf("Expected output to contain %q, got %q", expected, buf.String())
+	}
+
+	for _, logf := range []func(string, ...interface{}){logger.Debugf, logger.Warnf, logger.Errorf, logger.Fatalf} {
+		buf.Reset()
+		line := thisLine() + 1
+		logf("Message")
+		expected := "logger_test.go:" + strconv.Itoa(line) + ": Message\n"
+		if !strings.HasSuffix(buf.String(), expected) {
+			t.Errorf("Expected output to end with %q, got %q", expected, buf.String())
+		}
+	}
+}
+
+func TestLoggerImpl_IncludeCallerJSON(t *testing.T) {
+	buf := captureOutput(t)
+	logger := NewLogger(func(o *LoggerOptions) {
+		o.LogLevel = Info
+		o.Format = FormatJSON
+		o.IncludeCaller = true
+	})
+
+	line := thisLine() + 1
+	logger.Warnf("JSON message")
+
+	var entry map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("Expected valid JSON, got %q: %v", buf.String(), err)
+	}
+	if expected := "logger_test.go:" + strconv.Itoa(line); entry["caller"] != expected {
+		t.Fatalf("Expected caller %q, got %v", expected, entry["caller"])
+	}
+}
+
+func TestLoggerImpl_IncludeCallerOffByDefault(t *testing.T) {
+	buf := captureOutput(t)
+	logger := NewLogger(func(o *LoggerOptions) {
+		o.LogLevel = Info
+	})
+
+	logger.Infof("Message")
+
+	if strings.Contains(buf.String(), "logger_test.go") {
+		t.Fatalf("Expected no caller in output, got %q", buf.String())
+	}
+}
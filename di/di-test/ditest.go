@@ -0,0 +1,109 @@
+package ditest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lcrux/go-di/di"
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+// New creates a container with the given options, shut down when the test and its subtests complete.
+// A shutdown error fails the test.
+func New(t testing.TB, opts ...di.ContainerOption) di.Container {
+	t.Helper()
+
+	c := di.NewContainer(opts...)
+	t.Cleanup(func() {
+		if err := c.Shutdown(); err != nil {
+			t.Errorf("failed to shutdown container: %v", err)
+		}
+	})
+	return c
+}
+
+// Override replaces the registration of the service of type T with a Singleton returning the given instance,
+// typically a test double. The service does not need to be registered already.
+//
+// The registration is replaced with di.Container.ImportFrom, so a Singleton instance already created is disposed
+// and the next resolutions return the instance. It returns an error if the container is frozen.
+func Override[T any](c di.Container, instance T) error {
+	if c == nil {
+		return di.ErrNilContainer
+	}
+
+	// The container holding the replacement only lives until it is imported, it never creates an instance
+	override := di.NewContainer()
+	defer override.Shutdown()
+
+	if err := di.Register[T](override, di.Singleton, func() T { return instance }); err != nil {
+		return fmt.Errorf("failed to override %v: %w", diutils.TypeOf[T](), err)
+	}
+	if err := c.ImportFrom(override, di.Overwrite); err != nil {
+		return fmt.Errorf("failed to override %v: %w", diutils.TypeOf[T](), err)
+	}
+	return nil
+}
+
+// Recorder records the resolutions of a service, as returned by Spy.
+type Recorder struct {
+	di.NopObserver
+
+	key      string
+	mutex    sync.Mutex
+	resolved int
+	created  int
+	failed   int
+}
+
+// Spy records the resolutions of the service of type T registered with its default key,
+// including its injections into other services, from the time it is called.
+func Spy[T any](c di.Container) *Recorder {
+	recorder := &Recorder{key: di.KeyOf[T]()}
+	if c != nil {
+		c.AddObserver(recorder)
+	}
+	return recorder
+}
+
+// OnResolveEnd records a resolution of the spied service.
+func (r *Recorder) OnResolveEnd(key string, cached bool, _ time.Duration, err error) {
+	if key != r.key {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	switch {
+	case err != nil:
+		r.failed++
+	case cached:
+		r.resolved++
+	default:
+		r.resolved++
+		r.created++
+	}
+}
+
+// Resolutions returns the number of successful resolutions of the spied service, including the cached ones.
+func (r *Recorder) Resolutions() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.resolved
+}
+
+// Created returns the number of instances of the spied service created by its factory function.
+func (r *Recorder) Created() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.created
+}
+
+// Failures returns the number of failed resolutions of the spied service.
+func (r *Recorder) Failures() int {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.failed
+}
@@ -0,0 +1,102 @@
+package ditest
+
+import (
+	"testing"
+
+	"github.com/lcrux/go-di/di"
+)
+
+type greeter interface {
+	Greet() string
+}
+
+type englishGreeter struct{}
+
+func (englishGreeter) Greet() string { return "hello" }
+
+type fakeGreeter struct{}
+
+func (fakeGreeter) Greet() string { return "fake" }
+
+type closingService struct {
+	closed *bool
+}
+
+func (s *closingService) Close() error {
+	*s.closed = true
+	return nil
+}
+
+func TestNew_ShutsDownOnCleanup(t *testing.T) {
+	closed := false
+	t.Run("inner", func(t *testing.T) {
+		c := New(t)
+		if err := di.Register[*closingService](c, di.Singleton, func() *closingService {
+			return &closingService{closed: &closed}
+		}); err != nil {
+			t.Fatalf("unexpected register error: %v", err)
+		}
+		if _, err := di.Resolve[*closingService](c, nil); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+		if closed {
+			t.Fatal("expected the service not to be closed before the cleanup")
+		}
+	})
+	if !closed {
+		t.Fatal("expected the container to be shut down once the test completed")
+	}
+}
+
+func TestOverride(t *testing.T) {
+	c := New(t)
+	if err := di.Register[greeter](c, di.Singleton, func() greeter { return englishGreeter{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if g, _ := di.Resolve[greeter](c, nil); g.Greet() != "hello" {
+		t.Fatalf("expected the registered greeter, got %q", g.Greet())
+	}
+
+	if err := Override[greeter](c, fakeGreeter{}); err != nil {
+		t.Fatalf("unexpected override error: %v", err)
+	}
+	g, err := di.Resolve[greeter](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if g.Greet() != "fake" {
+		t.Fatalf("expected the overridden greeter, got %q", g.Greet())
+	}
+
+	c.Freeze()
+	if err := Override[greeter](c, englishGreeter{}); err == nil {
+		t.Fatal("expected an error overriding a service of a frozen container")
+	}
+}
+
+func TestSpy(t *testing.T) {
+	c := New(t)
+	if err := di.Register[greeter](c, di.Singleton, func() greeter { return englishGreeter{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := di.Register[*closingService](c, di.Transient, func(greeter) *closingService {
+		return &closingService{closed: new(bool)}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	spy := Spy[greeter](c)
+	for i := 0; i < 2; i++ {
+		if _, err := di.Resolve[greeter](c, nil); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+	}
+	if _, err := di.Resolve[*closingService](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	if spy.Resolutions() != 3 || spy.Created() != 1 || spy.Failures() != 0 {
+		t.Fatalf("expected 3 resolutions and 1 creation, got %d and %d with %d failures",
+			spy.Resolutions(), spy.Created(), spy.Failures())
+	}
+}
@@ -0,0 +1,9 @@
+// Package svc declares a type sharing its package and type name with another fixture package,
+// so type names can be checked to be qualified by import path.
+package svc
+
+// Service is a type with the same name in every fixture package.
+type Service struct{}
+
+// Counter is an unnamed struct type with an unexported field, declared in every fixture package.
+type Counter = struct{ count int }
@@ -9,6 +9,22 @@ type AsyncMap[K comparable, V any] interface {
 	Keys() []K
 	Values() []V
 	Cleanup()
+	// Len returns the number of entries in the map.
+	Len() int
+	// Range calls fn for each entry in the map under the read lock, stopping when fn returns false.
+	// The callback must not call back into the map, as any write would deadlock.
+	Range(fn func(K, V) bool)
+	// GetOrSet atomically returns the existing value for the key, or stores and returns the given value.
+	// The boolean result is true if the value was loaded, false if it was stored.
+	GetOrSet(key K, value V) (V, bool)
+	// LoadOrStore is equivalent to GetOrSet, following the naming of sync.Map.
+	LoadOrStore(key K, value V) (V, bool)
+	// Compute atomically stores and returns the result of fn, called with the current value for the key
+	// and whether it exists. The callback must not call back into the map.
+	Compute(key K, fn func(old V, ok bool) V) V
+	// Update atomically calls fn with the current value for the key and whether it exists, then stores the
+	// returned value, or deletes the key when fn returns false. The callback must not call back into the map.
+	Update(key K, fn func(old V, ok bool) (V, bool))
 }
 
 type asyncMaper[K comparable, V any] struct {
@@ -59,6 +75,57 @@ func (m *asyncMaper[K, V]) Cleanup() {
 	m.data = make(map[K]V)
 }
 
+func (m *asyncMaper[K, V]) Len() int {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return len(m.data)
+}
+
+func (m *asyncMaper[K, V]) Range(fn func(K, V) bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	for k, v := range m.data {
+		if !fn(k, v) {
+			return
+		}
+	}
+}
+
+func (m *asyncMaper[K, V]) GetOrSet(key K, value V) (V, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if existing, exists := m.data[key]; exists {
+		return existing, true
+	}
+	m.data[key] = value
+	return value, false
+}
+
+func (m *asyncMaper[K, V]) LoadOrStore(key K, value V) (V, bool) {
+	return m.GetOrSet(key, value)
+}
+
+func (m *asyncMaper[K, V]) Compute(key K, fn func(old V, ok bool) V) V {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	old, exists := m.data[key]
+	value := fn(old, exists)
+	m.data[key] = value
+	return value
+}
+
+func (m *asyncMaper[K, V]) Update(key K, fn func(old V, ok bool) (V, bool)) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	old, exists := m.data[key]
+	value, keep := fn(old, exists)
+	if !keep {
+		delete(m.data, key)
+		return
+	}
+	m.data[key] = value
+}
+
 func getMapKeys[K comparable, V any](m map[K]V) []K {
 	if m == nil {
 		return make([]K, 0)
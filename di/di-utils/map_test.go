@@ -1,6 +1,8 @@
 package diutils
 
 import (
+	"sync"
+	"sync/atomic"
 	"testing"
 )
 
@@ -75,3 +77,179 @@ func TestMapCleanup(t *testing.T) {
 		t.Fatal("Expected map to be empty after Cleanup")
 	}
 }
+
+func TestMapLen(t *testing.T) {
+	m := NewAsyncMap[string, int]()
+	m.Set("key1", 100)
+	m.Set("key2", 200)
+	if m.Len() != 2 {
+		t.Fatalf("Expected length 2, got %d", m.Len())
+	}
+	m.Delete("key1")
+	if m.Len() != 1 {
+		t.Fatalf("Expected length 1, got %d", m.Len())
+	}
+}
+
+func TestMapRange(t *testing.T) {
+	m := NewAsyncMap[string, int]()
+	m.Set("key1", 100)
+	m.Set("key2", 200)
+	m.Set("key3", 300)
+
+	sum := 0
+	m.Range(func(_ string, v int) bool {
+		sum += v
+		return true
+	})
+	if sum != 600 {
+		t.Fatalf("Expected sum 600, got %d", sum)
+	}
+
+	visited := 0
+	m.Range(func(_ string, _ int) bool {
+		visited++
+		return false
+	})
+	if visited != 1 {
+		t.Fatalf("Expected Range to stop after 1 entry, got %d", visited)
+	}
+}
+
+func TestMapGetOrSet(t *testing.T) {
+	m := NewAsyncMap[string, int]()
+
+	value, loaded := m.GetOrSet("key1", 100)
+	if loaded || value != 100 {
+		t.Fatalf("Expected value 100 to be stored, got %d (loaded: %v)", value, loaded)
+	}
+	value, loaded = m.GetOrSet("key1", 200)
+	if !loaded || value != 100 {
+		t.Fatalf("Expected existing value 100 to be loaded, got %d (loaded: %v)", value, loaded)
+	}
+}
+
+func TestMapGetOrSetConcurrent(t *testing.T) {
+	m := NewAsyncMap[string, int]()
+	stored := int32(0)
+
+	wg := sync.WaitGroup{}
+	results := make([]int, 100)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, loaded := m.GetOrSet("key", i)
+			if !loaded {
+				atomic.AddInt32(&stored, 1)
+			}
+			results[i] = value
+		}(i)
+	}
+	wg.Wait()
+
+	if stored != 1 {
+		t.Fatalf("Expected exactly one value to be stored, got %d", stored)
+	}
+	for _, value := range results {
+		if value != results[0] {
+			t.Fatalf("Expected all goroutines to get the same value, got %d and %d", value, results[0])
+		}
+	}
+}
+
+func TestMapLoadOrStore(t *testing.T) {
+	m := NewAsyncMap[string, int]()
+
+	if value, loaded := m.LoadOrStore("key1", 100); loaded || value != 100 {
+		t.Fatalf("Expected value 100 to be stored, got %d (loaded: %v)", value, loaded)
+	}
+	if value, loaded := m.LoadOrStore("key1", 200); !loaded || value != 100 {
+		t.Fatalf("Expected existing value 100 to be loaded, got %d (loaded: %v)", value, loaded)
+	}
+}
+
+func TestMapComputeConcurrent(t *testing.T) {
+	m := NewAsyncMap[string, int]()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Compute("counter", func(old int, _ bool) int {
+				return old + 1
+			})
+		}()
+	}
+	wg.Wait()
+
+	if value, _ := m.Get("counter"); value != 100 {
+		t.Fatalf("Expected counter to be 100, got %d", value)
+	}
+}
+
+func TestMapComputeReportsExistence(t *testing.T) {
+	m := NewAsyncMap[string, int]()
+
+	value := m.Compute("key1", func(old int, ok bool) int {
+		if ok {
+			t.Fatal("Expected key1 to not exist")
+		}
+		return old + 10
+	})
+	if value != 10 {
+		t.Fatalf("Expected value 10, got %d", value)
+	}
+	m.Compute("key1", func(old int, ok bool) int {
+		if !ok || old != 10 {
+			t.Fatalf("Expected existing value 10, got %d (ok: %v)", old, ok)
+		}
+		return old
+	})
+}
+
+func TestMapUpdateConcurrent(t *testing.T) {
+	m := NewAsyncMap[string, int]()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.Update("counter", func(old int, _ bool) (int, bool) {
+				return old + 1, true
+			})
+		}()
+	}
+	wg.Wait()
+
+	if value, _ := m.Get("counter"); value != 100 {
+		t.Fatalf("Expected counter to be 100, got %d", value)
+	}
+}
+
+func TestMapUpdateDeletesKey(t *testing.T) {
+	m := NewAsyncMap[string, int]()
+	m.Set("key1", 1)
+
+	m.Update("key1", func(old int, ok bool) (int, bool) {
+		if !ok || old != 1 {
+			t.Fatalf("Expected existing value 1, got %d (ok: %v)", old, ok)
+		}
+		return 0, false
+	})
+	if _, exists := m.Get("key1"); exists {
+		t.Fatal("Expected key1 to be deleted")
+	}
+
+	m.Update("key2", func(old int, ok bool) (int, bool) {
+		if ok {
+			t.Fatal("Expected key2 to not exist")
+		}
+		return 0, false
+	})
+	if m.Len() != 0 {
+		t.Fatalf("Expected an empty map, got %d entries", m.Len())
+	}
+}
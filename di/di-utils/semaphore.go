@@ -1,8 +1,11 @@
 package diutils
 
 import (
+	"context"
+	"fmt"
 	"os"
 	"strconv"
+	"sync"
 )
 
 var defaultSemaphoreCapacity = 10
@@ -18,6 +21,8 @@ func init() {
 // Semaphore is a simple semaphore implementation.
 type Semaphore struct {
 	ch chan struct{}
+	// weighted serializes AcquireN calls, so two weighted acquisitions holding part of their slots cannot deadlock
+	weighted sync.Mutex
 }
 
 // NewSemaphore creates a new semaphore with the given capacity.
@@ -36,11 +41,66 @@ func (s *Semaphore) Acquire() {
 	s.ch <- struct{}{}
 }
 
+// AcquireN acquires n slots in the semaphore, blocking until all of them are acquired.
+// It panics if n is negative or greater than the capacity of the semaphore, since the call could never return.
+func (s *Semaphore) AcquireN(n int) {
+	s.checkWeight(n)
+
+	s.weighted.Lock()
+	defer s.weighted.Unlock()
+	for i := 0; i < n; i++ {
+		s.ch <- struct{}{}
+	}
+}
+
+// AcquireCtx acquires a slot in the semaphore, blocking until a slot is available or the context is done.
+// It returns the context error if the context is done before a slot is obtained.
+func (s *Semaphore) AcquireCtx(ctx context.Context) error {
+	// Check the context first, select picks randomly when both cases are ready
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	select {
+	case s.ch <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// TryAcquire attempts to acquire a slot in the semaphore without blocking.
+// It returns true if a slot was acquired, false if the semaphore is at capacity.
+func (s *Semaphore) TryAcquire() bool {
+	select {
+	case s.ch <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
 // Release releases a slot in the semaphore.
 func (s *Semaphore) Release() {
 	<-s.ch
 }
 
+// ReleaseN releases n slots in the semaphore, typically acquired with AcquireN.
+// It panics if n is negative or greater than the capacity of the semaphore.
+func (s *Semaphore) ReleaseN(n int) {
+	s.checkWeight(n)
+
+	for i := 0; i < n; i++ {
+		<-s.ch
+	}
+}
+
+// checkWeight panics if n is not a valid number of slots for the semaphore.
+func (s *Semaphore) checkWeight(n int) {
+	if n < 0 || n > cap(s.ch) {
+		panic(fmt.Sprintf("semaphore: cannot use %d slots, capacity is %d", n, cap(s.ch)))
+	}
+}
+
 // Done closes the semaphore channel, releasing all resources.
 // Any attempt to acquire or release the semaphore after calling Done will panic.
 func (s *Semaphore) Done() {
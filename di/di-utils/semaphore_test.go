@@ -1,6 +1,8 @@
 package diutils
 
 import (
+	"context"
+	"errors"
 	"sync"
 	"testing"
 	"time"
@@ -94,3 +96,105 @@ func TestSemaphoreConcurrency(t *testing.T) {
 		t.Fatalf("Expected counter to be 10, got %d", counter)
 	}
 }
+
+func TestSemaphoreAcquireCtx(t *testing.T) {
+	sem := NewSemaphore(1)
+
+	if err := sem.AcquireCtx(context.Background()); err != nil {
+		t.Fatalf("Expected AcquireCtx to succeed, got %v", err)
+	}
+
+	// The semaphore is at capacity, so AcquireCtx blocks until the context is canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- sem.AcquireCtx(ctx)
+	}()
+
+	select {
+	case err := <-done:
+		t.Fatalf("Expected AcquireCtx to block, got %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Fatalf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected AcquireCtx to return after cancellation")
+	}
+}
+
+func TestSemaphoreAcquireCtxCanceledBeforeStart(t *testing.T) {
+	sem := NewSemaphore(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if err := sem.AcquireCtx(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if !sem.TryAcquire() {
+		t.Fatal("Expected the slot to remain available after a canceled AcquireCtx")
+	}
+}
+
+func TestSemaphoreTryAcquire(t *testing.T) {
+	sem := NewSemaphore(2)
+
+	if !sem.TryAcquire() || !sem.TryAcquire() {
+		t.Fatal("Expected TryAcquire to succeed below capacity")
+	}
+	if sem.TryAcquire() {
+		t.Fatal("Expected TryAcquire to fail at capacity")
+	}
+
+	sem.Release()
+	if !sem.TryAcquire() {
+		t.Fatal("Expected TryAcquire to succeed after Release")
+	}
+}
+
+func TestSemaphoreAcquireNReleaseN(t *testing.T) {
+	sem := NewSemaphore(2)
+	sem.AcquireN(2)
+
+	if sem.TryAcquire() {
+		t.Fatal("Expected AcquireN to use all the slots")
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		sem.Acquire()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("Expected Acquire to block after AcquireN, but it did not")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	sem.ReleaseN(2)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("Expected Acquire to proceed after ReleaseN, but it did not")
+	}
+	if !sem.TryAcquire() {
+		t.Fatal("Expected ReleaseN to free both slots")
+	}
+}
+
+func TestSemaphoreAcquireNPanicsAboveCapacity(t *testing.T) {
+	sem := NewSemaphore(2)
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("Expected AcquireN to panic above capacity, but no panic occurred")
+		}
+	}()
+	sem.AcquireN(3)
+}
@@ -3,6 +3,8 @@ package diutils
 import (
 	"fmt"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
 // TypeOf returns the reflect.Type of a generic type T.
@@ -17,25 +19,115 @@ func NameOf[T interface{}]() string {
 }
 
 // NameOfType returns the fully qualified name of a reflect.Type.
+//
+// Named types are qualified with their package import path, such as "github.com/org/pkg.Service",
+// so types with the same name in different packages get distinct names. Pointers, slices, arrays, maps,
+// and channels are qualified recursively, such as "*github.com/org/pkg.Service" or "map[string]github.com/org/pkg.Service".
+// Generic types are named after their instantiation, such as "github.com/org/pkg.Box[github.com/org/pkg.Service]".
+// Unnamed function, struct, and interface types are qualified recursively too, through their parameters and results,
+// fields, and method signatures, such as "func(github.com/org/pkg.Service) error".
 func NameOfType(t reflect.Type) string {
-	var pkgPath string
-	var tName string
-
-	if t.Kind() == reflect.Ptr {
-		pkgPath = t.Elem().PkgPath()
-		tName = t.Elem().Name()
-	} else {
-		pkgPath = t.PkgPath()
-		tName = t.Name()
+	if t.Name() != "" {
+		if t.PkgPath() == "" {
+			// Predeclared types, such as int or error
+			return t.Name()
+		}
+		return fmt.Sprintf("%s.%s", t.PkgPath(), t.Name())
 	}
 
-	if tName == "" {
+	switch t.Kind() {
+	case reflect.Ptr:
+		return "*" + NameOfType(t.Elem())
+	case reflect.Slice:
+		return "[]" + NameOfType(t.Elem())
+	case reflect.Array:
+		return fmt.Sprintf("[%d]%s", t.Len(), NameOfType(t.Elem()))
+	case reflect.Map:
+		return fmt.Sprintf("map[%s]%s", NameOfType(t.Key()), NameOfType(t.Elem()))
+	case reflect.Chan:
+		switch t.ChanDir() {
+		case reflect.RecvDir:
+			return "<-chan " + NameOfType(t.Elem())
+		case reflect.SendDir:
+			return "chan<- " + NameOfType(t.Elem())
+		default:
+			return "chan " + NameOfType(t.Elem())
+		}
+	case reflect.Func:
+		return "func" + signatureName(t)
+	case reflect.Struct:
+		return structName(t)
+	case reflect.Interface:
+		return interfaceName(t)
+	default:
 		return t.String()
 	}
+}
+
+// signatureName returns the qualified parameters and results of the given function type, such as "(int, ...string) error".
+func signatureName(t reflect.Type) string {
+	params := make([]string, t.NumIn())
+	for i := range params {
+		if t.IsVariadic() && i == len(params)-1 {
+			params[i] = "..." + NameOfType(t.In(i).Elem())
+			continue
+		}
+		params[i] = NameOfType(t.In(i))
+	}
+	name := "(" + strings.Join(params, ", ") + ")"
+
+	results := make([]string, t.NumOut())
+	for i := range results {
+		results[i] = NameOfType(t.Out(i))
+	}
+	switch len(results) {
+	case 0:
+		return name
+	case 1:
+		return name + " " + results[0]
+	default:
+		return name + " (" + strings.Join(results, ", ") + ")"
+	}
+}
 
-	if pkgPath == "" {
-		return tName
+// structName returns the name of the given unnamed struct type, with the qualified types of its fields
+// and the qualified names of its unexported fields.
+func structName(t reflect.Type) string {
+	if t.NumField() == 0 {
+		return "struct {}"
+	}
+	fields := make([]string, t.NumField())
+	for i := range fields {
+		field := t.Field(i)
+		fields[i] = NameOfType(field.Type)
+		if !field.Anonymous {
+			name := field.Name
+			if field.PkgPath != "" {
+				// Unexported fields belong to their package, so same-shaped structs of different packages are distinct
+				name = field.PkgPath + "." + name
+			}
+			fields[i] = name + " " + fields[i]
+		}
+		if field.Tag != "" {
+			fields[i] += " " + strconv.Quote(string(field.Tag))
+		}
 	}
+	return "struct { " + strings.Join(fields, "; ") + " }"
+}
 
-	return fmt.Sprintf("%s/%s", pkgPath, tName)
+// interfaceName returns the name of the given unnamed interface type, with the qualified signatures of its methods
+// and the qualified names of its unexported methods.
+func interfaceName(t reflect.Type) string {
+	if t.NumMethod() == 0 {
+		return "interface {}"
+	}
+	methods := make([]string, t.NumMethod())
+	for i := range methods {
+		method := t.Method(i)
+		methods[i] = method.Name + signatureName(method.Type)
+		if method.PkgPath != "" {
+			methods[i] = method.PkgPath + "." + methods[i]
+		}
+	}
+	return "interface { " + strings.Join(methods, "; ") + " }"
 }
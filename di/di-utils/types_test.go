@@ -3,6 +3,9 @@ package diutils
 import (
 	"reflect"
 	"testing"
+
+	one "github.com/lcrux/go-di/di/di-utils/internal/fixtures/one/svc"
+	two "github.com/lcrux/go-di/di/di-utils/internal/fixtures/two/svc"
 )
 
 type sample struct{}
@@ -26,8 +29,8 @@ func TestTypeOf_Alias(t *testing.T) {
 
 func TestNameOf_CustomType(t *testing.T) {
 	got := NameOf[sample]()
-	if got != "github.com/lcrux/go-di/di/di-utils/sample" {
-		t.Fatalf("expected github.com/lcrux/go-di/di/di-utils/sample, got %s", got)
+	if got != "github.com/lcrux/go-di/di/di-utils.sample" {
+		t.Fatalf("expected github.com/lcrux/go-di/di/di-utils.sample, got %s", got)
 	}
 }
 
@@ -37,3 +40,71 @@ func TestNameOf_Builtin(t *testing.T) {
 		t.Fatalf("expected int, got %s", got)
 	}
 }
+
+type box[T any] struct {
+	value T
+}
+
+func TestNameOf_CompositeTypes(t *testing.T) {
+	const sampleName = "github.com/lcrux/go-di/di/di-utils.sample"
+
+	cases := []struct {
+		got      string
+		expected string
+	}{
+		{NameOf[*sample](), "*" + sampleName},
+		{NameOf[[]*sample](), "[]*" + sampleName},
+		{NameOf[[2]sample](), "[2]" + sampleName},
+		{NameOf[map[string]sample](), "map[string]" + sampleName},
+		{NameOf[<-chan sample](), "<-chan " + sampleName},
+		{NameOf[box[*sample]](), "github.com/lcrux/go-di/di/di-utils.box[*" + sampleName + "]"},
+		{NameOf[error](), "error"},
+		{NameOf[func(sample) error](), "func(" + sampleName + ") error"},
+		{NameOf[func(int, ...*sample) (sample, error)](), "func(int, ...*" + sampleName + ") (" + sampleName + ", error)"},
+		{NameOf[struct {
+			sample
+			Value *sample `json:"value"`
+		}](), "struct { " + sampleName + "; Value *" + sampleName + " \"json:\\\"value\\\"\" }"},
+		{NameOf[interface{ Get() sample }](), "interface { Get() " + sampleName + " }"},
+		{NameOf[interface{}](), "interface {}"},
+	}
+	for _, c := range cases {
+		if c.got != c.expected {
+			t.Fatalf("expected %s, got %s", c.expected, c.got)
+		}
+	}
+}
+
+func TestNameOf_DistinguishesPointerAndValue(t *testing.T) {
+	if NameOf[sample]() == NameOf[*sample]() {
+		t.Fatal("expected pointer and value types to have distinct names")
+	}
+}
+
+func TestNameOf_UnnamedTypesQualifiedByImportPath(t *testing.T) {
+	cases := []struct {
+		one string
+		two string
+	}{
+		{NameOf[func(one.Service)](), NameOf[func(two.Service)]()},
+		{NameOf[func() *one.Service](), NameOf[func() *two.Service]()},
+		{NameOf[struct{ Service one.Service }](), NameOf[struct{ Service two.Service }]()},
+		{NameOf[interface{ Get() one.Service }](), NameOf[interface{ Get() two.Service }]()},
+		{NameOf[one.Counter](), NameOf[two.Counter]()},
+	}
+	for _, c := range cases {
+		if c.one == c.two {
+			t.Fatalf("expected types of same-named packages to have distinct names, got %s", c.one)
+		}
+	}
+
+	expected := "func(github.com/lcrux/go-di/di/di-utils/internal/fixtures/one/svc.Service)"
+	if got := NameOf[func(one.Service)](); got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+
+	expected = "struct { github.com/lcrux/go-di/di/di-utils/internal/fixtures/one/svc.count int }"
+	if got := NameOf[one.Counter](); got != expected {
+		t.Fatalf("expected %s, got %s", expected, got)
+	}
+}
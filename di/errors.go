@@ -0,0 +1,151 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrNotRegistered is returned when a service, or one of its dependencies, is not registered.
+	ErrNotRegistered = errors.New("service not registered")
+	// ErrCircularDependency is returned when the dependencies of a service form a cycle.
+	ErrCircularDependency = errors.New("circular dependency detected")
+	// ErrTypeMismatch is returned when a resolved instance is not of the requested type.
+	ErrTypeMismatch = errors.New("type mismatch")
+	// ErrNilContainer is returned when a nil container is passed to a helper function.
+	ErrNilContainer = errors.New("container cannot be nil")
+	// ErrFrozen is returned when registering a service with a frozen container.
+	ErrFrozen = errors.New("container is frozen")
+	// ErrScopeRequired is returned by containers created with WithStrictScopes
+	// when a Scoped service is resolved within the background context.
+	ErrScopeRequired = errors.New("scoped service requires a lifecycle context")
+	// ErrMaxResolutionDepth is returned when resolutions nested by Lazy dependencies or providers
+	// exceed the maximum resolution depth, see WithMaxResolutionDepth.
+	ErrMaxResolutionDepth = errors.New("maximum resolution depth exceeded")
+	// ErrConstructionTimeout is returned when a factory function does not return within the construction timeout,
+	// see WithConstructionTimeout.
+	ErrConstructionTimeout = errors.New("construction timed out")
+	// ErrCaptiveDependency is returned by ValidateStrict when a Singleton service depends on a Scoped service,
+	// which would be captured by the singleton beyond the lifecycle context it belongs to.
+	ErrCaptiveDependency = errors.New("singleton depends on a scoped service")
+	// ErrShuttingDown is returned when a resolution starts while the container is shutting down.
+	ErrShuttingDown = errors.New("container shutting down")
+	// ErrContainerClosed is returned when resolving or registering a service with a container closed by ShutdownFinal.
+	ErrContainerClosed = errors.New("container closed")
+)
+
+// NotRegisteredError is returned when a service is not registered, it matches ErrNotRegistered with errors.Is.
+type NotRegisteredError struct {
+	Key string // Key of the missing service
+}
+
+func (e *NotRegisteredError) Error() string {
+	return fmt.Sprintf("service with key '%s' not registered", e.Key)
+}
+
+// Is reports whether the target is ErrNotRegistered.
+func (e *NotRegisteredError) Is(target error) bool {
+	return target == ErrNotRegistered
+}
+
+// CircularDependencyError is returned when the dependencies of a service form a cycle,
+// it matches ErrCircularDependency with errors.Is.
+type CircularDependencyError struct {
+	Path []string // Services forming the cycle, the first and last elements are the same service
+}
+
+func (e *CircularDependencyError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrCircularDependency, strings.Join(e.Path, " -> "))
+}
+
+// Is reports whether the target is ErrCircularDependency.
+func (e *CircularDependencyError) Is(target error) bool {
+	return target == ErrCircularDependency
+}
+
+// dependencyError is returned when a dependency fails to resolve, it records the key of the failing dependency,
+// so the chain of services leading to it can be reported.
+type dependencyError struct {
+	key string
+	err error
+}
+
+func (e *dependencyError) Error() string {
+	return fmt.Sprintf("failed to resolve dependency %s: %v", e.key, e.err)
+}
+
+func (e *dependencyError) Unwrap() error {
+	return e.err
+}
+
+// ShutdownCause is an error encountered while shutting down a lifecycle context.
+type ShutdownCause struct {
+	ContextID string // ID of the lifecycle context the error comes from, empty for container level errors
+	Err       error  // Error encountered during the shutdown
+}
+
+// ShutdownError is returned when errors are encountered while shutting down lifecycle contexts.
+//
+// It supports errors.Is and errors.As through Unwrap, which returns the errors of all its causes.
+type ShutdownError struct {
+	Causes []ShutdownCause
+}
+
+// newShutdownError creates a ShutdownError for the given errors of a lifecycle context.
+// It returns nil if there are no errors, so the result can be returned as is.
+func newShutdownError(contextID string, errs []error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	shutdownErr := &ShutdownError{}
+	for _, err := range errs {
+		shutdownErr.add(contextID, err)
+	}
+	return shutdownErr
+}
+
+// add appends the given error to the causes, flattening the causes of a nested ShutdownError.
+func (e *ShutdownError) add(contextID string, err error) {
+	var nested *ShutdownError
+	if errors.As(err, &nested) {
+		e.Causes = append(e.Causes, nested.Causes...)
+		return
+	}
+	e.Causes = append(e.Causes, ShutdownCause{ContextID: contextID, Err: err})
+}
+
+// Error returns a message listing all the causes with the ID of their lifecycle context.
+func (e *ShutdownError) Error() string {
+	messages := make([]string, len(e.Causes))
+	for i, cause := range e.Causes {
+		if cause.ContextID == "" {
+			messages[i] = cause.Err.Error()
+		} else {
+			messages[i] = fmt.Sprintf("[Context ID: %s] %v", cause.ContextID, cause.Err)
+		}
+	}
+	return fmt.Sprintf("shutdown failed with %d error(s): %s", len(e.Causes), strings.Join(messages, "; "))
+}
+
+// Unwrap returns the errors of all the causes.
+func (e *ShutdownError) Unwrap() []error {
+	errs := make([]error, len(e.Causes))
+	for i, cause := range e.Causes {
+		errs[i] = cause.Err
+	}
+	return errs
+}
+
+// ShutdownErrors returns the errors of the causes of the given shutdown error, for callers who need a slice.
+// It returns nil if err is nil, and a slice containing only err if it is not a ShutdownError.
+func ShutdownErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+	var shutdownErr *ShutdownError
+	if errors.As(err, &shutdownErr) {
+		return shutdownErr.Unwrap()
+	}
+	return []error{err}
+}
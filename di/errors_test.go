@@ -0,0 +1,98 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+var errDisposeFailed = errors.New("dispose failed")
+
+type listenerSentinelErr struct{}
+
+func (l *listenerSentinelErr) EndLifecycle(_ ...context.Context) error {
+	return errDisposeFailed
+}
+
+func TestShutdownError_ContainerShutdown(t *testing.T) {
+	c := NewContainer()
+	ctx1 := c.NewContext()
+	ctx2 := c.NewContext()
+
+	if err := Register[*listenerSentinelErr](c, Scoped, func() *listenerSentinelErr {
+		return &listenerSentinelErr{}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	for _, ctx := range []LifecycleContext{ctx1, ctx2} {
+		if _, err := Resolve[*listenerSentinelErr](c, ctx); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+	}
+
+	err := c.Shutdown()
+
+	var shutdownErr *ShutdownError
+	if !errors.As(err, &shutdownErr) {
+		t.Fatalf("expected a *ShutdownError, got %T: %v", err, err)
+	}
+	if len(shutdownErr.Causes) != 2 {
+		t.Fatalf("expected 2 causes, got %d", len(shutdownErr.Causes))
+	}
+	contextIDs := map[string]bool{}
+	for _, cause := range shutdownErr.Causes {
+		contextIDs[cause.ContextID] = true
+		if !errors.Is(cause.Err, errDisposeFailed) {
+			t.Fatalf("expected cause to wrap the EndLifecycle error, got: %v", cause.Err)
+		}
+	}
+	if !contextIDs[ctx1.ID()] || !contextIDs[ctx2.ID()] {
+		t.Fatalf("expected causes to reference both contexts, got %v", contextIDs)
+	}
+	if !errors.Is(err, errDisposeFailed) {
+		t.Fatal("expected errors.Is to find the EndLifecycle error")
+	}
+}
+
+func TestShutdownError_RemoveContext(t *testing.T) {
+	c := NewContainer()
+	ctx := c.NewContext()
+
+	if err := Register[*listenerSentinelErr](c, Scoped, func() *listenerSentinelErr {
+		return &listenerSentinelErr{}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Resolve[*listenerSentinelErr](c, ctx); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	err := c.RemoveContext(ctx)
+
+	var shutdownErr *ShutdownError
+	if !errors.As(err, &shutdownErr) {
+		t.Fatalf("expected a *ShutdownError, got %T: %v", err, err)
+	}
+	if len(shutdownErr.Causes) != 1 || shutdownErr.Causes[0].ContextID != ctx.ID() {
+		t.Fatalf("expected one cause from context %s, got %v", ctx.ID(), shutdownErr.Causes)
+	}
+}
+
+func TestShutdownError_NilWithoutErrors(t *testing.T) {
+	c := NewContainer()
+
+	if err := c.Shutdown(); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if errs := ShutdownErrors(nil); errs != nil {
+		t.Fatalf("expected no errors, got %v", errs)
+	}
+}
+
+func TestShutdownErrors_PlainError(t *testing.T) {
+	errs := ShutdownErrors(errDisposeFailed)
+
+	if len(errs) != 1 || errs[0] != errDisposeFailed {
+		t.Fatalf("expected the error itself, got %v", errs)
+	}
+}
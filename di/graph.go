@@ -0,0 +1,187 @@
+package di
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// dependencyKey returns the registry key of the service the given parameter of the entry depends on.
+// Lazy dependencies and providers depend on the service they resolve, and Named dependencies on their qualified service.
+func (c *containerImpl) dependencyKey(entry *containerEntry, i int) string {
+	paramType := entry.factoryFnParams[i]
+	depKey := entry.factoryFnParamKeys[i]
+	if isLazyDependency(paramType) {
+		return lazyTargetKey(paramType)
+	}
+	if isNamedDependency(paramType) {
+		_, targetKey := namedTarget(paramType)
+		return targetKey
+	}
+	if _, ok := c.registry.Get(depKey); !ok && isProviderDependency(paramType) {
+		return providerTargetKey(paramType)
+	}
+	return depKey
+}
+
+// dependencyKeys returns the registry keys of the services the given parameter of the entry depends on,
+// which are the keys of its fields for an In parameter struct, the keys of the services assignable to its element type
+// for a slice dependency, and the key returned by dependencyKey otherwise.
+func (c *containerImpl) dependencyKeys(entry *containerEntry, i int) []string {
+	if isInDependency(entry.factoryFnParams[i]) {
+		return c.newInEntry(entry.factoryFnParams[i], entry.factoryFnParamKeys[i]).factoryFnParamKeys
+	}
+	if c.isSliceDependency(entry.factoryFnParams[i], entry.factoryFnParamKeys[i]) {
+		return c.sliceElementKeys(entry.factoryFnParams[i])
+	}
+	return []string{c.dependencyKey(entry, i)}
+}
+
+// DependencyGraph returns the dependency graph of the registered services as an adjacency list.
+// Each registered key, including aliases, is mapped to the keys of the services its factory function depends on.
+func (c *containerImpl) DependencyGraph() map[string][]string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	graph := make(map[string][]string)
+	for _, key := range c.registry.Keys() {
+		entry, exists := c.registry.Get(key)
+		if !exists {
+			continue
+		}
+		deps := make([]string, 0, len(entry.factoryFnParamKeys))
+		for i := range entry.factoryFnParamKeys {
+			deps = append(deps, c.dependencyKeys(entry, i)...)
+		}
+		graph[key] = deps
+	}
+	return graph
+}
+
+// DependencyGraphDOT returns the dependency graph of the registered services in the Graphviz DOT format.
+//
+// Each registered key is rendered as a node labeled with its service type and scope, with directed edges
+// to the services its factory function depends on. The Container and LifecycleContext dependencies,
+// and the custom special types, are rendered as dashed nodes, and dependency cycles are rendered as is.
+func (c *containerImpl) DependencyGraphDOT() (string, error) {
+	graph := c.DependencyGraph()
+
+	keys := make([]string, 0, len(graph))
+	for key := range graph {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("digraph dependencies {\n")
+
+	specials := make(map[string]bool)
+	for _, key := range keys {
+		entry, exists := c.registry.Get(key)
+		if !exists {
+			continue
+		}
+		fmt.Fprintf(&sb, "\t%q [label=%q];\n", key, fmt.Sprintf("%s\n%s", entry.serviceType.String(), entry.scope))
+		for _, depKey := range graph[key] {
+			if c.isSpecial(depKey) {
+				specials[depKey] = true
+			}
+			fmt.Fprintf(&sb, "\t%q -> %q;\n", key, depKey)
+		}
+	}
+
+	specialKeys := make([]string, 0, len(specials))
+	for key := range specials {
+		specialKeys = append(specialKeys, key)
+	}
+	sort.Strings(specialKeys)
+	for _, key := range specialKeys {
+		fmt.Fprintf(&sb, "\t%q [shape=box, style=dashed];\n", key)
+	}
+
+	sb.WriteString("}\n")
+	return sb.String(), nil
+}
+
+// Describe returns a human-readable listing of the registrations of the container, for debugging.
+//
+// Each registered service is listed under its key, sorted by key, with its service type, lifecycle scope
+// and the keys of the services its factory function depends on, followed by the aliases and the groups.
+// The built-in logger is not listed. Unlike DependencyGraphDOT, the output is not meant to be parsed.
+func (c *containerImpl) Describe() string {
+	type service struct {
+		key         string
+		serviceType string
+		scope       LifecycleScope
+		deps        []string
+	}
+
+	// Snapshot the registrations under the read lock, and format them once it is released
+	c.mutex.RLock()
+	var services []service
+	aliases := make(map[string]string)
+	for _, key := range c.registry.Keys() {
+		entry, exists := c.registry.Get(key)
+		if !exists || key == loggerReflectedKey || strings.HasPrefix(key, groupKeyPrefix) {
+			continue
+		}
+		if key != entry.key {
+			aliases[key] = entry.key
+			continue
+		}
+		s := service{key: key, serviceType: entry.serviceType.String(), scope: entry.scope}
+		for i := range entry.factoryFnParamKeys {
+			s.deps = append(s.deps, c.dependencyKeys(entry, i)...)
+		}
+		services = append(services, s)
+	}
+	groups := make(map[string][]service, len(c.groups))
+	for group, members := range c.groups {
+		for _, member := range members {
+			groups[group] = append(groups[group], service{key: member.key, serviceType: member.serviceType.String(), scope: member.scope})
+		}
+	}
+	c.mutex.RUnlock()
+
+	sort.Slice(services, func(i, j int) bool {
+		return services[i].key < services[j].key
+	})
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Services (%d):\n", len(services))
+	for _, s := range services {
+		fmt.Fprintf(&sb, "  %s [%s, %s]\n", s.key, s.serviceType, s.scope)
+		for _, dep := range s.deps {
+			fmt.Fprintf(&sb, "    -> %s\n", dep)
+		}
+	}
+
+	if len(aliases) > 0 {
+		aliasKeys := make([]string, 0, len(aliases))
+		for alias := range aliases {
+			aliasKeys = append(aliasKeys, alias)
+		}
+		sort.Strings(aliasKeys)
+		fmt.Fprintf(&sb, "Aliases (%d):\n", len(aliasKeys))
+		for _, alias := range aliasKeys {
+			fmt.Fprintf(&sb, "  %s => %s\n", alias, aliases[alias])
+		}
+	}
+
+	if len(groups) > 0 {
+		groupNames := make([]string, 0, len(groups))
+		for group := range groups {
+			groupNames = append(groupNames, group)
+		}
+		sort.Strings(groupNames)
+		fmt.Fprintf(&sb, "Groups (%d):\n", len(groupNames))
+		for _, group := range groupNames {
+			fmt.Fprintf(&sb, "  %s:\n", group)
+			// Members are listed in their resolution order
+			for _, member := range groups[group] {
+				fmt.Fprintf(&sb, "    %s [%s, %s]\n", member.key, member.serviceType, member.scope)
+			}
+		}
+	}
+	return sb.String()
+}
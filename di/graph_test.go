@@ -0,0 +1,103 @@
+package di
+
+import (
+	"strings"
+	"testing"
+
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+func TestContainer_DependencyGraph(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Scoped, func(a *depA, b *depB) *depC { return &depC{a: a, b: b} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	graph := c.DependencyGraph()
+	deps := graph[diutils.NameOf[*depC]()]
+	if len(deps) != 2 || deps[0] != diutils.NameOf[*depA]() || deps[1] != diutils.NameOf[*depB]() {
+		t.Fatalf("expected depC to depend on depA and depB, got %v", deps)
+	}
+	if deps, ok := graph[diutils.NameOf[*depA]()]; !ok || len(deps) != 0 {
+		t.Fatalf("expected depA without dependencies, got %v", deps)
+	}
+}
+
+func TestContainer_DependencyGraphDOT(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Singleton, func(b *depB) *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	// A cycle still renders
+	if err := Register[*depB](c, Transient, func(a *depA, ctx LifecycleContext) *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	dot, err := c.DependencyGraphDOT()
+	if err != nil {
+		t.Fatalf("unexpected graph error: %v", err)
+	}
+
+	keyA, keyB := diutils.NameOf[*depA](), diutils.NameOf[*depB]()
+	expected := []string{
+		"digraph dependencies {",
+		`"` + keyA + `" [label="*di.depA\nSingleton"];`,
+		`"` + keyB + `" [label="*di.depB\nTransient"];`,
+		`"` + keyA + `" -> "` + keyB + `";`,
+		`"` + keyB + `" -> "` + keyA + `";`,
+		`"` + lifecycleContextReflectedKey + `" [shape=box, style=dashed];`,
+	}
+	for _, line := range expected {
+		if !strings.Contains(dot, line) {
+			t.Fatalf("expected %s in the DOT output, got:\n%s", line, dot)
+		}
+	}
+}
+
+func TestContainer_Describe(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Scoped, func(a *depA, b *depB) *depC { return &depC{a: a, b: b} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.RegisterAlias("primary", diutils.NameOf[*depA]()); err != nil {
+		t.Fatalf("unexpected alias error: %v", err)
+	}
+	if err := RegisterToGroup[*depB](c, "handlers", 1, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	description := c.Describe()
+
+	keyA, keyB, keyC := diutils.NameOf[*depA](), diutils.NameOf[*depB](), diutils.NameOf[*depC]()
+	expected := []string{
+		"Services (3):\n",
+		"  " + keyA + " [*di.depA, Singleton]\n",
+		"  " + keyB + " [*di.depB, Transient]\n",
+		"  " + keyC + " [*di.depC, Scoped]\n    -> " + keyA + "\n    -> " + keyB + "\n",
+		"Aliases (1):\n  primary => " + keyA + "\n",
+		"Groups (1):\n  handlers:\n    " + groupMemberKey("handlers", 0) + " [*di.depB, Transient]\n",
+	}
+	for _, part := range expected {
+		if !strings.Contains(description, part) {
+			t.Fatalf("expected %q in the description, got:\n%s", part, description)
+		}
+	}
+	if strings.Contains(description, loggerReflectedKey) {
+		t.Fatalf("expected the built-in logger not to be described, got:\n%s", description)
+	}
+	if strings.Index(description, keyA) > strings.Index(description, keyC) {
+		t.Fatalf("expected the services to be sorted by key, got:\n%s", description)
+	}
+}
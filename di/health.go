@@ -0,0 +1,103 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// healthPollInterval is the time WaitForReady waits before checking an unhealthy service again.
+const healthPollInterval = 20 * time.Millisecond
+
+// HealthChecker is implemented by services becoming ready asynchronously, such as a database client
+// connecting in the background. Healthy returns nil once the service is ready to be used.
+type HealthChecker interface {
+	Healthy(ctx context.Context) error
+}
+
+// WaitForReady resolves every Singleton service, creating those not created yet, then waits until the singletons
+// implementing HealthChecker report healthy, checking them concurrently and again every healthPollInterval.
+// If the context is nil, a background context is used.
+//
+// It returns nil once all the singletons are healthy, or an error joining the resolution failures and,
+// once the context is canceled, the services still unhealthy along with their last health error, sorted by key.
+func (c *containerImpl) WaitForReady(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	keys := c.registry.Keys()
+	sort.Strings(keys)
+
+	// Failures are collected per key, so they are reported in key order whichever check completes first
+	failures := make(map[string]error)
+	checkers := make(map[string]HealthChecker)
+	for _, key := range keys {
+		entry, exists := c.registry.Get(key)
+		// Aliases share the instance of their target, which is checked under its own key
+		if !exists || entry.key != key || entry.scope != Singleton {
+			continue
+		}
+		instance, err := c.Resolve(key, nil)
+		if err != nil {
+			failures[key] = fmt.Errorf("failed to resolve singleton %s: %w", key, err)
+			continue
+		}
+		if checker, ok := instance.(HealthChecker); ok {
+			checkers[key] = checker
+		}
+	}
+
+	var failuresMutex sync.Mutex
+	wg := sync.WaitGroup{}
+	for key, checker := range checkers {
+		wg.Add(1)
+		go func(key string, checker HealthChecker) {
+			defer wg.Done()
+			if err := waitHealthy(ctx, checker); err != nil {
+				failuresMutex.Lock()
+				failures[key] = fmt.Errorf("singleton %s is not healthy: %w", key, err)
+				failuresMutex.Unlock()
+			}
+		}(key, checker)
+	}
+	wg.Wait()
+
+	if len(failures) == 0 {
+		c.logger.Debugf("All singletons are ready")
+		return nil
+	}
+
+	failedKeys := make([]string, 0, len(failures))
+	for key := range failures {
+		failedKeys = append(failedKeys, key)
+	}
+	sort.Strings(failedKeys)
+	errs := make([]error, 0, len(failedKeys))
+	for _, key := range failedKeys {
+		errs = append(errs, failures[key])
+	}
+	return errors.Join(errs...)
+}
+
+// waitHealthy checks the given service until it reports healthy or the context is canceled.
+// It returns nil once the service is healthy, or an error wrapping the context error and the last health error.
+func waitHealthy(ctx context.Context, checker HealthChecker) error {
+	ticker := time.NewTicker(healthPollInterval)
+	defer ticker.Stop()
+
+	for {
+		err := checker.Healthy(ctx)
+		if err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("%w: %w", ctx.Err(), err)
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// delayedService becomes healthy once its ready time has passed.
+type delayedService struct {
+	readyAt time.Time
+	checks  *int32
+}
+
+func (s *delayedService) Healthy(context.Context) error {
+	atomic.AddInt32(s.checks, 1)
+	if time.Now().Before(s.readyAt) {
+		return errors.New("still connecting")
+	}
+	return nil
+}
+
+// unhealthyService never becomes healthy.
+type unhealthyService struct{}
+
+func (unhealthyService) Healthy(context.Context) error {
+	return errors.New("connection refused")
+}
+
+func TestContainer_WaitForReady(t *testing.T) {
+	c := NewContainer()
+	checks := int32(0)
+	created := int32(0)
+	if err := Register[*delayedService](c, Singleton, func() *delayedService {
+		atomic.AddInt32(&created, 1)
+		return &delayedService{readyAt: time.Now().Add(50 * time.Millisecond), checks: &checks}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := c.WaitForReady(ctx); err != nil {
+		t.Fatalf("unexpected wait error: %v", err)
+	}
+	if atomic.LoadInt32(&created) != 1 {
+		t.Fatalf("expected the singleton to be created once, got %d", created)
+	}
+	if atomic.LoadInt32(&checks) < 2 {
+		t.Fatalf("expected the service to be checked until healthy, got %d checks", checks)
+	}
+	if !c.BackgroundContext().Has(KeyOf[*depA]()) {
+		t.Fatal("expected every singleton to be created")
+	}
+}
+
+func TestContainer_WaitForReady_Timeout(t *testing.T) {
+	c := NewContainer()
+	checks := int32(0)
+	if err := Register[*delayedService](c, Singleton, func() *delayedService {
+		return &delayedService{readyAt: time.Now().Add(20 * time.Millisecond), checks: &checks}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*unhealthyService](c, Singleton, func() *unhealthyService { return &unhealthyService{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	err := c.WaitForReady(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected a deadline exceeded error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), KeyOf[*unhealthyService]()) || !strings.Contains(err.Error(), "connection refused") {
+		t.Fatalf("expected the unhealthy service and its last error to be reported, got %v", err)
+	}
+	if strings.Contains(err.Error(), KeyOf[*delayedService]()) {
+		t.Fatalf("expected the service that became healthy not to be reported, got %v", err)
+	}
+}
+
+func TestContainer_WaitForReady_ResolutionError(t *testing.T) {
+	c := NewContainer()
+	if err := Register[*depC](c, Singleton, func(a *depA, b *depB) *depC { return &depC{a: a, b: b} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if err := c.WaitForReady(context.Background()); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected the resolution error to be returned, got %v", err)
+	}
+}
+
+func TestContainer_WaitForReady_FailuresSortedByKey(t *testing.T) {
+	c := NewContainer()
+	keys := []string{"svc.d", "svc.b", "svc.a", "svc.c"}
+	for _, key := range keys {
+		if err := RegisterWithKey[*unhealthyService](c, key, Singleton, func() *unhealthyService { return &unhealthyService{} }); err != nil {
+			t.Fatalf("unexpected register error: %v", err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+	err := c.WaitForReady(ctx)
+	joined, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatalf("expected joined errors, got %v", err)
+	}
+
+	errs := joined.Unwrap()
+	expected := []string{"svc.a", "svc.b", "svc.c", "svc.d"}
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %v", len(expected), errs)
+	}
+	for i, key := range expected {
+		if !strings.Contains(errs[i].Error(), "singleton "+key+" ") {
+			t.Fatalf("expected error %d to report %s, got %v", i, key, errs[i])
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package di
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConflictPolicy defines how ImportFrom handles a registration whose key is already registered in the container.
+type ConflictPolicy int
+
+const (
+	// SkipExisting keeps the existing registration and ignores the imported one.
+	SkipExisting ConflictPolicy = iota
+	// Overwrite replaces the existing registration with the imported one.
+	Overwrite
+	// Error fails the import, without importing any registration.
+	Error
+)
+
+// ImportFrom copies the registrations, aliases, and group members of the other container into the container.
+//
+// Only the registrations are imported, each container keeps creating its own instances. Conflicting keys are handled
+// according to the given policy, an overwritten Singleton instance already created is removed from the background context,
+// so the imported registration is used on the next resolution. Group members are appended to the groups of the container.
+// It returns an error if the other container is not created by NewContainer, or if the container is frozen.
+func (c *containerImpl) ImportFrom(other Container, onConflict ConflictPolicy) error {
+	source, ok := other.(*containerImpl)
+	if scoped, isScoped := other.(*scopedContainer); isScoped {
+		// The container injected into a factory function resolved within a lifecycle context
+		source, ok = scoped.containerImpl, true
+	}
+	if !ok || source == nil {
+		return fmt.Errorf("cannot import from container of type %T", other)
+	}
+	if source == c {
+		return fmt.Errorf("cannot import a container into itself")
+	}
+
+	overwritten, err := c.importFrom(source, onConflict)
+	if err != nil {
+		return err
+	}
+
+	// Remove the singletons created from the overwritten registrations, once the container lock is released
+	bgCtx := c.BackgroundContext()
+	for _, key := range overwritten {
+		if err := bgCtx.RemoveInstance(key); err != nil {
+			c.logger.Warnf("Failed to remove instance of overwritten service %s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+// importSnapshot holds the registrations of a container copied by ImportFrom.
+type importSnapshot struct {
+	services []*containerEntry            // Copies of the service entries, sorted by key
+	aliases  map[string]string            // Target key of each alias
+	groups   map[string][]*containerEntry // Copies of the members of each group, sorted by their order
+}
+
+// snapshotForImport copies the registrations of the container under its read lock, so ImportFrom never
+// holds the locks of both containers, which would deadlock two containers importing from each other.
+func (c *containerImpl) snapshotForImport() importSnapshot {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	snapshot := importSnapshot{aliases: make(map[string]string), groups: make(map[string][]*containerEntry, len(c.groups))}
+	keys := c.registry.Keys()
+	sort.Strings(keys)
+	for _, key := range keys {
+		entry, _ := c.registry.Get(key)
+		switch {
+		case key == loggerReflectedKey || strings.HasPrefix(key, groupKeyPrefix):
+			// The logger is injected by each container, and group members are imported with their group
+			continue
+		case entry.key != key:
+			snapshot.aliases[key] = entry.key
+		default:
+			snapshot.services = append(snapshot.services, entry.clone())
+		}
+	}
+	for group, members := range c.groups {
+		for _, member := range members {
+			snapshot.groups[group] = append(snapshot.groups[group], member.clone())
+		}
+	}
+	return snapshot
+}
+
+// importFrom copies the registrations of the source container, snapshotted before the container lock is taken.
+// It returns the keys of the overwritten registrations.
+func (c *containerImpl) importFrom(source *containerImpl, onConflict ConflictPolicy) ([]string, error) {
+	snapshot := source.snapshotForImport()
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.closed.Load() {
+		return nil, fmt.Errorf("cannot import registrations: %w", ErrContainerClosed)
+	}
+	if c.frozen {
+		return nil, fmt.Errorf("cannot import registrations: %w", ErrFrozen)
+	}
+
+	// Services first, then aliases, so the aliases point to the entries registered in the container
+	aliases := make([]string, 0, len(snapshot.aliases))
+	for key := range snapshot.aliases {
+		aliases = append(aliases, key)
+	}
+	sort.Strings(aliases)
+
+	if onConflict == Error {
+		for _, entry := range snapshot.services {
+			if _, exists := c.registry.Get(entry.key); exists {
+				return nil, fmt.Errorf("cannot import service with key %s: already registered", entry.key)
+			}
+		}
+		for _, key := range aliases {
+			if _, exists := c.registry.Get(key); exists {
+				return nil, fmt.Errorf("cannot import service with key %s: already registered", key)
+			}
+		}
+	}
+
+	overwritten := make([]string, 0)
+	for _, entry := range snapshot.services {
+		if _, exists := c.registry.Get(entry.key); exists {
+			if onConflict == SkipExisting {
+				continue
+			}
+			overwritten = append(overwritten, entry.key)
+		}
+		c.registry.Set(entry.key, entry)
+	}
+	c.repointAliases(overwritten)
+	for _, key := range aliases {
+		if _, exists := c.registry.Get(key); exists && onConflict == SkipExisting {
+			continue
+		}
+		if target, exists := c.registry.Get(snapshot.aliases[key]); exists {
+			c.registry.Set(key, target)
+		}
+	}
+	for group, members := range snapshot.groups {
+		for _, member := range members {
+			c.addGroupMember(group, member)
+		}
+	}
+
+	// New and overwritten entries may change the dependency tree of any service
+	c.invalidateDependencyTrees()
+
+	c.logger.Debugf("Imported registrations, %d services overwritten", len(overwritten))
+	return overwritten, nil
+}
+
+// repointAliases makes the aliases of the container pointing to the given overwritten registrations
+// share the entries that replaced them, as aliases hold the entry of their target rather than its key.
+// It must be called with the container lock held.
+func (c *containerImpl) repointAliases(overwritten []string) {
+	if len(overwritten) == 0 {
+		return
+	}
+	replaced := make(map[string]bool, len(overwritten))
+	for _, key := range overwritten {
+		replaced[key] = true
+	}
+	for _, key := range c.registry.Keys() {
+		entry, exists := c.registry.Get(key)
+		if !exists || entry.key == key || !replaced[entry.key] {
+			continue
+		}
+		if target, exists := c.registry.Get(entry.key); exists {
+			c.registry.Set(key, target)
+		}
+	}
+}
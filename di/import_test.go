@@ -0,0 +1,176 @@
+package di
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+// newImportSource returns a container registering depA, an alias to it, and a group member.
+func newImportSource(t *testing.T) Container {
+	t.Helper()
+	source := NewContainer()
+	if err := Register[*depA](source, Singleton, func() *depA { return &depA{name: "imported"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := source.RegisterAlias("alias-a", diutils.NameOf[*depA]()); err != nil {
+		t.Fatalf("unexpected alias error: %v", err)
+	}
+	if err := RegisterToGroup[*depB](source, "handlers", 0, func() *depB { return &depB{name: "imported"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	return source
+}
+
+func TestImportFrom_ResolvesImportedServices(t *testing.T) {
+	source := newImportSource(t)
+	c := NewContainer()
+	if err := RegisterToGroup[*depB](c, "handlers", 1, func() *depB { return &depB{name: "local"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if err := c.ImportFrom(source, Error); err != nil {
+		t.Fatalf("unexpected import error: %v", err)
+	}
+
+	imported, err := Resolve[*depA](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	viaAlias, err := ResolveWithKey[*depA](c, "alias-a", nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if imported.name != "imported" || viaAlias != imported {
+		t.Fatal("expected the imported alias to resolve the imported singleton")
+	}
+	fromSource, err := Resolve[*depA](source, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if fromSource == imported {
+		t.Fatal("expected each container to create its own singleton")
+	}
+
+	members, err := ResolveGroup[*depB](c, "handlers", nil)
+	if err != nil {
+		t.Fatalf("unexpected group error: %v", err)
+	}
+	if len(members) != 2 || members[0].name != "imported" || members[1].name != "local" {
+		t.Fatalf("expected the imported member to be merged by order, got %d members", len(members))
+	}
+}
+
+func TestImportFrom_ConflictPolicies(t *testing.T) {
+	newTarget := func(t *testing.T) Container {
+		c := NewContainer()
+		if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "local"} }); err != nil {
+			t.Fatalf("unexpected register error: %v", err)
+		}
+		// Create the local singleton, so an overwrite must discard it
+		if _, err := Resolve[*depA](c, nil); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+		return c
+	}
+
+	tests := []struct {
+		policy   ConflictPolicy
+		wantErr  bool
+		wantName string
+	}{
+		{policy: SkipExisting, wantName: "local"},
+		{policy: Overwrite, wantName: "imported"},
+		{policy: Error, wantErr: true, wantName: "local"},
+	}
+	for _, tt := range tests {
+		c := newTarget(t)
+		err := c.ImportFrom(newImportSource(t), tt.policy)
+		if (err != nil) != tt.wantErr {
+			t.Fatalf("policy %d: expected error %t, got: %v", tt.policy, tt.wantErr, err)
+		}
+
+		a, err := Resolve[*depA](c, nil)
+		if err != nil {
+			t.Fatalf("policy %d: unexpected resolve error: %v", tt.policy, err)
+		}
+		if a.name != tt.wantName {
+			t.Fatalf("policy %d: expected %s registration, got %s", tt.policy, tt.wantName, a.name)
+		}
+		if _, err := ResolveWithKey[*depA](c, "alias-a", nil); (err != nil) != tt.wantErr {
+			t.Fatalf("policy %d: expected the alias to be imported only without error, got: %v", tt.policy, err)
+		}
+	}
+}
+
+func TestImportFrom_OverwriteRepointsExistingAliases(t *testing.T) {
+	c := NewContainer()
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "local"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.RegisterAlias("local-alias", KeyOf[*depA]()); err != nil {
+		t.Fatalf("unexpected alias error: %v", err)
+	}
+
+	if err := c.ImportFrom(newImportSource(t), Overwrite); err != nil {
+		t.Fatalf("unexpected import error: %v", err)
+	}
+
+	aliased, err := ResolveWithKey[*depA](c, "local-alias", nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if aliased.name != "imported" {
+		t.Fatalf("expected the alias to resolve the imported registration, got %s", aliased.name)
+	}
+	a, err := Resolve[*depA](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if a != aliased {
+		t.Fatal("expected the alias and its target to share the singleton")
+	}
+}
+
+func TestImportFrom_ConcurrentMutualImports(t *testing.T) {
+	a := NewContainer()
+	b := NewContainer()
+	if err := Register[*depA](a, Transient, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](b, Transient, func() *depB { return &depB{name: "b"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		wg := sync.WaitGroup{}
+		for i := 0; i < 50; i++ {
+			wg.Add(2)
+			go func() {
+				defer wg.Done()
+				_ = a.ImportFrom(b, SkipExisting)
+			}()
+			go func() {
+				defer wg.Done()
+				_ = b.ImportFrom(a, SkipExisting)
+			}()
+		}
+		wg.Wait()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected containers importing from each other not to deadlock")
+	}
+	if _, err := Resolve[*depB](a, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if _, err := Resolve[*depA](b, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+// inType is the reflected type of the In marker.
+var inType = diutils.TypeOf[In]()
+
+// In marks a parameter struct whose fields are the dependencies of a factory function.
+//
+// Constructors with many positional parameters are error-prone, so a factory function can instead take
+// a single struct embedding In, and the container fills each of its exported fields by resolving the field's type,
+// or the key given by its `di:"key"` tag, which selects one of several implementations of an interface:
+//
+//	type ServiceDeps struct {
+//		di.In
+//
+//		Repository *Repository
+//		Logger     dilogger.Logger
+//		Primary    Cache `di:"cache.redis"`
+//		Fallback   Cache `di:"cache.memory" optional:"true"`
+//	}
+//
+//	func NewService(deps ServiceDeps) *Service { ... }
+//
+// The fields are edges of the dependency tree, so they are validated like positional parameters.
+// Fields tagged `di:"-"` are skipped, and a field tagged `optional:"true"` is left to its zero value
+// when its service is not registered.
+// Lazy, provider and Named dependencies must be declared as positional parameters.
+type In struct{}
+
+// inField describes a field of an In parameter struct filled by the container.
+type inField struct {
+	index    int          // The index of the field in the struct
+	name     string       // The name of the field
+	typ      reflect.Type // The type of the field
+	key      string       // The registry key of the service injected into the field
+	optional bool         // Whether the field is left to its zero value when the service is not registered
+}
+
+// isInDependency indicates whether the given factory parameter type is a struct embedding In.
+func isInDependency(paramType reflect.Type) bool {
+	if paramType.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < paramType.NumField(); i++ {
+		if field := paramType.Field(i); field.Anonymous && field.Type == inType {
+			return true
+		}
+	}
+	return false
+}
+
+// inFields returns the fields of the given In parameter struct type filled by the container, in declaration order.
+// The In marker, the unexported fields and the fields tagged `di:"-"` are skipped.
+func inFields(paramType reflect.Type) []inField {
+	fields := make([]inField, 0, paramType.NumField())
+	for i := 0; i < paramType.NumField(); i++ {
+		field := paramType.Field(i)
+		if !field.IsExported() || field.Type == inType {
+			continue
+		}
+		key, skip := fieldKey(field)
+		if skip {
+			continue
+		}
+		fields = append(fields, inField{
+			index:    i,
+			name:     field.Name,
+			typ:      field.Type,
+			key:      key,
+			optional: field.Tag.Get("optional") == "true",
+		})
+	}
+	return fields
+}
+
+// newInEntry creates the dependency tree entry standing for an In parameter struct, depending on the services of its fields.
+// The optional fields whose service is not registered are not dependencies of the entry.
+func (c *containerImpl) newInEntry(paramType reflect.Type, key string) *containerEntry {
+	entry := &containerEntry{
+		serviceType: paramType,
+		key:         key,
+		scope:       Transient,
+		in:          true,
+	}
+	for _, field := range inFields(paramType) {
+		if field.optional && !c.isProvided(field.key) {
+			continue
+		}
+		entry.factoryFnParams = append(entry.factoryFnParams, field.typ)
+		entry.factoryFnParamKeys = append(entry.factoryFnParamKeys, field.key)
+	}
+	return entry
+}
+
+// buildIn returns an In parameter struct of the given type, with each field set to the instance of its service
+// returned by resolve. The optional fields whose service is not registered are left to their zero value.
+func (c *containerImpl) buildIn(paramType reflect.Type, resolve func(key string) (reflect.Value, error)) (reflect.Value, error) {
+	deps := reflect.New(paramType).Elem()
+	for _, field := range inFields(paramType) {
+		if field.optional && !c.isProvided(field.key) {
+			continue
+		}
+		instance, err := resolve(field.key)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !instance.IsValid() {
+			continue
+		}
+		if !instance.Type().AssignableTo(field.typ) {
+			return reflect.Value{}, fmt.Errorf("%w: cannot inject %s with key %s into field %s of %s",
+				ErrTypeMismatch, instance.Type().String(), field.key, field.name, paramType.String())
+		}
+		deps.Field(field.index).Set(instance)
+	}
+	return deps, nil
+}
+
+// isProvided indicates whether the service with the given key is provided by the container,
+// either as a registered service or as a special dependency.
+func (c *containerImpl) isProvided(key string) bool {
+	if c.isSpecial(key) {
+		return true
+	}
+	_, ok := c.registry.Get(key)
+	return ok
+}
@@ -0,0 +1,162 @@
+package di
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+type inDeps struct {
+	In
+
+	A        *depA
+	B        *depB
+	D        *depD `optional:"true"`
+	internal *depA
+}
+
+type inService struct {
+	deps inDeps
+}
+
+func registerInService(t *testing.T, c Container) {
+	t.Helper()
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*inService](c, Transient, func(deps inDeps) *inService {
+		return &inService{deps: deps}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+}
+
+func TestIn_ResolvesFields(t *testing.T) {
+	c := NewContainer()
+	registerInService(t, c)
+	if err := Register[*depB](c, Transient, func() *depB { return &depB{name: "b"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected validate error: %v", err)
+	}
+	svc, err := Resolve[*inService](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if svc.deps.A == nil || svc.deps.A.name != "a" || svc.deps.B == nil || svc.deps.B.name != "b" {
+		t.Fatalf("expected the fields to be resolved, got %+v", svc.deps)
+	}
+	if svc.deps.D != nil {
+		t.Fatalf("expected the unregistered optional field to be nil, got %v", svc.deps.D)
+	}
+	if svc.deps.internal != nil {
+		t.Fatal("expected the unexported field to be skipped")
+	}
+
+	// The optional field is resolved once its service is registered
+	if err := Register[*depC](c, Transient, func(a *depA, b *depB) *depC { return &depC{a: a, b: b} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depD](c, Transient, func(dc *depC) *depD { return &depD{c: dc} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	svc, err = Resolve[*inService](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if svc.deps.D == nil || svc.deps.D.c.a != svc.deps.A {
+		t.Fatalf("expected the optional field to be resolved, got %+v", svc.deps)
+	}
+
+	deps := c.DependencyGraph()[KeyOf[*inService]()]
+	for _, key := range []string{KeyOf[*depA](), KeyOf[*depB](), KeyOf[*depD]()} {
+		if !slices.Contains(deps, key) {
+			t.Fatalf("expected the dependency graph to contain %s, got %v", key, deps)
+		}
+	}
+}
+
+func TestIn_MissingRequiredField(t *testing.T) {
+	c := NewContainer()
+	registerInService(t, c)
+
+	if err := c.Validate(); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected validate to report the missing field, got %v", err)
+	}
+	_, err := Resolve[*inService](c, nil)
+	var notRegistered *NotRegisteredError
+	if !errors.As(err, &notRegistered) || notRegistered.Key != KeyOf[*depB]() {
+		t.Fatalf("expected a not registered error for %s, got %v", KeyOf[*depB](), err)
+	}
+}
+
+type taggedDeps struct {
+	In
+
+	Fast    namedCache `di:"cache.fast"`
+	Slow    namedCache `di:"cache.slow"`
+	Ignored namedCache `di:"-"`
+}
+
+type taggedConsumer struct {
+	fast, slow namedCache
+}
+
+func TestIn_TaggedFields(t *testing.T) {
+	c := NewContainer()
+	registerNamedCaches(t, c)
+	if err := Register[*taggedConsumer](c, Transient, func(deps taggedDeps) *taggedConsumer {
+		if deps.Ignored != nil {
+			t.Errorf("expected the excluded field to be nil, got %v", deps.Ignored)
+		}
+		return &taggedConsumer{fast: deps.Fast, slow: deps.Slow}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected validate error: %v", err)
+	}
+	consumer, err := Resolve[*taggedConsumer](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if consumer.fast.Name() != "memory" || consumer.slow.Name() != "disk" {
+		t.Fatalf("expected the fields to resolve their tagged keys, got %s and %s", consumer.fast.Name(), consumer.slow.Name())
+	}
+}
+
+func TestIn_TaggedFieldErrors(t *testing.T) {
+	c := NewContainer()
+	if err := RegisterWithKey[namedCache](c, "cache.fast", Singleton, func() namedCache { return memoryCache{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*taggedConsumer](c, Transient, func(deps taggedDeps) *taggedConsumer {
+		return &taggedConsumer{fast: deps.Fast, slow: deps.Slow}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if err := c.Validate(); !errors.Is(err, ErrNotRegistered) || !strings.Contains(err.Error(), "cache.slow") {
+		t.Fatalf("expected validate to report the missing tagged key, got %v", err)
+	}
+	_, err := Resolve[*taggedConsumer](c, nil)
+	var notRegistered *NotRegisteredError
+	if !errors.As(err, &notRegistered) || notRegistered.Key != "cache.slow" || !strings.Contains(err.Error(), "Slow") {
+		t.Fatalf("expected a not registered error for the Slow field, got %v", err)
+	}
+
+	// A service of another type registered with the tagged key is a type mismatch
+	if err := RegisterWithKey[*depA](c, "cache.slow", Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.Validate(); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("expected validate to report a type mismatch, got %v", err)
+	}
+	if _, err := Resolve[*taggedConsumer](c, nil); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("expected a type mismatch error, got %v", err)
+	}
+}
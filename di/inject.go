@@ -0,0 +1,131 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+// fieldKey returns the registry key of the service injected into the given struct field, read from its `di` tag,
+// and a boolean indicating whether the field is excluded with the `di:"-"` tag.
+// The key of the field type is used when the field is not tagged.
+func fieldKey(field reflect.StructField) (string, bool) {
+	switch tag := field.Tag.Get("di"); tag {
+	case "-":
+		return "", true
+	case "":
+		return diutils.NameOfType(field.Type), false
+	default:
+		return tag, false
+	}
+}
+
+// InjectInto populates the exported fields of an already constructed struct with services resolved from the container,
+// for example for HTTP handlers created outside the container.
+//
+// Each exported field is resolved by the key of its type, or by the key given by its `di:"key"` tag.
+// Fields tagged `di:"-"` and unexported fields are skipped, and a field tagged `optional:"true"` is left unchanged
+// when its service is not registered. The fields are only assigned once all of them are resolved.
+//
+// Parameters:
+//
+// Container: The container instance from which to resolve the services.
+//
+// Target: A non-nil pointer to the struct to populate.
+//
+// LifecycleContext: The lifecycle context to use for resolving the services. If nil, the container's background context is used.
+func InjectInto(c Container, target interface{}, ctx LifecycleContext) error {
+	if c == nil {
+		return ErrNilContainer
+	}
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Pointer || targetValue.IsNil() || targetValue.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("target must be a non-nil pointer to a struct, got %T", target)
+	}
+
+	structValue := targetValue.Elem()
+	structType := structValue.Type()
+	instances := make(map[int]reflect.Value, structType.NumField())
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		if !field.IsExported() || field.Type == inType {
+			continue
+		}
+		key, skip := fieldKey(field)
+		if skip {
+			continue
+		}
+
+		inst, err := c.Resolve(key, ctx)
+		if err != nil {
+			var notRegistered *NotRegisteredError
+			if field.Tag.Get("optional") == "true" && errors.As(err, &notRegistered) && notRegistered.Key == key {
+				continue
+			}
+			return fmt.Errorf("failed to inject field %s of %s: %w", field.Name, structType.String(), err)
+		}
+		if inst == nil {
+			continue
+		}
+		instance := reflect.ValueOf(inst)
+		if !instance.Type().AssignableTo(field.Type) {
+			return fmt.Errorf("%w: cannot inject %s with key %s into field %s of type %s",
+				ErrTypeMismatch, instance.Type().String(), key, field.Name, field.Type.String())
+		}
+		instances[i] = instance
+	}
+
+	for i, instance := range instances {
+		structValue.Field(i).Set(instance)
+	}
+	return nil
+}
+
+// ResolveInto resolves the service of the type pointed to by each target, and assigns it to the target,
+// so the services needed during wiring are resolved in one call:
+//
+//	var users *UserService
+//	var orders *OrderService
+//	err := di.ResolveInto(container, ctx, &users, &orders)
+//
+// Each target must be a non-nil pointer, and is resolved like ResolveType with the type it points to.
+// The targets are only assigned once all of them are resolved, and the returned error names the index
+// and the type of the failing target.
+//
+// Parameters:
+//
+// Container: The container instance from which to resolve the services.
+//
+// LifecycleContext: The lifecycle context to use for resolving the services. If nil, the container's background context is used.
+//
+// Targets: The non-nil pointers to assign the resolved services to.
+func ResolveInto(c Container, ctx LifecycleContext, targets ...interface{}) error {
+	if c == nil {
+		return ErrNilContainer
+	}
+
+	instances := make([]reflect.Value, len(targets))
+	for i, target := range targets {
+		targetValue := reflect.ValueOf(target)
+		if targetValue.Kind() != reflect.Pointer || targetValue.IsNil() {
+			return fmt.Errorf("target %d must be a non-nil pointer, got %T", i, target)
+		}
+		targetType := targetValue.Type().Elem()
+
+		inst, err := c.ResolveType(targetType, ctx)
+		if err != nil {
+			return fmt.Errorf("failed to resolve target %d of type %s: %w", i, targetType.String(), err)
+		}
+		if inst == nil {
+			return fmt.Errorf("resolved instance is nil for target %d of type %s", i, targetType.String())
+		}
+		instances[i] = reflect.ValueOf(inst)
+	}
+
+	for i, target := range targets {
+		reflect.ValueOf(target).Elem().Set(instances[i])
+	}
+	return nil
+}
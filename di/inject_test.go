@@ -0,0 +1,135 @@
+package di
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type injectTarget struct {
+	A        *depA
+	Keyed    *depB  `di:"depB.secondary"`
+	D        *depD  `optional:"true"`
+	Name     string `di:"-"`
+	internal *depA
+}
+
+func registerInjectDeps(t *testing.T, c Container) {
+	t.Helper()
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func() *depB { return &depB{name: "default"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterWithKey[*depB](c, "depB.secondary", Transient, func() *depB { return &depB{name: "secondary"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+}
+
+func TestInjectInto(t *testing.T) {
+	c := NewContainer()
+	registerInjectDeps(t, c)
+
+	target := &injectTarget{Name: "handler"}
+	if err := InjectInto(c, target, nil); err != nil {
+		t.Fatalf("unexpected inject error: %v", err)
+	}
+	if target.A == nil || target.A.name != "a" {
+		t.Fatalf("expected the field to be resolved by its type, got %v", target.A)
+	}
+	if target.Keyed == nil || target.Keyed.name != "secondary" {
+		t.Fatalf("expected the field to be resolved by its key, got %v", target.Keyed)
+	}
+	if target.D != nil || target.Name != "handler" || target.internal != nil {
+		t.Fatalf("expected the optional, excluded and unexported fields to be unchanged, got %+v", target)
+	}
+	if singleton, _ := Resolve[*depA](c, nil); singleton != target.A {
+		t.Fatal("expected the singleton instance to be injected")
+	}
+}
+
+func TestInjectInto_Errors(t *testing.T) {
+	c := NewContainer()
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	target := &injectTarget{}
+	err := InjectInto(c, target, nil)
+	var notRegistered *NotRegisteredError
+	if !errors.As(err, &notRegistered) || notRegistered.Key != "depB.secondary" {
+		t.Fatalf("expected a not registered error for the keyed field, got %v", err)
+	}
+	if target.A != nil {
+		t.Fatal("expected no field to be assigned when the injection fails")
+	}
+
+	if err := RegisterWithKey[*depA](c, "depB.secondary", Transient, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := InjectInto(c, target, nil); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("expected a type mismatch error, got %v", err)
+	}
+
+	if err := InjectInto(c, injectTarget{}, nil); err == nil {
+		t.Fatal("expected an error for a non-pointer target")
+	}
+	if err := InjectInto(nil, target, nil); !errors.Is(err, ErrNilContainer) {
+		t.Fatalf("expected ErrNilContainer, got %v", err)
+	}
+}
+
+func TestResolveInto(t *testing.T) {
+	c := NewContainer()
+	ctx := c.NewContext()
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Scoped, func() *depB { return &depB{name: "b"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Transient, func(a *depA, b *depB) *depC { return &depC{a: a, b: b} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	var a *depA
+	var b *depB
+	var dc *depC
+	if err := ResolveInto(c, ctx, &a, &b, &dc); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if a == nil || a.name != "a" || b == nil || b.name != "b" || dc == nil {
+		t.Fatalf("expected the targets to be assigned, got %v %v %v", a, b, dc)
+	}
+	if dc.a != a || dc.b != b {
+		t.Fatal("expected the cached instances to be assigned")
+	}
+}
+
+func TestResolveInto_Errors(t *testing.T) {
+	c := NewContainer()
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	var a *depA
+	var d *depD
+	err := ResolveInto(c, nil, &a, &d)
+	if !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected a not registered error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "target 1 of type *di.depD") {
+		t.Fatalf("expected the error to name the failing target, got %v", err)
+	}
+	if a != nil {
+		t.Fatal("expected no target to be assigned when the resolution fails")
+	}
+
+	if err := ResolveInto(c, nil, a); err == nil || !strings.Contains(err.Error(), "target 0") {
+		t.Fatalf("expected an error for a non-pointer target, got %v", err)
+	}
+	if err := ResolveInto(nil, nil, &a); !errors.Is(err, ErrNilContainer) {
+		t.Fatalf("expected ErrNilContainer, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package di
+
+import "fmt"
+
+// RegisterKeyed registers a service of type T with the container under a typed key, such as a constant
+// of an enum type or a struct value, so the compiler checks the keys instead of relying on raw strings.
+//
+// The registry key is derived from the type and the value of the key, as "<key type>:<key value>",
+// so equal values of distinct key types do not collide. ResolveKeyed resolves the service with the same key.
+//
+// Parameters:
+//
+// Container: The container instance in which to register the service.
+//
+// Key: The typed key associated with the service to register.
+//
+// Scope: The lifecycle scope of the service (Transient, Singleton, Scoped).
+//
+// FactoryFn: The factory function used to create instances of the service.
+func RegisterKeyed[K comparable, T any](c Container, key K, scope LifecycleScope, factoryFn interface{}) error {
+	return RegisterWithKey[T](c, typedKey(key), scope, factoryFn)
+}
+
+// ResolveKeyed resolves a service of type T registered with RegisterKeyed under the given typed key.
+// If the context is nil, it uses the container's background context.
+//
+// Parameters:
+//
+// Container: The container instance from which to resolve the service.
+//
+// Key: The typed key associated with the service to resolve.
+//
+// LifecycleContext: The lifecycle context to use for resolving the service. If nil, the container's background context is used.
+func ResolveKeyed[K comparable, T any](c Container, key K, ctx LifecycleContext) (T, error) {
+	return ResolveWithKey[T](c, typedKey(key), ctx)
+}
+
+// typedKey returns the registry key of the given typed key, qualified with its type.
+func typedKey[K comparable](key K) string {
+	return fmt.Sprintf("%T:%v", key, key)
+}
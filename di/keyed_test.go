@@ -0,0 +1,70 @@
+package di
+
+import (
+	"errors"
+	"testing"
+)
+
+type serviceName int
+
+const (
+	primaryService serviceName = iota
+	secondaryService
+)
+
+type otherName int
+
+func TestRegisterKeyed(t *testing.T) {
+	c := NewContainer()
+	if err := RegisterKeyed[serviceName, *depA](c, primaryService, Singleton, func() *depA { return &depA{name: "primary"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterKeyed[serviceName, *depA](c, secondaryService, Singleton, func() *depA { return &depA{name: "secondary"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	primary, err := ResolveKeyed[serviceName, *depA](c, primaryService, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	secondary, err := ResolveKeyed[serviceName, *depA](c, secondaryService, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if primary.name != "primary" || secondary.name != "secondary" {
+		t.Fatalf("expected each key to resolve its own registration, got %q and %q", primary.name, secondary.name)
+	}
+
+	again, err := ResolveKeyed[serviceName, *depA](c, primaryService, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if again != primary {
+		t.Fatal("expected the singleton to be shared by resolutions with the same key")
+	}
+}
+
+func TestRegisterKeyed_DistinctKeyTypesDoNotCollide(t *testing.T) {
+	c := NewContainer()
+	if err := RegisterKeyed[serviceName, *depA](c, primaryService, Transient, func() *depA { return &depA{name: "service"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterKeyed[otherName, *depA](c, otherName(primaryService), Transient, func() *depA { return &depA{name: "other"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	other, err := ResolveKeyed[otherName, *depA](c, otherName(primaryService), nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if other.name != "other" {
+		t.Fatalf("expected the registration of the other key type, got %q", other.name)
+	}
+
+	if _, err := ResolveKeyed[otherName, *depA](c, otherName(secondaryService), nil); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected ErrNotRegistered, got %v", err)
+	}
+	if err := RegisterKeyed[serviceName, *depA](c, primaryService, Transient, func() *depA { return &depA{} }); err == nil {
+		t.Fatal("expected an error when registering the same typed key twice")
+	}
+}
@@ -0,0 +1,71 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+// lazyDependencyType is the reflected type of the lazyDependency interface.
+var lazyDependencyType = diutils.TypeOf[lazyDependency]()
+
+// lazyDependency is implemented by the Lazy type, so the container can inject it without knowing its type parameter.
+type lazyDependency interface {
+	// lazyTarget returns the type of the service resolved by the lazy dependency.
+	lazyTarget() reflect.Type
+	// bind returns a lazy dependency resolving its service from the given container and lifecycle context.
+	bind(c Container, ctx LifecycleContext) reflect.Value
+}
+
+// isLazyDependency indicates whether the given factory parameter type is a Lazy dependency.
+func isLazyDependency(paramType reflect.Type) bool {
+	return paramType.Kind() == reflect.Struct && paramType.Implements(lazyDependencyType)
+}
+
+// lazyTargetKey returns the registry key of the service resolved by the given Lazy dependency type.
+func lazyTargetKey(paramType reflect.Type) string {
+	target := reflect.Zero(paramType).Interface().(lazyDependency).lazyTarget()
+	return diutils.NameOfType(target)
+}
+
+// Lazy defers the resolution of a service of type T until its first use.
+//
+// When a factory function declares a Lazy[T] parameter, the container injects a Lazy bound to the container
+// and to the lifecycle context of the resolution, and T is only resolved on the first call to Get.
+// Lazy dependencies are not part of the dependency tree of a service, so they can be used to break dependency cycles,
+// for example A can depend on Lazy[B] while B depends on A.
+type Lazy[T any] struct {
+	state *lazyState[T]
+}
+
+// lazyState holds the binding and the outcome of a Lazy resolution, it is shared by the copies of a Lazy.
+type lazyState[T any] struct {
+	container Container
+	ctx       LifecycleContext
+	once      sync.Once
+	value     T
+	err       error
+}
+
+// Get resolves the service on the first call and returns the same instance, or error, on subsequent calls.
+// It returns an error if the Lazy was not injected by a container.
+func (l Lazy[T]) Get() (T, error) {
+	if l.state == nil {
+		var zero T
+		return zero, fmt.Errorf("lazy dependency %v is not bound to a container", diutils.TypeOf[T]())
+	}
+	l.state.once.Do(func() {
+		l.state.value, l.state.err = Resolve[T](l.state.container, l.state.ctx)
+	})
+	return l.state.value, l.state.err
+}
+
+func (l Lazy[T]) lazyTarget() reflect.Type {
+	return diutils.TypeOf[T]()
+}
+
+func (l Lazy[T]) bind(c Container, ctx LifecycleContext) reflect.Value {
+	return reflect.ValueOf(Lazy[T]{state: &lazyState[T]{container: c, ctx: ctx}})
+}
@@ -0,0 +1,117 @@
+package di
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+type lazyConsumer struct {
+	b Lazy[*depB]
+}
+
+type cyclicA struct {
+	b Lazy[*cyclicB]
+}
+
+type cyclicB struct {
+	a *cyclicA
+}
+
+func TestLazy_DefersResolution(t *testing.T) {
+	c := NewContainer()
+	created := int32(0)
+
+	if err := Register[*depB](c, Singleton, func() *depB {
+		atomic.AddInt32(&created, 1)
+		return &depB{name: "b"}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*lazyConsumer](c, Transient, func(b Lazy[*depB]) *lazyConsumer {
+		return &lazyConsumer{b: b}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	consumer, err := Resolve[*lazyConsumer](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if atomic.LoadInt32(&created) != 0 {
+		t.Fatal("expected the lazy dependency not to be created before Get")
+	}
+
+	first, err := consumer.b.Get()
+	if err != nil {
+		t.Fatalf("unexpected lazy error: %v", err)
+	}
+	second, err := consumer.b.Get()
+	if err != nil {
+		t.Fatalf("unexpected lazy error: %v", err)
+	}
+	if first != second || first.name != "b" {
+		t.Fatal("expected Get to return the resolved instance")
+	}
+	if atomic.LoadInt32(&created) != 1 {
+		t.Fatalf("expected the lazy dependency to be created once, got %d", created)
+	}
+}
+
+func TestLazy_BreaksCycles(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*cyclicA](c, Singleton, func(b Lazy[*cyclicB]) *cyclicA {
+		return &cyclicA{b: b}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*cyclicB](c, Singleton, func(a *cyclicA) *cyclicB {
+		return &cyclicB{a: a}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("expected no validation error, got: %v", err)
+	}
+
+	a, err := Resolve[*cyclicA](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	b, err := a.b.Get()
+	if err != nil {
+		t.Fatalf("unexpected lazy error: %v", err)
+	}
+	if b.a != a {
+		t.Fatal("expected the cycle to resolve to the same singleton")
+	}
+}
+
+func TestLazy_ValidateRequiresTarget(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*lazyConsumer](c, Transient, func(b Lazy[*depB]) *lazyConsumer {
+		return &lazyConsumer{b: b}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected validation error for unregistered lazy target")
+	}
+
+	consumer, err := Resolve[*lazyConsumer](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if _, err := consumer.b.Get(); err == nil {
+		t.Fatal("expected Get to fail for an unregistered service")
+	}
+}
+
+func TestLazy_UnboundReturnsError(t *testing.T) {
+	var lazy Lazy[*depA]
+
+	if _, err := lazy.Get(); err == nil {
+		t.Fatal("expected error for a lazy dependency not injected by a container")
+	}
+}
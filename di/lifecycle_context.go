@@ -3,8 +3,12 @@ package di
 import (
 	"context"
 	"fmt"
+	"io"
 	"reflect"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 	dilogger "github.com/lcrux/go-di/di/di-logger"
@@ -21,21 +25,67 @@ const (
 	Singleton
 	// Scoped: A single instance is shared, like a singleton, within a specific context.
 	Scoped
+	// ScopedOrSingleton: A single instance is shared within each context created with NewContext, like Scoped,
+	// while the resolutions within the background context share a process-wide instance, like Singleton.
+	ScopedOrSingleton
 )
 
+// String returns the name of the lifecycle scope.
+func (s LifecycleScope) String() string {
+	switch s {
+	case Transient:
+		return "Transient"
+	case Singleton:
+		return "Singleton"
+	case Scoped:
+		return "Scoped"
+	case ScopedOrSingleton:
+		return "ScopedOrSingleton"
+	default:
+		return fmt.Sprintf("LifecycleScope(%d)", int(s))
+	}
+}
+
 type LifecycleListener interface {
 	EndLifecycle(...context.Context) error
 }
 
+// ContainerAwareListener is implemented by instances needing the container during their disposal,
+// for example to resolve a logger to flush to. It is preferred over LifecycleListener when both are implemented.
+//
+// The container is the one owning the lifecycle context, or nil for contexts created with NewLifecycleContext.
+// Disposing an instance never shuts down the container, so services can still be resolved from it.
+type ContainerAwareListener interface {
+	EndLifecycleWithContainer(c Container, ctx context.Context) error
+}
+
 // NewLifecycleContext creates a new instance of RegistryContext with a unique ID and an empty scopedInstances map.
 //
 // It allows storing and retrieving instances of services by their type within the context.
 // Once the context is closed, all stored instances are cleaned up and cannot be retrieved.
 func NewLifecycleContext() LifecycleContext {
+	return newLifecycleContextImpl(uuid.New().String())
+}
+
+// NewLifecycleContextWithID creates a new lifecycle context like NewLifecycleContext, identified by the given ID
+// instead of a random one, so tests can assert on stable IDs and callers can correlate scopes with external
+// request IDs. An empty or blank ID is replaced by a random one.
+func NewLifecycleContextWithID(id string) LifecycleContext {
+	if strings.TrimSpace(id) == "" {
+		return NewLifecycleContext()
+	}
+	return newLifecycleContextImpl(id)
+}
+
+// newLifecycleContextImpl creates an empty lifecycle context identified by the given ID.
+func newLifecycleContextImpl(id string) *lifecycleContextImpl {
 	ctx := &lifecycleContextImpl{
-		id:     uuid.New().String(),
-		cache:  diutils.NewAsyncMap[string, reflect.Value](),
-		logger: dilogger.NewLogger(nil),
+		id:        id,
+		startedAt: time.Now(),
+		cache:     diutils.NewAsyncMap[string, reflect.Value](),
+		values:    diutils.NewAsyncMap[string, interface{}](),
+		logger:    dilogger.NewLogger(nil),
+		createdAt: make(map[string]time.Time),
 	}
 	return ctx
 }
@@ -44,17 +94,53 @@ func NewLifecycleContext() LifecycleContext {
 type LifecycleContext interface {
 	// ID returns the unique identifier of the lifecycle context.
 	ID() string
+	// Name returns the name of the lifecycle context, empty if it was not created with Container.NewNamedContext.
+	Name() string
+	// CreatedAt returns the time the lifecycle context was created.
+	CreatedAt() time.Time
+	// Age returns the time elapsed since the lifecycle context was created, measured with the monotonic clock.
+	Age() time.Duration
+	// LastAccess returns the time an instance was last retrieved from or stored in the lifecycle context,
+	// or its creation time if it was never accessed.
+	LastAccess() time.Time
 	// IsClosed indicates whether the lifecycle context has been closed.
 	IsClosed() bool
 	// Shutdown cleans up all scoped instances in the context.
-	// It returns a slice of errors encountered during the shutdown process.
-	Shutdown(...context.Context) []error
+	// It returns a *ShutdownError wrapping the errors encountered during the shutdown process, or nil.
+	Shutdown(...context.Context) error
+	// ShutdownSync cleans up all scoped instances in the context like Shutdown, but disposes them one at a time
+	// on the calling goroutine, in the reverse order of their creation.
+	// It returns a *ShutdownError wrapping the errors encountered during the shutdown process, or nil.
+	ShutdownSync(...context.Context) error
+	// Clear disposes and removes all instances in the context without closing it, so it can be reused.
+	// It returns a *ShutdownError wrapping the errors encountered during the disposal process, or nil.
+	Clear(...context.Context) error
 	// GetInstance retrieves an instance of the specified service type from the context.
 	// It returns the instance and a boolean indicating whether the instance was found.
 	GetInstance(key string) (reflect.Value, bool)
 	// SetInstance stores an instance of the specified service type in the context.
 	// Any existing instance of the specified type will be overwritten.
 	SetInstance(key string, instance reflect.Value) error
+	// SetInstanceIfAbsent atomically stores an instance of the specified service type in the context,
+	// unless an instance is already stored for the key.
+	// It returns the stored instance and a boolean indicating whether an existing instance was loaded.
+	SetInstanceIfAbsent(key string, instance reflect.Value) (reflect.Value, bool, error)
+	// RemoveInstance removes the instance of the specified service type from the context.
+	// If the instance implements LifecycleListener or io.Closer, it is disposed after being removed.
+	RemoveInstance(key string) error
+	// Has indicates whether an instance of the specified service type is stored in the context.
+	Has(key string) bool
+	// Count returns the number of instances stored in the context.
+	Count() int
+	// Keys returns the keys of the instances stored in the context, in the order they were stored.
+	Keys() []string
+	// SetValue stores an ambient value in the context, such as a trace ID or the authenticated user,
+	// separately from the service instances. The value is ignored if the context is closed.
+	// Values are never disposed: they are only removed by Shutdown and Clear.
+	SetValue(key string, value interface{})
+	// GetValue retrieves a value stored with SetValue.
+	// It returns the value and a boolean indicating whether a value was found.
+	GetValue(key string) (interface{}, bool)
 	// SetLogger sets the logger for the lifecycle context.
 	// It returns an error if the provided logger is nil.
 	SetLogger(logger dilogger.Logger) error
@@ -63,10 +149,22 @@ type LifecycleContext interface {
 // lifecycleContextImpl is the implementation of the LifecycleContext interface.
 type lifecycleContextImpl struct {
 	id     string
+	name   string // Optional label identifying the context in logs, set by Container.NewNamedContext
 	cache  diutils.AsyncMap[string, reflect.Value]
 	mutex  sync.RWMutex
 	closed bool
 	logger dilogger.Logger
+	order  []string                              // Keys of the stored instances in insertion order, guarded by mutex
+	values diutils.AsyncMap[string, interface{}] // Ambient values set with SetValue, independent of the instances
+
+	startedAt  time.Time                      // Creation time of the context, with a monotonic clock reading
+	lastAccess atomic.Int64                   // Time of the last GetInstance or SetInstance call, in nanoseconds since startedAt
+	createdAt  map[string]time.Time           // Time each instance was stored, guarded by mutex
+	ttlOf      func(key string) time.Duration // Returns the time to live of the instances stored under a key, 0 if they do not expire
+	onDispose  func(key string, err error)    // Called after an instance is disposed, set by the container to notify its observers
+
+	disposer disposer // Disposes the stored instances, configured by the container owning the context
+	releases []func() // Callbacks run when the instances are disposed, such as returning pooled instances, guarded by mutex
 }
 
 // ID returns the unique identifier of the lifecycle context.
@@ -74,6 +172,42 @@ func (lctx *lifecycleContextImpl) ID() string {
 	return lctx.id
 }
 
+// Name returns the name of the lifecycle context, empty if it was not created with Container.NewNamedContext.
+func (lctx *lifecycleContextImpl) Name() string {
+	return lctx.name
+}
+
+// CreatedAt returns the time the lifecycle context was created.
+func (lctx *lifecycleContextImpl) CreatedAt() time.Time {
+	return lctx.startedAt
+}
+
+// Age returns the time elapsed since the lifecycle context was created.
+// It is measured with the monotonic clock, so it is not affected by changes of the wall clock.
+func (lctx *lifecycleContextImpl) Age() time.Duration {
+	return time.Since(lctx.startedAt)
+}
+
+// LastAccess returns the time an instance was last retrieved from or stored in the lifecycle context,
+// or its creation time if it was never accessed. It carries a monotonic clock reading, so the idle time
+// measured with time.Since is not affected by changes of the wall clock.
+func (lctx *lifecycleContextImpl) LastAccess() time.Time {
+	return lctx.startedAt.Add(time.Duration(lctx.lastAccess.Load()))
+}
+
+// touch records an access to the lifecycle context.
+func (lctx *lifecycleContextImpl) touch() {
+	lctx.lastAccess.Store(int64(time.Since(lctx.startedAt)))
+}
+
+// label returns the prefix identifying the lifecycle context in log lines, including its name if it has one.
+func (lctx *lifecycleContextImpl) label() string {
+	if lctx.name == "" {
+		return fmt.Sprintf("[Context ID: %s]", lctx.id)
+	}
+	return fmt.Sprintf("[Context %s (%s)]", lctx.name, lctx.id)
+}
+
 func (lctx *lifecycleContextImpl) IsClosed() bool {
 	lctx.mutex.RLock()
 	defer lctx.mutex.RUnlock()
@@ -98,8 +232,10 @@ func (lctx *lifecycleContextImpl) SetLogger(logger dilogger.Logger) error {
 
 // Shutdown cleans up all scoped instances in the context.
 // Logs the operation and confirms the context has been closed.
-func (lctx *lifecycleContextImpl) Shutdown(ctxs ...context.Context) []error {
-	lctx.logger.Debugf("[Context ID: %s] Closing lifecycle context...", lctx.ID())
+//
+// It returns a *ShutdownError wrapping the errors encountered during the shutdown process, or nil.
+func (lctx *lifecycleContextImpl) Shutdown(ctxs ...context.Context) error {
+	lctx.logger.Debugf("%s Closing lifecycle context...", lctx.label())
 
 	// If a context is provided, use it; otherwise, use a background context
 	ctx := context.Background()
@@ -107,7 +243,7 @@ func (lctx *lifecycleContextImpl) Shutdown(ctxs ...context.Context) []error {
 		ctx = ctxs[0]
 	}
 	if checkIfCanceled(ctx) {
-		return []error{fmt.Errorf("context canceled before shutdown")}
+		return newShutdownError(lctx.ID(), []error{fmt.Errorf("context canceled before shutdown")})
 	}
 
 	defer func() {
@@ -117,6 +253,103 @@ func (lctx *lifecycleContextImpl) Shutdown(ctxs ...context.Context) []error {
 		}
 	}()
 
+	errors := lctx.disposeInstances(ctx)
+
+	lctx.logger.Debugf("%s Lifecycle context closed", lctx.label())
+	return newShutdownError(lctx.ID(), errors)
+}
+
+// ShutdownSync cleans up all scoped instances in the context sequentially, in the reverse order of their creation,
+// so instances are disposed before the instances they were created from.
+// Panics raised during the disposal are recovered, and the remaining instances are still disposed.
+//
+// It returns a *ShutdownError wrapping the errors encountered during the shutdown process, or nil.
+func (lctx *lifecycleContextImpl) ShutdownSync(ctxs ...context.Context) error {
+	lctx.logger.Debugf("%s Closing lifecycle context synchronously...", lctx.label())
+
+	// If a context is provided, use it; otherwise, use a background context
+	ctx := context.Background()
+	if len(ctxs) > 0 {
+		ctx = ctxs[0]
+	}
+	if checkIfCanceled(ctx) {
+		return newShutdownError(lctx.ID(), []error{fmt.Errorf("context canceled before shutdown")})
+	}
+
+	defer func() {
+		if !checkIfCanceled(ctx) {
+			// Mark the context as closed
+			setContextClosed(lctx)
+		}
+	}()
+
+	var errors []error
+	keys := lctx.Keys()
+	for i := len(keys) - 1; i >= 0; i-- {
+		k := keys[i]
+		instance, exists := lctx.cache.Get(k)
+		if !exists {
+			continue
+		}
+		if !lctx.disposer.isDisposable(instance) {
+			lctx.deleteInstance(k)
+			continue
+		}
+
+		// Stop disposing instances once the shutdown context is canceled
+		if checkIfCanceled(ctx) {
+			errors = append(errors, fmt.Errorf("context canceled during shutdown: %w", ctx.Err()))
+			break
+		}
+
+		lctx.logger.Debugf("%s Ending lifecycle for service type: %v...", lctx.label(), k)
+		err := lctx.disposer.dispose(ctx, k, instance)
+		lctx.notifyDispose(k, err)
+		if err != nil {
+			lctx.logger.Debugf("%s Error ending lifecycle for service type: %v, error: %v", lctx.label(), k, err)
+			errors = append(errors, err)
+			if !lctx.disposer.removeOnError {
+				continue
+			}
+		}
+		lctx.deleteInstance(k)
+	}
+	lctx.runReleases()
+
+	lctx.logger.Debugf("%s Lifecycle context closed", lctx.label())
+	return newShutdownError(lctx.ID(), errors)
+}
+
+// Clear disposes and removes all instances in the context, like Shutdown, but keeps the context open.
+// Subsequent calls to SetInstance and GetInstance keep working on the emptied context.
+// It returns a *ShutdownError wrapping the errors encountered during the disposal process, or nil.
+func (lctx *lifecycleContextImpl) Clear(ctxs ...context.Context) error {
+	lctx.logger.Debugf("%s Clearing lifecycle context...", lctx.label())
+
+	// If a context is provided, use it; otherwise, use a background context
+	ctx := context.Background()
+	if len(ctxs) > 0 {
+		ctx = ctxs[0]
+	}
+	if checkIfCanceled(ctx) {
+		return newShutdownError(lctx.ID(), []error{fmt.Errorf("context canceled before clear")})
+	}
+	if lctx.IsClosed() {
+		return newShutdownError(lctx.ID(), []error{fmt.Errorf("cannot clear closed lifecycle context")})
+	}
+
+	errors := lctx.disposeInstances(ctx)
+	lctx.values.Cleanup()
+
+	lctx.logger.Debugf("%s Lifecycle context cleared", lctx.label())
+	return newShutdownError(lctx.ID(), errors)
+}
+
+// disposeInstances disposes all instances stored in the context and removes them from the cache.
+//
+// Instances implementing LifecycleListener or io.Closer are disposed concurrently, bounded by a semaphore,
+// and remain in the cache if their disposal fails, unless the container removes them on disposal errors. It returns the errors encountered during the disposal.
+func (lctx *lifecycleContextImpl) disposeInstances(ctx context.Context) []error {
 	// To collect errors from EndLifecycle calls
 	var errors []error
 	var errorsMux sync.Mutex
@@ -135,79 +368,109 @@ func (lctx *lifecycleContextImpl) Shutdown(ctxs ...context.Context) []error {
 
 	wg := sync.WaitGroup{}
 	for _, k := range cacheKeys {
-		lctx.logger.Debugf("[Context ID: %s] Deleting instance for service type: %v", lctx.ID(), k)
+		lctx.logger.Debugf("%s Deleting instance for service type: %v", lctx.label(), k)
 
 		instance, exists := lctx.cache.Get(k)
 		if !exists {
 			continue
 		}
 
-		// Check if the instance implements the LifecycleListener interface, if not, skip it
-		lm, ok := instance.Interface().(LifecycleListener)
-		if !ok {
-			lctx.logger.Debugf("[Context ID: %s] Instance for service type: %v does not implement LifecycleListener, skipping EndLifecycle", lctx.ID(), k)
-			lctx.cache.Delete(k)
+		// Check if the instance can be disposed, if not, skip it
+		if !lctx.disposer.isDisposable(instance) {
+			lctx.logger.Debugf("%s Instance for service type: %v does not implement LifecycleListener or io.Closer, skipping EndLifecycle", lctx.label(), k)
+			lctx.deleteInstance(k)
 			continue
 		}
 
-		if checkIfCanceled(ctx) {
-			setError(fmt.Errorf("context canceled during shutdown"))
-			return errors
+		// Stop acquiring new slots once the shutdown context is canceled
+		if err := semaphore.AcquireCtx(ctx); err != nil {
+			setError(fmt.Errorf("context canceled during shutdown: %w", err))
+			break
 		}
 
 		// Call EndLifecycle in a separate goroutine to avoid blocking
 		wg.Add(1)
-		semaphore.Acquire()
-		go func(lm LifecycleListener, k string, lctx *lifecycleContextImpl, ctx context.Context) {
+		go func(k string, instance reflect.Value) {
 			defer wg.Done()
 			defer semaphore.Release()
-			defer func() {
-				if r := recover(); r != nil {
-					lctx.logger.Debugf("[Context ID: %s] Recovered from panic in EndLifecycle for service type: %v, panic: %v", lctx.ID(), k, r)
-
-					setError(fmt.Errorf("panic in EndLifecycle for service type: %v, panic: %v", k, r))
-				}
-			}()
 
-			lctx.logger.Debugf("[Context ID: %s] Ending lifecycle for service type: %v...", lctx.ID(), k)
+			lctx.logger.Debugf("%s Ending lifecycle for service type: %v...", lctx.label(), k)
 
-			if err := lm.EndLifecycle(ctx); err != nil {
-				lctx.logger.Debugf("[Context ID: %s] Error ending lifecycle for service type: %v, error: %v", lctx.ID(), k, err)
-				setError(fmt.Errorf("error in EndLifecycle for service type: %v: %w", k, err))
+			err := lctx.disposer.dispose(ctx, k, instance)
+			lctx.notifyDispose(k, err)
+			if err != nil {
+				lctx.logger.Debugf("%s Error ending lifecycle for service type: %v, error: %v", lctx.label(), k, err)
+				setError(err)
+				if lctx.disposer.removeOnError {
+					lctx.deleteInstance(k)
+				}
 			} else {
 				// Remove the instance from the cache
-				lctx.logger.Debugf("[Context ID: %s] Removing instance for service type: %v", lctx.ID(), k)
-				lctx.cache.Delete(k)
+				lctx.logger.Debugf("%s Removing instance for service type: %v", lctx.label(), k)
+				lctx.deleteInstance(k)
 			}
-		}(lm, k, lctx, ctx)
+		}(k, instance)
 	}
 	wg.Wait() // Wait for all EndLifecycle calls to complete
+	lctx.runReleases()
 
-	lctx.logger.Debugf("[Context ID: %s] Lifecycle context closed", lctx.ID())
 	return errors
 }
 
+// onRelease registers a callback run once when the instances of the context are disposed, by Shutdown,
+// ShutdownSync or Clear, for resources handed out within the context without being cached, such as pooled instances.
+// It returns false, without registering the callback, if the context is closed.
+func (lctx *lifecycleContextImpl) onRelease(fn func()) bool {
+	lctx.mutex.Lock()
+	defer lctx.mutex.Unlock()
+
+	if lctx.closed {
+		return false
+	}
+	lctx.releases = append(lctx.releases, fn)
+	return true
+}
+
+// runReleases runs and removes the callbacks registered with onRelease.
+func (lctx *lifecycleContextImpl) runReleases() {
+	lctx.mutex.Lock()
+	releases := lctx.releases
+	lctx.releases = nil
+	lctx.mutex.Unlock()
+
+	for _, fn := range releases {
+		fn()
+	}
+}
+
 // GetInstance retrieves an instance of the specified service type from the context.
 // Logs the operation and whether the instance was found.
 func (lctx *lifecycleContextImpl) GetInstance(key string) (reflect.Value, bool) {
 	if key == "" {
-		lctx.logger.Debugf("[Context ID: %s] GetInstance called with empty service type key", lctx.ID())
+		lctx.logger.Debugf("%s GetInstance called with empty service type key", lctx.label())
 		return reflect.Value{}, false
 	}
 	if lctx.IsClosed() {
-		lctx.logger.Debugf("[Context ID: %s] Cannot get instance from closed lifecycle context", lctx.ID())
+		lctx.logger.Debugf("%s Cannot get instance from closed lifecycle context", lctx.label())
 		return reflect.Value{}, false
 	}
 
+	lctx.touch()
+	lctx.logger.Debugf("%s Getting instance for service type: %v", lctx.label(), key)
 	lctx.mutex.RLock()
-	defer lctx.mutex.RUnlock()
-
-	lctx.logger.Debugf("[Context ID: %s] Getting instance for service type: %v", lctx.ID(), key)
 	instance, exists := lctx.cache.Get(key)
+	expired := exists && lctx.isExpired(key)
+	lctx.mutex.RUnlock()
+
+	// An expired instance is disposed and reported as missing, so it is created again
+	if expired {
+		lctx.evict(key)
+		instance, exists = reflect.Value{}, false
+	}
 	if exists {
-		lctx.logger.Debugf("[Context ID: %s] Instance found for service type: %v", lctx.ID(), key)
+		lctx.logger.Debugf("%s Instance found for service type: %v", lctx.label(), key)
 	} else {
-		lctx.logger.Debugf("[Context ID: %s] No instance found for service type: %v", lctx.ID(), key)
+		lctx.logger.Debugf("%s No instance found for service type: %v", lctx.label(), key)
 	}
 
 	return instance, exists
@@ -228,16 +491,298 @@ func (lctx *lifecycleContextImpl) SetInstance(key string, instance reflect.Value
 		return fmt.Errorf("cannot set instance on closed lifecycle context")
 	}
 
+	lctx.touch()
 	lctx.mutex.Lock()
 	defer lctx.mutex.Unlock()
 
-	lctx.logger.Debugf("[Context ID: %s] Setting instance for service type: %v", lctx.ID(), key)
+	lctx.logger.Debugf("%s Setting instance for service type: %v", lctx.label(), key)
 	if _, exists := lctx.cache.Get(key); exists {
-		lctx.logger.Debugf("[Context ID: %s] Overwriting existing instance for service type: %v", lctx.ID(), key)
+		lctx.logger.Debugf("%s Overwriting existing instance for service type: %v", lctx.label(), key)
 	}
 
 	lctx.cache.Set(key, instance)
-	lctx.logger.Debugf("[Context ID: %s] Instance set for service type: %v", lctx.ID(), key)
+	lctx.order = append(removeKey(lctx.order, key), key)
+	lctx.createdAt[key] = time.Now()
+	lctx.logger.Debugf("%s Instance set for service type: %v", lctx.label(), key)
+	return nil
+}
+
+// SetInstanceIfAbsent atomically stores an instance of the specified service type in the context,
+// unless an instance is already stored for the key.
+//
+// It returns the stored instance, which is the existing one if any, and a boolean indicating whether it was loaded.
+func (lctx *lifecycleContextImpl) SetInstanceIfAbsent(key string, instance reflect.Value) (reflect.Value, bool, error) {
+	if key == "" {
+		return reflect.Value{}, false, fmt.Errorf("service type key cannot be empty")
+	}
+	if !instance.IsValid() {
+		return reflect.Value{}, false, fmt.Errorf("instance value is not valid")
+	}
+	if lctx.IsClosed() {
+		return reflect.Value{}, false, fmt.Errorf("cannot set instance on closed lifecycle context")
+	}
+
+	lctx.touch()
+	lctx.mutex.Lock()
+	defer lctx.mutex.Unlock()
+
+	stored, loaded := lctx.cache.LoadOrStore(key, instance)
+	if !loaded {
+		lctx.order = append(lctx.order, key)
+		lctx.createdAt[key] = time.Now()
+	}
+	if loaded {
+		lctx.logger.Debugf("%s Instance already set for service type: %v", lctx.label(), key)
+	} else {
+		lctx.logger.Debugf("%s Instance set for service type: %v", lctx.label(), key)
+	}
+	return stored, loaded, nil
+}
+
+// RemoveInstance removes the instance of the specified service type from the context.
+//
+// The instance is removed under the context lock and disposed afterwards, calling EndLifecycle or Close
+// if it implements LifecycleListener or io.Closer. Removing a missing instance is a no-op.
+func (lctx *lifecycleContextImpl) RemoveInstance(key string) error {
+	if key == "" {
+		return fmt.Errorf("service type key cannot be empty")
+	}
+	if lctx.IsClosed() {
+		return fmt.Errorf("cannot remove instance from closed lifecycle context")
+	}
+
+	instance, exists := func() (reflect.Value, bool) {
+		lctx.mutex.Lock()
+		defer lctx.mutex.Unlock()
+
+		instance, exists := lctx.cache.Get(key)
+		if exists {
+			lctx.cache.Delete(key)
+			lctx.order = removeKey(lctx.order, key)
+			delete(lctx.createdAt, key)
+		}
+		return instance, exists
+	}()
+	if !exists {
+		lctx.logger.Debugf("%s No instance to remove for service type: %v", lctx.label(), key)
+		return nil
+	}
+
+	lctx.logger.Debugf("%s Removed instance for service type: %v", lctx.label(), key)
+	if !lctx.disposer.isDisposable(instance) {
+		return nil
+	}
+	err := lctx.disposer.dispose(context.Background(), key, instance)
+	lctx.notifyDispose(key, err)
+	return err
+}
+
+// notifyDispose reports the disposal of the instance stored under the given key, if a dispose hook is set.
+func (lctx *lifecycleContextImpl) notifyDispose(key string, err error) {
+	if lctx.onDispose != nil {
+		lctx.onDispose(key, err)
+	}
+}
+
+// Has indicates whether an instance of the specified service type is stored in the context.
+// It returns false if the context is closed.
+func (lctx *lifecycleContextImpl) Has(key string) bool {
+	if lctx.IsClosed() {
+		return false
+	}
+
+	lctx.mutex.RLock()
+	defer lctx.mutex.RUnlock()
+
+	_, exists := lctx.cache.Get(key)
+	return exists
+}
+
+// Count returns the number of instances stored in the context.
+// It returns 0 if the context is closed.
+func (lctx *lifecycleContextImpl) Count() int {
+	if lctx.IsClosed() {
+		return 0
+	}
+
+	lctx.mutex.RLock()
+	defer lctx.mutex.RUnlock()
+
+	return lctx.cache.Len()
+}
+
+// Keys returns the keys of the instances stored in the context, in the order they were stored.
+// An overwritten instance moves to the end, as it was stored last. It returns nil if the context is closed.
+func (lctx *lifecycleContextImpl) Keys() []string {
+	if lctx.IsClosed() {
+		return nil
+	}
+
+	lctx.mutex.RLock()
+	defer lctx.mutex.RUnlock()
+
+	return append([]string(nil), lctx.order...)
+}
+
+// SetValue stores an ambient value in the context, independently of the service instances.
+// The value is ignored if the context is closed, and it is removed, without being disposed, by Shutdown and Clear.
+func (lctx *lifecycleContextImpl) SetValue(key string, value interface{}) {
+	// Hold the read lock, so the value cannot be stored after the values of a closing context are removed
+	lctx.mutex.RLock()
+	defer lctx.mutex.RUnlock()
+
+	if lctx.closed {
+		lctx.logger.Debugf("%s Cannot set value on closed lifecycle context", lctx.label())
+		return
+	}
+	lctx.values.Set(key, value)
+}
+
+// GetValue retrieves a value stored with SetValue.
+// It returns the value and a boolean indicating whether a value was found.
+func (lctx *lifecycleContextImpl) GetValue(key string) (interface{}, bool) {
+	return lctx.values.Get(key)
+}
+
+// deleteInstance removes the instance stored under the given key from the cache and from the insertion order.
+func (lctx *lifecycleContextImpl) deleteInstance(key string) {
+	lctx.mutex.Lock()
+	defer lctx.mutex.Unlock()
+
+	lctx.cache.Delete(key)
+	lctx.order = removeKey(lctx.order, key)
+	delete(lctx.createdAt, key)
+}
+
+// isExpired indicates whether the instance stored under the given key has outlived its time to live.
+// It must be called with the context lock held.
+func (lctx *lifecycleContextImpl) isExpired(key string) bool {
+	if lctx.ttlOf == nil {
+		return false
+	}
+	ttl := lctx.ttlOf(key)
+	if ttl <= 0 {
+		return false
+	}
+	createdAt, exists := lctx.createdAt[key]
+	return exists && time.Since(createdAt) > ttl
+}
+
+// evict removes the instance stored under the given key if it is still expired, then disposes it.
+// A disposal error is logged, as the instance is already removed and a new one can be created.
+func (lctx *lifecycleContextImpl) evict(key string) {
+	instance, evicted := func() (reflect.Value, bool) {
+		lctx.mutex.Lock()
+		defer lctx.mutex.Unlock()
+
+		// A concurrent resolution may have evicted the instance and stored a new one in the meantime
+		instance, exists := lctx.cache.Get(key)
+		if !exists || !lctx.isExpired(key) {
+			return reflect.Value{}, false
+		}
+		lctx.cache.Delete(key)
+		lctx.order = removeKey(lctx.order, key)
+		delete(lctx.createdAt, key)
+		return instance, true
+	}()
+	if !evicted {
+		return
+	}
+
+	lctx.logger.Debugf("%s Evicted expired instance for service type: %v", lctx.label(), key)
+	if !lctx.disposer.isDisposable(instance) {
+		return
+	}
+	err := lctx.disposer.dispose(context.Background(), key, instance)
+	lctx.notifyDispose(key, err)
+	if err != nil {
+		lctx.logger.Errorf("%s Failed to dispose expired instance for service type: %v, error: %v", lctx.label(), key, err)
+	}
+}
+
+// removeKey returns the given keys without the given key.
+func removeKey(keys []string, key string) []string {
+	for i, k := range keys {
+		if k == key {
+			return append(keys[:i], keys[i+1:]...)
+		}
+	}
+	return keys
+}
+
+// disposer ends the lifecycle of the instances of a container.
+type disposer struct {
+	container     Container // Container passed to ContainerAwareListener instances, nil outside a container
+	conventional  bool      // Whether instances exposing Stop or Shutdown methods are disposed
+	removeOnError bool      // Whether instances are removed from the cache even when their disposal fails
+}
+
+// isDisposable indicates whether the given instance implements ContainerAwareListener, LifecycleListener or io.Closer,
+// or, when conventional disposal is enabled, exposes a Stop or Shutdown method.
+func (d disposer) isDisposable(instance reflect.Value) bool {
+	switch instance.Interface().(type) {
+	case ContainerAwareListener, LifecycleListener, io.Closer:
+		return true
+	default:
+		if d.conventional {
+			_, dispose := conventionalDisposer(instance)
+			return dispose != nil
+		}
+		return false
+	}
+}
+
+// conventionalDisposer returns the name of the method disposing the given instance by convention, and a function calling it.
+// The recognized methods are Stop() error, Stop() and Shutdown(context.Context) error. It returns a nil function otherwise.
+func conventionalDisposer(instance reflect.Value) (string, func(ctx context.Context) error) {
+	switch disposable := instance.Interface().(type) {
+	case interface{ Stop() error }:
+		return "Stop", func(_ context.Context) error { return disposable.Stop() }
+	case interface{ Stop() }:
+		return "Stop", func(_ context.Context) error {
+			disposable.Stop()
+			return nil
+		}
+	case interface{ Shutdown(context.Context) error }:
+		return "Shutdown", disposable.Shutdown
+	default:
+		return "", nil
+	}
+}
+
+// dispose ends the lifecycle of the given instance if it implements ContainerAwareListener, LifecycleListener or io.Closer,
+// or, when conventional disposal is enabled, if it exposes a Stop or Shutdown method.
+// A panic raised during the disposal is recovered and returned as an error.
+func (d disposer) dispose(ctx context.Context, key string, instance reflect.Value) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic in EndLifecycle for service type: %v, panic: %v", key, r)
+		}
+	}()
+
+	switch disposable := instance.Interface().(type) {
+	case ContainerAwareListener:
+		if err := disposable.EndLifecycleWithContainer(d.container, ctx); err != nil {
+			return fmt.Errorf("error in EndLifecycleWithContainer for service type: %v: %w", key, err)
+		}
+	case LifecycleListener:
+		if err := disposable.EndLifecycle(ctx); err != nil {
+			return fmt.Errorf("error in EndLifecycle for service type: %v: %w", key, err)
+		}
+	case io.Closer:
+		if err := disposable.Close(); err != nil {
+			return fmt.Errorf("error in Close for service type: %v: %w", key, err)
+		}
+	default:
+		if !d.conventional {
+			return nil
+		}
+		if method, dispose := conventionalDisposer(instance); dispose != nil {
+			if err := dispose(ctx); err != nil {
+				return fmt.Errorf("error in %s for service type: %v: %w", method, key, err)
+			}
+		}
+	}
 	return nil
 }
 
@@ -254,4 +799,5 @@ func setContextClosed(lctx *lifecycleContextImpl) {
 	lctx.mutex.Lock()
 	defer lctx.mutex.Unlock()
 	lctx.closed = true
+	lctx.values.Cleanup()
 }
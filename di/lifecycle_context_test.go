@@ -3,9 +3,14 @@ package di
 import (
 	"context"
 	"errors"
+	"fmt"
 	"reflect"
+	"slices"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	diutils "github.com/lcrux/go-di/di/di-utils"
 )
@@ -18,6 +23,16 @@ func TestNewLifecycleContext(t *testing.T) {
 	}
 }
 
+func TestNewLifecycleContextWithID(t *testing.T) {
+	ctx := NewLifecycleContextWithID("request-42")
+	if ctx.ID() != "request-42" {
+		t.Fatalf("Expected ID request-42, got %s", ctx.ID())
+	}
+	if generated := NewLifecycleContextWithID(" "); strings.TrimSpace(generated.ID()) == "" {
+		t.Fatal("Expected a generated ID for a blank ID")
+	}
+}
+
 type listenerOk struct {
 	called *int32
 }
@@ -109,7 +124,7 @@ func TestLifecycleContext_Shutdown_InvokesLifecycleListener(t *testing.T) {
 	if err := ctx.SetInstance(key, instance); err != nil {
 		t.Fatalf("Failed to set instance: %v", err)
 	}
-	errs := ctx.Shutdown()
+	errs := ShutdownErrors(ctx.Shutdown())
 
 	if len(errs) != 0 {
 		t.Fatalf("Expected no errors, got %d", len(errs))
@@ -133,7 +148,7 @@ func TestLifecycleContext_Shutdown_CollectsErrors(t *testing.T) {
 	if err := ctx.SetInstance(key, instance); err != nil {
 		t.Fatalf("Failed to set instance: %v", err)
 	}
-	errs := ctx.Shutdown()
+	errs := ShutdownErrors(ctx.Shutdown())
 
 	if len(errs) != 1 {
 		t.Fatalf("Expected one error, got %d", len(errs))
@@ -154,7 +169,7 @@ func TestLifecycleContext_Shutdown_RecoversFromPanics(t *testing.T) {
 	if err := ctx.SetInstance(key, instance); err != nil {
 		t.Fatalf("Failed to set instance: %v", err)
 	}
-	errs := ctx.Shutdown()
+	errs := ShutdownErrors(ctx.Shutdown())
 
 	if len(errs) != 1 {
 		t.Fatalf("Expected one error from panic recovery, got %d", len(errs))
@@ -166,10 +181,58 @@ func TestLifecycleContext_Shutdown_RecoversFromPanics(t *testing.T) {
 	}
 }
 
+type orderedListener struct {
+	name  string
+	order *[]string
+}
+
+func (l *orderedListener) EndLifecycle(_ ...context.Context) error {
+	*l.order = append(*l.order, l.name)
+	return nil
+}
+
+func TestLifecycleContext_ShutdownSync_DisposesInReverseOrder(t *testing.T) {
+	ctx := NewLifecycleContext()
+	var order []string
+
+	for _, name := range []string{"first", "second", "third"} {
+		if err := ctx.SetInstance(name, reflect.ValueOf(&orderedListener{name: name, order: &order})); err != nil {
+			t.Fatalf("Failed to set instance: %v", err)
+		}
+	}
+	if err := ctx.SetInstance("panic", reflect.ValueOf(&listenerPanic{})); err != nil {
+		t.Fatalf("Failed to set instance: %v", err)
+	}
+	if err := ctx.SetInstance("last", reflect.ValueOf(&orderedListener{name: "last", order: &order})); err != nil {
+		t.Fatalf("Failed to set instance: %v", err)
+	}
+
+	err := ctx.ShutdownSync()
+	var shutdownErr *ShutdownError
+	if !errors.As(err, &shutdownErr) {
+		t.Fatalf("Expected a *ShutdownError, got %v", err)
+	}
+	errs := ShutdownErrors(err)
+	if len(errs) != 1 {
+		t.Fatalf("Expected one error from panic recovery, got %d", len(errs))
+	}
+	if !strings.Contains(errs[0].Error(), "panic") {
+		t.Fatalf("Expected a panic error, got %v", errs[0])
+	}
+
+	expected := []string{"last", "third", "second", "first"}
+	if !slices.Equal(order, expected) {
+		t.Fatalf("Expected disposal order %v, got %v", expected, order)
+	}
+	if !ctx.IsClosed() {
+		t.Fatal("Expected context to be closed")
+	}
+}
+
 func TestLifecycleContext_Shutdown_EmptyContext(t *testing.T) {
 	ctx := NewLifecycleContext()
 
-	errs := ctx.Shutdown()
+	errs := ShutdownErrors(ctx.Shutdown())
 	if len(errs) != 0 {
 		t.Fatalf("Expected no errors, got %d", len(errs))
 	}
@@ -189,7 +252,7 @@ func TestLifecycleContext_Shutdown_ContextCanceledBeforeStart(t *testing.T) {
 	cancelCtx, cancel := context.WithCancel(context.Background())
 	cancel()
 
-	errs := ctx.Shutdown(cancelCtx)
+	errs := ShutdownErrors(ctx.Shutdown(cancelCtx))
 	if len(errs) != 1 {
 		t.Fatalf("Expected one error, got %d", len(errs))
 	}
@@ -200,3 +263,384 @@ func TestLifecycleContext_Shutdown_ContextCanceledBeforeStart(t *testing.T) {
 		t.Fatal("Expected instance to remain after canceled shutdown")
 	}
 }
+
+func TestLifecycleContext_SetInstanceIfAbsent(t *testing.T) {
+	ctx := NewLifecycleContext()
+	key := diutils.NameOfType(reflect.TypeOf(""))
+
+	stored, loaded, err := ctx.SetInstanceIfAbsent(key, reflect.ValueOf("first"))
+	if err != nil {
+		t.Fatalf("Failed to set instance: %v", err)
+	}
+	if loaded || stored.Interface() != "first" {
+		t.Fatalf("Expected first instance to be stored, got %v (loaded: %v)", stored.Interface(), loaded)
+	}
+
+	stored, loaded, err = ctx.SetInstanceIfAbsent(key, reflect.ValueOf("second"))
+	if err != nil {
+		t.Fatalf("Failed to set instance: %v", err)
+	}
+	if !loaded || stored.Interface() != "first" {
+		t.Fatalf("Expected existing instance to be kept, got %v (loaded: %v)", stored.Interface(), loaded)
+	}
+}
+
+func TestLifecycleContext_SetInstanceIfAbsent_Concurrent(t *testing.T) {
+	ctx := NewLifecycleContext()
+	key := diutils.NameOfType(reflect.TypeOf(&depA{}))
+	stored := int32(0)
+
+	results := make([]*depA, 50)
+	wg := sync.WaitGroup{}
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			value, loaded, err := ctx.SetInstanceIfAbsent(key, reflect.ValueOf(&depA{}))
+			if err != nil {
+				t.Errorf("Failed to set instance: %v", err)
+				return
+			}
+			if !loaded {
+				atomic.AddInt32(&stored, 1)
+			}
+			results[i] = value.Interface().(*depA)
+		}(i)
+	}
+	wg.Wait()
+
+	if stored != 1 {
+		t.Fatalf("Expected exactly one instance to be stored, got %d", stored)
+	}
+	for _, r := range results {
+		if r != results[0] {
+			t.Fatal("Expected all goroutines to get the same instance")
+		}
+	}
+}
+
+func TestLifecycleContext_SetInstanceIfAbsent_ClosedContext(t *testing.T) {
+	ctx := NewLifecycleContext()
+	ctx.Shutdown()
+
+	if _, _, err := ctx.SetInstanceIfAbsent("key", reflect.ValueOf("value")); err == nil {
+		t.Fatal("Expected error when setting an instance on a closed context")
+	}
+}
+
+type closerErr struct {
+	closed *int32
+}
+
+func (c *closerErr) Close() error {
+	atomic.AddInt32(c.closed, 1)
+	return errors.New("close failed")
+}
+
+func TestLifecycleContext_RemoveInstance_InvokesLifecycleListener(t *testing.T) {
+	ctx := NewLifecycleContext()
+	called := int32(0)
+	key := diutils.NameOfType(reflect.TypeOf(&listenerOk{}))
+
+	if err := ctx.SetInstance(key, reflect.ValueOf(&listenerOk{called: &called})); err != nil {
+		t.Fatalf("Failed to set instance: %v", err)
+	}
+	if err := ctx.RemoveInstance(key); err != nil {
+		t.Fatalf("Failed to remove instance: %v", err)
+	}
+
+	if atomic.LoadInt32(&called) != 1 {
+		t.Fatalf("Expected EndLifecycle to be called once, got %d", called)
+	}
+	if ctx.Has(key) {
+		t.Fatal("Expected instance to be removed")
+	}
+}
+
+func TestLifecycleContext_RemoveInstance_ClosesCloser(t *testing.T) {
+	ctx := NewLifecycleContext()
+	closed := int32(0)
+	key := diutils.NameOfType(reflect.TypeOf(&closerErr{}))
+
+	if err := ctx.SetInstance(key, reflect.ValueOf(&closerErr{closed: &closed})); err != nil {
+		t.Fatalf("Failed to set instance: %v", err)
+	}
+	if err := ctx.RemoveInstance(key); err == nil {
+		t.Fatal("Expected the Close error to be returned")
+	}
+
+	if atomic.LoadInt32(&closed) != 1 {
+		t.Fatalf("Expected Close to be called once, got %d", closed)
+	}
+	if ctx.Has(key) {
+		t.Fatal("Expected instance to be removed even when Close fails")
+	}
+}
+
+func TestLifecycleContext_RemoveInstance_RecoversFromPanics(t *testing.T) {
+	ctx := NewLifecycleContext()
+	key := diutils.NameOfType(reflect.TypeOf(&listenerPanic{}))
+
+	if err := ctx.SetInstance(key, reflect.ValueOf(&listenerPanic{})); err != nil {
+		t.Fatalf("Failed to set instance: %v", err)
+	}
+	if err := ctx.RemoveInstance(key); err == nil {
+		t.Fatal("Expected the panic to be returned as an error")
+	}
+}
+
+func TestLifecycleContext_RemoveInstance_MissingKey(t *testing.T) {
+	ctx := NewLifecycleContext()
+
+	if err := ctx.RemoveInstance("missing"); err != nil {
+		t.Fatalf("Expected removing a missing instance to be a no-op, got %v", err)
+	}
+	if err := ctx.RemoveInstance(""); err == nil {
+		t.Fatal("Expected error for empty key")
+	}
+}
+
+func TestLifecycleContext_HasAndCount(t *testing.T) {
+	ctx := NewLifecycleContext()
+
+	if ctx.Has("first") || ctx.Count() != 0 {
+		t.Fatal("Expected empty context")
+	}
+	if err := ctx.SetInstance("first", reflect.ValueOf("first")); err != nil {
+		t.Fatalf("Failed to set instance: %v", err)
+	}
+	if err := ctx.SetInstance("second", reflect.ValueOf("second")); err != nil {
+		t.Fatalf("Failed to set instance: %v", err)
+	}
+
+	if !ctx.Has("first") || !ctx.Has("second") {
+		t.Fatal("Expected instances to be present")
+	}
+	if ctx.Count() != 2 {
+		t.Fatalf("Expected 2 instances, got %d", ctx.Count())
+	}
+
+	ctx.Shutdown()
+	if ctx.Has("first") || ctx.Count() != 0 {
+		t.Fatal("Expected closed context to report no instances")
+	}
+	if err := ctx.RemoveInstance("first"); err == nil {
+		t.Fatal("Expected error when removing from a closed context")
+	}
+}
+
+func TestLifecycleContext_Clear_KeepsContextUsable(t *testing.T) {
+	ctx := NewLifecycleContext()
+	called := int32(0)
+	key := diutils.NameOfType(reflect.TypeOf(&listenerOk{}))
+
+	if err := ctx.SetInstance(key, reflect.ValueOf(&listenerOk{called: &called})); err != nil {
+		t.Fatalf("Failed to set instance: %v", err)
+	}
+	if err := ctx.SetInstance("plain", reflect.ValueOf("plain")); err != nil {
+		t.Fatalf("Failed to set instance: %v", err)
+	}
+
+	if errs := ShutdownErrors(ctx.Clear()); len(errs) != 0 {
+		t.Fatalf("Expected no errors, got %v", errs)
+	}
+	if atomic.LoadInt32(&called) != 1 {
+		t.Fatalf("Expected EndLifecycle to be called once, got %d", called)
+	}
+	if ctx.IsClosed() {
+		t.Fatal("Expected context to remain open after Clear")
+	}
+	if ctx.Count() != 0 {
+		t.Fatalf("Expected no instances after Clear, got %d", ctx.Count())
+	}
+
+	if err := ctx.SetInstance("plain", reflect.ValueOf("again")); err != nil {
+		t.Fatalf("Failed to set instance after Clear: %v", err)
+	}
+	if val, exists := ctx.GetInstance("plain"); !exists || val.Interface() != "again" {
+		t.Fatal("Expected context to be reusable after Clear")
+	}
+}
+
+func TestLifecycleContext_Clear_CollectsErrors(t *testing.T) {
+	ctx := NewLifecycleContext()
+	closed := int32(0)
+
+	if err := ctx.SetInstance("closer", reflect.ValueOf(&closerErr{closed: &closed})); err != nil {
+		t.Fatalf("Failed to set instance: %v", err)
+	}
+	if err := ctx.SetInstance("panic", reflect.ValueOf(&listenerPanic{})); err != nil {
+		t.Fatalf("Failed to set instance: %v", err)
+	}
+
+	if errs := ShutdownErrors(ctx.Clear()); len(errs) != 2 {
+		t.Fatalf("Expected two errors, got %v", errs)
+	}
+	if atomic.LoadInt32(&closed) != 1 {
+		t.Fatalf("Expected Close to be called once, got %d", closed)
+	}
+	if ctx.IsClosed() {
+		t.Fatal("Expected context to remain open after Clear")
+	}
+}
+
+func TestLifecycleContext_Clear_ClosedContext(t *testing.T) {
+	ctx := NewLifecycleContext()
+	ctx.Shutdown()
+
+	if errs := ShutdownErrors(ctx.Clear()); len(errs) != 1 {
+		t.Fatalf("Expected one error when clearing a closed context, got %v", errs)
+	}
+}
+
+func TestLifecycleContext_KeysInInsertionOrder(t *testing.T) {
+	ctx := NewLifecycleContext()
+
+	for _, key := range []string{"c", "a", "b"} {
+		if err := ctx.SetInstance(key, reflect.ValueOf(key)); err != nil {
+			t.Fatalf("Failed to set instance: %v", err)
+		}
+	}
+	if _, _, err := ctx.SetInstanceIfAbsent("d", reflect.ValueOf("d")); err != nil {
+		t.Fatalf("Failed to set instance: %v", err)
+	}
+	if keys := ctx.Keys(); !reflect.DeepEqual(keys, []string{"c", "a", "b", "d"}) {
+		t.Fatalf("Expected keys in insertion order, got %v", keys)
+	}
+
+	// Overwriting an instance moves its key to the end
+	if err := ctx.SetInstance("a", reflect.ValueOf("a2")); err != nil {
+		t.Fatalf("Failed to set instance: %v", err)
+	}
+	if err := ctx.RemoveInstance("b"); err != nil {
+		t.Fatalf("Failed to remove instance: %v", err)
+	}
+	if keys := ctx.Keys(); !reflect.DeepEqual(keys, []string{"c", "d", "a"}) {
+		t.Fatalf("Expected keys to reflect the overwrite and the removal, got %v", keys)
+	}
+
+	if err := ctx.Clear(); err != nil {
+		t.Fatalf("Failed to clear context: %v", err)
+	}
+	if keys := ctx.Keys(); len(keys) != 0 {
+		t.Fatalf("Expected no keys after clear, got %v", keys)
+	}
+}
+
+func TestLifecycleContext_CreatedAtAndAge(t *testing.T) {
+	before := time.Now()
+	ctx := NewLifecycleContext()
+	after := time.Now()
+
+	createdAt := ctx.CreatedAt()
+	if createdAt.Before(before) || createdAt.After(after) {
+		t.Fatalf("Expected CreatedAt between %v and %v, got %v", before, after, createdAt)
+	}
+
+	age := ctx.Age()
+	time.Sleep(10 * time.Millisecond)
+	if later := ctx.Age(); later < age+10*time.Millisecond {
+		t.Fatalf("Expected Age to increase by at least 10ms, got %v then %v", age, later)
+	}
+	if !ctx.CreatedAt().Equal(createdAt) {
+		t.Fatalf("Expected CreatedAt to be stable, got %v then %v", createdAt, ctx.CreatedAt())
+	}
+}
+
+func TestLifecycleContext_LastAccess(t *testing.T) {
+	ctx := NewLifecycleContext()
+	if !ctx.LastAccess().Equal(ctx.CreatedAt()) {
+		t.Fatalf("Expected LastAccess to be the creation time before any access, got %v", ctx.LastAccess())
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	if err := ctx.SetInstance("key", reflect.ValueOf(&depA{})); err != nil {
+		t.Fatalf("Unexpected set error: %v", err)
+	}
+	set := ctx.LastAccess()
+	if set.Sub(ctx.CreatedAt()) < 10*time.Millisecond {
+		t.Fatalf("Expected LastAccess to advance on SetInstance, got %v after creation", set.Sub(ctx.CreatedAt()))
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	ctx.GetInstance("key")
+	get := ctx.LastAccess()
+	if get.Sub(set) < 10*time.Millisecond {
+		t.Fatalf("Expected LastAccess to advance on GetInstance, got %v after SetInstance", get.Sub(set))
+	}
+
+	// An untouched context reports a stale last access
+	time.Sleep(20 * time.Millisecond)
+	if !ctx.LastAccess().Equal(get) {
+		t.Fatalf("Expected LastAccess to be stable without access, got %v then %v", get, ctx.LastAccess())
+	}
+	if idle := time.Since(ctx.LastAccess()); idle < 20*time.Millisecond {
+		t.Fatalf("Expected the context to be idle for at least 20ms, got %v", idle)
+	}
+}
+
+func TestLifecycleContext_Values(t *testing.T) {
+	ctx := NewLifecycleContext()
+	called := int32(0)
+	listener := &listenerOk{called: &called}
+
+	ctx.SetValue("trace_id", "abc-123")
+	ctx.SetValue("listener", listener)
+
+	if v, ok := ctx.GetValue("trace_id"); !ok || v != "abc-123" {
+		t.Fatalf("Expected value 'abc-123', got %v (found: %v)", v, ok)
+	}
+	if _, ok := ctx.GetValue("missing"); ok {
+		t.Fatal("Expected missing value not to be found")
+	}
+	if ctx.Count() != 0 || ctx.Has("trace_id") {
+		t.Fatal("Expected values to be stored separately from the instances")
+	}
+
+	if errs := ShutdownErrors(ctx.Clear()); len(errs) != 0 {
+		t.Fatalf("Expected no errors, got %v", errs)
+	}
+	if _, ok := ctx.GetValue("trace_id"); ok {
+		t.Fatal("Expected values to be removed by Clear")
+	}
+	if atomic.LoadInt32(&called) != 0 {
+		t.Fatal("Expected values not to be disposed")
+	}
+
+	ctx.SetValue("trace_id", "def-456")
+	if err := ctx.Shutdown(); err != nil {
+		t.Fatalf("Unexpected shutdown error: %v", err)
+	}
+	if _, ok := ctx.GetValue("trace_id"); ok {
+		t.Fatal("Expected values to be removed by Shutdown")
+	}
+	ctx.SetValue("trace_id", "ghi-789")
+	if _, ok := ctx.GetValue("trace_id"); ok {
+		t.Fatal("Expected values to be ignored once the context is closed")
+	}
+}
+
+func TestLifecycleContext_Values_Concurrent(t *testing.T) {
+	ctx := NewLifecycleContext()
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			key := fmt.Sprintf("key-%d", i%5)
+			ctx.SetValue(key, i)
+			ctx.GetValue(key)
+		}(i)
+	}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		_ = ctx.Shutdown()
+	}()
+	wg.Wait()
+
+	if _, ok := ctx.GetValue("key-0"); ok {
+		t.Fatal("Expected no value to remain once the context is shut down")
+	}
+}
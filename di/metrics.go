@@ -0,0 +1,90 @@
+package di
+
+import (
+	"sync/atomic"
+	"time"
+
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+// ServiceMetrics holds the resolution metrics of a single service.
+type ServiceMetrics struct {
+	Constructions             uint64        // Number of instances created by the factory function
+	CacheHits                 uint64        // Number of resolutions served from a lifecycle context cache
+	TotalConstructionDuration time.Duration // Total time spent creating instances
+}
+
+// ContainerMetrics holds the resolution metrics of the services of a container, keyed by their registry keys.
+type ContainerMetrics map[string]ServiceMetrics
+
+// WithMetrics enables the collection of resolution metrics, returned by Container.Metrics.
+// Metrics are disabled by default to avoid their overhead when resolving services.
+func WithMetrics() ContainerOption {
+	return func(c *containerImpl) {
+		if c.metrics == nil {
+			c.metrics = newMetricsCollector()
+			c.AddObserver(c.metrics)
+		}
+	}
+}
+
+// Metrics returns a snapshot of the resolution metrics of the container.
+// It returns an empty snapshot if the container was not created with WithMetrics.
+func (c *containerImpl) Metrics() ContainerMetrics {
+	if c.metrics == nil {
+		return ContainerMetrics{}
+	}
+	return c.metrics.snapshot()
+}
+
+// serviceCounters holds the atomic counters of the resolution metrics of a single service.
+type serviceCounters struct {
+	constructions atomic.Uint64
+	cacheHits     atomic.Uint64
+	duration      atomic.Int64
+}
+
+// metricsCollector collects resolution metrics as an observer of the container.
+type metricsCollector struct {
+	NopObserver
+	counters diutils.AsyncMap[string, *serviceCounters]
+}
+
+// newMetricsCollector creates a metrics collector without counters.
+func newMetricsCollector() *metricsCollector {
+	return &metricsCollector{
+		counters: diutils.NewAsyncMap[string, *serviceCounters](),
+	}
+}
+
+// OnResolveEnd counts the successful resolution of the service with the given key, either from the cache or by construction.
+func (m *metricsCollector) OnResolveEnd(key string, cached bool, d time.Duration, err error) {
+	if err != nil {
+		return
+	}
+
+	counters, exists := m.counters.Get(key)
+	if !exists {
+		counters, _ = m.counters.LoadOrStore(key, &serviceCounters{})
+	}
+	if cached {
+		counters.cacheHits.Add(1)
+		return
+	}
+	counters.constructions.Add(1)
+	counters.duration.Add(int64(d))
+}
+
+// snapshot returns the current value of the counters of every service.
+func (m *metricsCollector) snapshot() ContainerMetrics {
+	metrics := make(ContainerMetrics, m.counters.Len())
+	m.counters.Range(func(key string, counters *serviceCounters) bool {
+		metrics[key] = ServiceMetrics{
+			Constructions:             counters.constructions.Load(),
+			CacheHits:                 counters.cacheHits.Load(),
+			TotalConstructionDuration: time.Duration(counters.duration.Load()),
+		}
+		return true
+	})
+	return metrics
+}
@@ -0,0 +1,50 @@
+package di
+
+import (
+	"testing"
+
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+func TestMetrics_CountsConstructionsAndCacheHits(t *testing.T) {
+	c := NewContainer(WithMetrics())
+
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := Resolve[*depA](c, nil); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+		if _, err := Resolve[*depB](c, nil); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+	}
+
+	metrics := c.Metrics()
+	singleton := metrics[diutils.NameOf[*depA]()]
+	if singleton.Constructions != 1 || singleton.CacheHits != 1 {
+		t.Fatalf("expected 1 construction and 1 cache hit for the singleton, got %+v", singleton)
+	}
+	transient := metrics[diutils.NameOf[*depB]()]
+	if transient.Constructions != 2 || transient.CacheHits != 0 {
+		t.Fatalf("expected 2 constructions and no cache hit for the transient, got %+v", transient)
+	}
+}
+
+func TestMetrics_DisabledByDefault(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Resolve[*depA](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if metrics := c.Metrics(); len(metrics) != 0 {
+		t.Fatalf("expected no metrics without WithMetrics, got %v", metrics)
+	}
+}
@@ -0,0 +1,52 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Module bundles a set of related registrations, so they can be installed in a container with a single call.
+type Module interface {
+	// Register registers the services of the module with the given container.
+	Register(c Container) error
+}
+
+// NamedModule is a Module reporting its name, the name is used to identify the module in installation errors.
+type NamedModule interface {
+	Module
+	Name() string
+}
+
+// ModuleFunc is an adapter to allow the use of ordinary functions as modules.
+type ModuleFunc func(c Container) error
+
+// Register calls f(c).
+func (f ModuleFunc) Register(c Container) error {
+	return f(c)
+}
+
+// moduleName returns the name of the module if it implements NamedModule, or its index otherwise.
+func moduleName(module Module, index int) string {
+	if named, ok := module.(NamedModule); ok {
+		return fmt.Sprintf("%q (index %d)", named.Name(), index)
+	}
+	return fmt.Sprintf("at index %d", index)
+}
+
+// Install registers the services of the given modules with the container, in order.
+//
+// All modules are installed even if one of them fails, the errors are joined and reference
+// the module they come from by name, for modules implementing NamedModule, or by index.
+func (c *containerImpl) Install(modules ...Module) error {
+	var errs []error
+	for i, module := range modules {
+		if module == nil {
+			errs = append(errs, fmt.Errorf("module %s is nil", moduleName(module, i)))
+			continue
+		}
+		if err := module.Register(c); err != nil {
+			errs = append(errs, fmt.Errorf("failed to install module %s: %w", moduleName(module, i), err))
+		}
+	}
+	return errors.Join(errs...)
+}
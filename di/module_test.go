@@ -0,0 +1,62 @@
+package di
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type persistenceModule struct{}
+
+func (m persistenceModule) Name() string {
+	return "persistence"
+}
+
+func (m persistenceModule) Register(c Container) error {
+	return Register[*depA](c, Singleton, func() *depA { return &depA{name: "db"} })
+}
+
+func TestContainer_Install(t *testing.T) {
+	c := NewContainer()
+
+	err := c.Install(persistenceModule{}, ModuleFunc(func(c Container) error {
+		return Register[*depB](c, Transient, func(a *depA) *depB { return &depB{name: a.name} })
+	}))
+	if err != nil {
+		t.Fatalf("unexpected install error: %v", err)
+	}
+
+	b, err := Resolve[*depB](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if b.name != "db" {
+		t.Fatalf("expected services of both modules to be registered, got %s", b.name)
+	}
+}
+
+func TestContainer_Install_ReportsFailingModule(t *testing.T) {
+	c := NewContainer()
+	errHTTP := errors.New("http module failed")
+
+	err := c.Install(
+		persistenceModule{},
+		ModuleFunc(func(c Container) error { return errHTTP }),
+		persistenceModule{},
+	)
+	if err == nil {
+		t.Fatal("expected install error")
+	}
+	if !errors.Is(err, errHTTP) {
+		t.Fatalf("expected the module error to be wrapped, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "module at index 1") {
+		t.Fatalf("expected the failing module index in the error, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), `module "persistence" (index 2)`) {
+		t.Fatalf("expected the failing module name in the error, got: %v", err)
+	}
+	if strings.Contains(err.Error(), "index 0") {
+		t.Fatalf("expected the first module to be installed, got: %v", err)
+	}
+}
@@ -0,0 +1,78 @@
+package di
+
+import (
+	"reflect"
+
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+// namedDependencyType is the reflected type of the namedDependency interface.
+var namedDependencyType = diutils.TypeOf[namedDependency]()
+
+// namedDependency is implemented by the Named type, so the container can inject it without knowing its type parameters.
+type namedDependency interface {
+	// namedTarget returns the type and the registry key of the service injected by the named dependency.
+	namedTarget() (reflect.Type, string)
+	// wrap returns a named dependency holding the given instance of its service.
+	wrap(instance reflect.Value) reflect.Value
+}
+
+// isNamedDependency indicates whether the given factory parameter type is a Named dependency.
+func isNamedDependency(paramType reflect.Type) bool {
+	return paramType.Kind() == reflect.Struct && paramType.Implements(namedDependencyType)
+}
+
+// namedTarget returns the type and the registry key of the service injected by the given Named dependency type.
+func namedTarget(paramType reflect.Type) (reflect.Type, string) {
+	return reflect.Zero(paramType).Interface().(namedDependency).namedTarget()
+}
+
+// wrapNamed returns a Named dependency of the given type holding the given instance of its service.
+func wrapNamed(paramType reflect.Type, instance reflect.Value) reflect.Value {
+	return reflect.Zero(paramType).Interface().(namedDependency).wrap(instance)
+}
+
+// Qualifier is implemented by the marker types selecting the key of a Named dependency.
+// Its Key method is called on the zero value of the type, so it is typically an empty struct:
+//
+//	type Fast struct{}
+//
+//	func (Fast) Key() string { return "cache.fast" }
+type Qualifier interface {
+	Key() string
+}
+
+// Named injects the service of type T registered with the key given by the qualifier Q,
+// instead of the service registered with the default key of T.
+//
+// It disambiguates services implementing the same interface: when a factory function declares a Named[T, Q] parameter,
+// the container resolves the service registered with the key returned by Q's Key method, and the qualified service
+// is part of the dependency tree, so it is validated like any other dependency.
+type Named[T any, Q Qualifier] struct {
+	value T
+}
+
+// Get returns the injected service.
+func (n Named[T, Q]) Get() T {
+	return n.value
+}
+
+// Key returns the key of the injected service.
+func (n Named[T, Q]) Key() string {
+	var qualifier Q
+	return qualifier.Key()
+}
+
+func (n Named[T, Q]) namedTarget() (reflect.Type, string) {
+	return diutils.TypeOf[T](), n.Key()
+}
+
+func (n Named[T, Q]) wrap(instance reflect.Value) reflect.Value {
+	named := Named[T, Q]{}
+	if instance.IsValid() {
+		if value, ok := instance.Interface().(T); ok {
+			named.value = value
+		}
+	}
+	return reflect.ValueOf(named)
+}
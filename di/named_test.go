@@ -0,0 +1,99 @@
+package di
+
+import (
+	"errors"
+	"testing"
+)
+
+type namedCache interface {
+	Name() string
+}
+
+type memoryCache struct{}
+
+func (memoryCache) Name() string { return "memory" }
+
+type diskCache struct{}
+
+func (diskCache) Name() string { return "disk" }
+
+type fastQualifier struct{}
+
+func (fastQualifier) Key() string { return "cache.fast" }
+
+type slowQualifier struct{}
+
+func (slowQualifier) Key() string { return "cache.slow" }
+
+type cacheConsumer struct {
+	cache namedCache
+}
+
+func registerNamedCaches(t *testing.T, c Container) {
+	t.Helper()
+	if err := RegisterWithKey[namedCache](c, "cache.fast", Singleton, func() namedCache { return memoryCache{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterWithKey[namedCache](c, "cache.slow", Singleton, func() namedCache { return diskCache{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+}
+
+func TestNamed_InjectsQualifiedServices(t *testing.T) {
+	c := NewContainer()
+	registerNamedCaches(t, c)
+	if err := RegisterWithKey[*cacheConsumer](c, "consumer.fast", Transient, func(cache Named[namedCache, fastQualifier]) *cacheConsumer {
+		return &cacheConsumer{cache: cache.Get()}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterWithKey[*cacheConsumer](c, "consumer.slow", Transient, func(cache Named[namedCache, slowQualifier]) *cacheConsumer {
+		return &cacheConsumer{cache: cache.Get()}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatalf("unexpected validate error: %v", err)
+	}
+
+	fast, err := ResolveWithKey[*cacheConsumer](c, "consumer.fast", nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if fast.cache.Name() != "memory" {
+		t.Fatalf("expected the fast cache, got %s", fast.cache.Name())
+	}
+
+	slow, err := ResolveWithKey[*cacheConsumer](c, "consumer.slow", nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if slow.cache.Name() != "disk" {
+		t.Fatalf("expected the slow cache, got %s", slow.cache.Name())
+	}
+
+	deps := c.DependencyGraph()["consumer.fast"]
+	if len(deps) != 1 || deps[0] != "cache.fast" {
+		t.Fatalf("expected the consumer to depend on cache.fast, got %v", deps)
+	}
+}
+
+func TestNamed_MissingQualifiedServiceFailsValidation(t *testing.T) {
+	c := NewContainer()
+	if err := RegisterWithKey[namedCache](c, "cache.slow", Singleton, func() namedCache { return diskCache{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*cacheConsumer](c, Transient, func(cache Named[namedCache, fastQualifier]) *cacheConsumer {
+		return &cacheConsumer{cache: cache.Get()}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if err := c.Validate(); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected ErrNotRegistered from Validate, got %v", err)
+	}
+	if _, err := Resolve[*cacheConsumer](c, nil); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected ErrNotRegistered from Resolve, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package di
+
+import (
+	"sync/atomic"
+
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+// negativeCache remembers the keys known not to be registered, so repeated lookups of missing keys,
+// such as optional services probed on hot paths, fail fast with the same error.
+//
+// Each miss is recorded with the generation of the registry it was observed in, and invalidate moves
+// to the next generation, so a miss recorded while a registration adds its key is never served.
+type negativeCache struct {
+	generation atomic.Uint64
+	misses     diutils.AsyncMap[string, negativeEntry]
+}
+
+// negativeEntry is a miss recorded by the negative cache.
+type negativeEntry struct {
+	generation uint64 // Generation of the registry the miss was observed in
+	err        error  // Error returned for the missing key
+}
+
+// newNegativeCache creates an empty negative cache.
+func newNegativeCache() *negativeCache {
+	return &negativeCache{misses: diutils.NewAsyncMap[string, negativeEntry]()}
+}
+
+// get returns the error recorded for the given key, if it is still known not to be registered.
+func (n *negativeCache) get(key string) (error, bool) {
+	miss, ok := n.misses.Get(key)
+	if !ok || miss.generation != n.generation.Load() {
+		return nil, false
+	}
+	return miss.err, true
+}
+
+// current returns the generation of the registry, to be read before looking a key up in the registry.
+func (n *negativeCache) current() uint64 {
+	return n.generation.Load()
+}
+
+// record remembers that the given key was missing from the registry in the given generation.
+func (n *negativeCache) record(key string, generation uint64, err error) {
+	n.misses.Set(key, negativeEntry{generation: generation, err: err})
+}
+
+// invalidate forgets the recorded misses. It must be called after the registry is changed.
+func (n *negativeCache) invalidate() {
+	n.generation.Add(1)
+	n.misses.Cleanup()
+}
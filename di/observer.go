@@ -0,0 +1,83 @@
+package di
+
+import (
+	"time"
+)
+
+// Observer receives the lifecycle events of a container, for example to collect metrics or traces.
+//
+// Observers are called synchronously from the goroutine performing the operation, without holding container locks,
+// so they should return quickly. A panic raised by an observer is recovered and logged, it never fails the operation.
+type Observer interface {
+	// OnRegister is called after a service is registered with the given key and scope.
+	OnRegister(key string, scope LifecycleScope)
+	// OnResolveStart is called before the instance of the service with the given key is looked up or created.
+	OnResolveStart(key string)
+	// OnResolveEnd is called once the instance of the service with the given key is resolved.
+	// The cached flag indicates whether the instance was served from a lifecycle context cache,
+	// d is the duration of the resolution and err the error returned by the resolution, if any.
+	OnResolveEnd(key string, cached bool, d time.Duration, err error)
+	// OnDispose is called after an instance stored under the given key is disposed, with the disposal error, if any.
+	OnDispose(key string, err error)
+}
+
+// NopObserver is an Observer ignoring all events.
+// It can be embedded in custom observers to only implement the events they need.
+type NopObserver struct{}
+
+func (NopObserver) OnRegister(string, LifecycleScope)               {}
+func (NopObserver) OnResolveStart(string)                           {}
+func (NopObserver) OnResolveEnd(string, bool, time.Duration, error) {}
+func (NopObserver) OnDispose(string, error)                         {}
+
+// AddObserver adds an observer notified of the registrations, resolutions, and disposals of the container.
+// A nil observer is ignored.
+func (c *containerImpl) AddObserver(observer Observer) {
+	if observer == nil {
+		return
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	// Publish a new slice, so notifications can iterate over the observers without locking
+	current := c.observers.Load()
+	observers := make([]Observer, 0, 1)
+	if current != nil {
+		observers = append(observers, *current...)
+	}
+	observers = append(observers, observer)
+	c.observers.Store(&observers)
+}
+
+// hasObservers indicates whether at least one observer has been added to the container.
+func (c *containerImpl) hasObservers() bool {
+	observers := c.observers.Load()
+	return observers != nil && len(*observers) > 0
+}
+
+// notify calls the given function for each observer of the container.
+// A panic raised by an observer is recovered and logged, so the other observers are still notified.
+func (c *containerImpl) notify(event func(Observer)) {
+	observers := c.observers.Load()
+	if observers == nil {
+		return
+	}
+	for _, observer := range *observers {
+		func() {
+			defer func() {
+				if r := recover(); r != nil {
+					c.logger.Errorf("Observer %T panicked: %v", observer, r)
+				}
+			}()
+			event(observer)
+		}()
+	}
+}
+
+// notifyDispose notifies the observers of the container that an instance has been disposed.
+func (c *containerImpl) notifyDispose(key string, err error) {
+	c.notify(func(o Observer) {
+		o.OnDispose(key, err)
+	})
+}
@@ -0,0 +1,131 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+// recordingObserver records the events it receives as strings.
+type recordingObserver struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (o *recordingObserver) record(format string, v ...interface{}) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.events = append(o.events, fmt.Sprintf(format, v...))
+}
+
+func (o *recordingObserver) Events() []string {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	return append([]string(nil), o.events...)
+}
+
+func (o *recordingObserver) OnRegister(key string, scope LifecycleScope) {
+	o.record("register %s %s", key, scope)
+}
+
+func (o *recordingObserver) OnResolveStart(key string) {
+	o.record("start %s", key)
+}
+
+func (o *recordingObserver) OnResolveEnd(key string, cached bool, _ time.Duration, err error) {
+	o.record("end %s cached=%t err=%t", key, cached, err != nil)
+}
+
+func (o *recordingObserver) OnDispose(key string, err error) {
+	o.record("dispose %s err=%t", key, err != nil)
+}
+
+type panickingObserver struct {
+	NopObserver
+}
+
+func (panickingObserver) OnResolveStart(string) {
+	panic("observer failure")
+}
+
+func TestObserver_SingletonCacheMissThenHit(t *testing.T) {
+	c := NewContainer()
+	observer := &recordingObserver{}
+	c.AddObserver(observer)
+
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		if _, err := Resolve[*depA](c, nil); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+	}
+
+	key := diutils.NameOf[*depA]()
+	expected := []string{
+		"register " + key + " Singleton",
+		"start " + key,
+		"end " + key + " cached=false err=false",
+		"start " + key,
+		"end " + key + " cached=true err=false",
+	}
+	if events := observer.Events(); !reflect.DeepEqual(events, expected) {
+		t.Fatalf("expected events %v, got %v", expected, events)
+	}
+}
+
+func TestObserver_DisposeOnShutdown(t *testing.T) {
+	c := NewContainer()
+	observer := &recordingObserver{}
+	c.AddObserver(observer)
+
+	if err := Register[*closerErr](c, Singleton, func() *closerErr { return &closerErr{closed: new(int32)} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Resolve[*closerErr](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if err := c.Shutdown(); err == nil {
+		t.Fatal("expected shutdown error from the failing closer")
+	}
+
+	expected := "dispose " + diutils.NameOf[*closerErr]() + " err=true"
+	events := observer.Events()
+	if events[len(events)-1] != expected {
+		t.Fatalf("expected last event %q, got %v", expected, events)
+	}
+}
+
+func TestObserver_PanicDoesNotFailResolution(t *testing.T) {
+	logger, messages := recordingLogger()
+	c := NewContainer(WithLogger(logger))
+	c.AddObserver(panickingObserver{})
+	observer := &recordingObserver{}
+	c.AddObserver(observer)
+
+	if err := Register[*depA](c, Transient, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Resolve[*depA](c, nil); err != nil {
+		t.Fatalf("expected the observer panic to be recovered, got: %v", err)
+	}
+	if len(observer.Events()) != 3 {
+		t.Fatalf("expected the other observers to be notified, got %v", observer.Events())
+	}
+
+	logged := false
+	for _, message := range messages() {
+		if strings.Contains(message, "observer failure") {
+			logged = true
+		}
+	}
+	if !logged {
+		t.Fatal("expected the observer panic to be logged")
+	}
+}
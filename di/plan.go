@@ -0,0 +1,44 @@
+package di
+
+import (
+	"fmt"
+)
+
+// PlanStep describes a service resolved by a resolution, as reported by ResolutionPlan.
+type PlanStep struct {
+	Key    string         // The registry key of the service
+	Scope  LifecycleScope // The lifecycle scope of the service
+	Cached bool           // Whether the instance would be served from a cache rather than created
+}
+
+// ResolutionPlan returns the steps of the resolution of the service registered with the given key within the provided
+// lifecycle context, in the order the services would be resolved, without invoking any factory function.
+//
+// Each step reports whether its instance is already cached. The Container, LifecycleContext and custom special dependencies,
+// the Lazy and provider dependencies resolved on demand, the Named wrappers of their qualified services,
+// and the In parameter structs of their fields, are not part of the plan.
+func (c *containerImpl) ResolutionPlan(key string, ctx LifecycleContext) ([]PlanStep, error) {
+	ctx = c.resolveContext(ctx)
+
+	if _, err := c.getEntry(key); err != nil {
+		return nil, err
+	}
+	dependencies, err := c.getDependencyTree(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to plan resolution of %s: %w", key, err)
+	}
+
+	plan := make([]PlanStep, 0, len(dependencies))
+	for _, entry := range dependencies {
+		if entry.lazy || entry.provider || entry.named || entry.in || entry.slice || c.isSpecial(entry.key) {
+			continue
+		}
+		_, cached := c.loadInstance(ctx, entry)
+		plan = append(plan, PlanStep{
+			Key:    entry.key,
+			Scope:  entry.scope,
+			Cached: cached,
+		})
+	}
+	return plan, nil
+}
@@ -0,0 +1,54 @@
+package di
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+func TestContainer_ResolutionPlan(t *testing.T) {
+	c := NewContainer()
+	created := 0
+
+	if err := Register[*depA](c, Singleton, func() *depA {
+		created++
+		return &depA{}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func(a *depA, c Container) *depB {
+		created++
+		return &depB{}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Resolve[*depA](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	plan, err := c.ResolutionPlan(diutils.NameOf[*depB](), nil)
+	if err != nil {
+		t.Fatalf("unexpected plan error: %v", err)
+	}
+
+	expected := []PlanStep{
+		{Key: diutils.NameOf[*depA](), Scope: Singleton, Cached: true},
+		{Key: diutils.NameOf[*depB](), Scope: Transient, Cached: false},
+	}
+	if !reflect.DeepEqual(plan, expected) {
+		t.Fatalf("expected plan %+v, got %+v", expected, plan)
+	}
+	if created != 1 {
+		t.Fatalf("expected the plan not to invoke factories, got %d invocations", created)
+	}
+}
+
+func TestContainer_ResolutionPlan_NotRegistered(t *testing.T) {
+	c := NewContainer()
+
+	if _, err := c.ResolutionPlan(diutils.NameOf[*depA](), nil); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected not registered error, got: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package di
+
+import (
+	"fmt"
+	"sync"
+
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+// RegisterPooled registers a Transient service of type T backed by a sync.Pool, for services that are cheap
+// but allocation-heavy, such as request DTOs or buffers.
+//
+// Each resolution takes an instance from the pool, creating it with the factory function when the pool is empty,
+// and calls reset on it before handing it out. The instance is not shared: the lifecycle context of the resolution
+// returns it to the pool when the context is disposed, so it must not be used afterwards. The pooled instances
+// are not stored as instances of the context, and are not counted by Count, Keys or InstanceCounts.
+// Instances resolved within the background context, or within a context not created by the container,
+// are never returned to the pool, as the background context lives as long as the container.
+// The resolution fails if the factory function returns a nil instance.
+//
+// Parameters:
+//
+// Container: The container instance in which to register the service.
+//
+// FactoryFn: The function creating new instances when the pool is empty.
+//
+// Reset: The function restoring an instance to its initial state before it is handed out, it may be nil.
+func RegisterPooled[T any](c Container, factoryFn func() T, reset func(T)) error {
+	if c == nil {
+		return ErrNilContainer
+	}
+	if factoryFn == nil {
+		return fmt.Errorf("factoryFn cannot be nil")
+	}
+
+	key := KeyOf[T]()
+	pool := &sync.Pool{New: func() interface{} { return factoryFn() }}
+
+	return c.Register(diutils.TypeOf[T](), key, Transient, func(c Container, ctx LifecycleContext) T {
+		instance, ok := pool.Get().(T)
+		if !ok {
+			panic(factoryError{err: fmt.Errorf("factory for pooled service %s returned a nil instance", key)})
+		}
+		if reset != nil {
+			reset(instance)
+		}
+		// Return the instance to the pool when the context is disposed, an instance resolved within
+		// the background context or a closed context is left to the garbage collector
+		if lctx, ok := ctx.(*lifecycleContextImpl); ok && ctx.ID() != c.BackgroundContext().ID() {
+			lctx.onRelease(func() { pool.Put(instance) })
+		}
+		return instance
+	})
+}
@@ -0,0 +1,142 @@
+package di
+
+import (
+	"errors"
+	"testing"
+)
+
+type pooledBuffer struct {
+	data []byte
+}
+
+func TestRegisterPooled_ResetsReusedInstances(t *testing.T) {
+	c := NewContainer()
+	resets := 0
+	if err := RegisterPooled[*pooledBuffer](c, func() *pooledBuffer {
+		return &pooledBuffer{data: make([]byte, 0, 64)}
+	}, func(b *pooledBuffer) {
+		resets++
+		b.data = b.data[:0]
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	// The pool may drop instances, so resolve until one is reused
+	seen := make(map[*pooledBuffer]bool)
+	reused := false
+	for i := 0; i < 100 && !reused; i++ {
+		ctx := c.NewContext()
+		buffer, err := Resolve[*pooledBuffer](c, ctx)
+		if err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+		if len(buffer.data) != 0 {
+			t.Fatalf("expected a reset buffer, got %q", buffer.data)
+		}
+		if again, _ := Resolve[*pooledBuffer](c, ctx); again == buffer {
+			t.Fatal("expected pooled instances not to be shared within a context")
+		}
+		reused = seen[buffer]
+		seen[buffer] = true
+		buffer.data = append(buffer.data, "dirty"...)
+
+		if err := c.RemoveContext(ctx); err != nil {
+			t.Fatalf("unexpected remove context error: %v", err)
+		}
+	}
+	if !reused {
+		t.Fatal("expected an instance to be reused once its context was disposed")
+	}
+	if resets == 0 {
+		t.Fatal("expected reset to be called")
+	}
+}
+
+func TestRegisterPooled_DoesNotTrackInstances(t *testing.T) {
+	c := NewContainer()
+	if err := RegisterPooled[*pooledBuffer](c, func() *pooledBuffer { return &pooledBuffer{} }, nil); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	before := c.BackgroundContext().Count()
+	for i := 0; i < 10; i++ {
+		if _, err := Resolve[*pooledBuffer](c, nil); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+	}
+	if count := c.BackgroundContext().Count(); count != before {
+		t.Fatalf("expected background resolutions not to grow the background context, got %d instances instead of %d", count, before)
+	}
+
+	ctx := c.NewContext()
+	for i := 0; i < 10; i++ {
+		if _, err := Resolve[*pooledBuffer](c, ctx); err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+	}
+	if ctx.Count() != 0 || len(ctx.Keys()) != 0 {
+		t.Fatalf("expected pooled instances not to be stored in the context, got keys %v", ctx.Keys())
+	}
+	if counts := c.InstanceCounts(); counts[ctx.ID()] != 0 {
+		t.Fatalf("expected pooled instances not to be counted, got %v", counts)
+	}
+}
+
+type pooledService interface {
+	Reset()
+}
+
+func TestRegisterPooled_NilInstance(t *testing.T) {
+	c := NewContainer()
+	if err := RegisterPooled[pooledService](c, func() pooledService { return nil }, nil); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if _, err := c.Resolve(KeyOf[pooledService](), c.NewContext()); err == nil {
+		t.Fatal("expected an error for a nil pooled instance")
+	}
+	if _, err := Resolve[pooledService](c, nil); err == nil || errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected a nil instance error, got %v", err)
+	}
+}
+
+func TestRegisterPooled_Errors(t *testing.T) {
+	if err := RegisterPooled[*pooledBuffer](nil, func() *pooledBuffer { return &pooledBuffer{} }, nil); err != ErrNilContainer {
+		t.Fatalf("expected ErrNilContainer, got %v", err)
+	}
+	if err := RegisterPooled[*pooledBuffer](NewContainer(), nil, nil); err == nil {
+		t.Fatal("expected an error for a nil factory function")
+	}
+}
+
+func BenchmarkResolve_Pooled(b *testing.B) {
+	newBuffer := func() *pooledBuffer { return &pooledBuffer{data: make([]byte, 0, 4096)} }
+	for _, bc := range []struct {
+		name     string
+		register func(c Container) error
+	}{
+		{name: "Transient", register: func(c Container) error {
+			return Register[*pooledBuffer](c, Transient, newBuffer)
+		}},
+		{name: "Pooled", register: func(c Container) error {
+			return RegisterPooled[*pooledBuffer](c, newBuffer, func(buf *pooledBuffer) { buf.data = buf.data[:0] })
+		}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			c := NewContainer()
+			if err := bc.register(c); err != nil {
+				b.Fatalf("unexpected register error: %v", err)
+			}
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				ctx := c.NewContext()
+				if _, err := Resolve[*pooledBuffer](c, ctx); err != nil {
+					b.Fatalf("unexpected resolve error: %v", err)
+				}
+				_ = c.RemoveContext(ctx)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package di
+
+import (
+	"context"
+	"reflect"
+
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+// errorType is the reflected type of the error interface.
+var errorType = diutils.TypeOf[error]()
+
+// Provider creates instances of a service of type T on demand.
+//
+// When a factory function declares a Provider[T] parameter, the container injects a function resolving T
+// from the container and the lifecycle context of the resolution, each time it is called.
+// Transient services get a new instance on every call, while Singleton and Scoped services are shared as usual.
+//
+// A factory function can also declare a func() T parameter, which panics if T cannot be resolved.
+type Provider[T any] func() (T, error)
+
+// isProviderDependency indicates whether the given factory parameter type is a provider, that is a function
+// without parameters returning a value, and optionally an error.
+func isProviderDependency(paramType reflect.Type) bool {
+	if paramType.Kind() != reflect.Func || paramType.NumIn() != 0 {
+		return false
+	}
+	switch paramType.NumOut() {
+	case 1:
+		return true
+	case 2:
+		return paramType.Out(1) == errorType
+	default:
+		return false
+	}
+}
+
+// providerTargetKey returns the registry key of the service created by the given provider type.
+func providerTargetKey(paramType reflect.Type) string {
+	return diutils.NameOfType(paramType.Out(0))
+}
+
+// newProvider creates a provider of the given type, resolving its service from the container and lifecycle context
+// at the given resolution depth, and starting its construction spans from the given trace context.
+// Providers without an error result panic if the service cannot be resolved.
+func (c *containerImpl) newProvider(paramType reflect.Type, ctx LifecycleContext, depth int, trace context.Context) reflect.Value {
+	targetKey := providerTargetKey(paramType)
+	targetType := paramType.Out(0)
+
+	return reflect.MakeFunc(paramType, func(_ []reflect.Value) []reflect.Value {
+		value := reflect.New(targetType).Elem()
+		inst, err := c.resolveAtDepth(targetKey, ctx, depth, trace)
+		if err == nil && inst != nil {
+			value.Set(reflect.ValueOf(inst))
+		}
+
+		if paramType.NumOut() == 1 {
+			if err != nil {
+				panic(err)
+			}
+			return []reflect.Value{value}
+		}
+
+		errValue := reflect.New(errorType).Elem()
+		if err != nil {
+			errValue.Set(reflect.ValueOf(err))
+		}
+		return []reflect.Value{value, errValue}
+	})
+}
+
+// depthContainer is a container resolving services at a fixed resolution depth, it is bound to Lazy dependencies
+// so their resolutions are nested within the resolution that injected them.
+type depthContainer struct {
+	*containerImpl
+	depth int
+	trace context.Context // Context the construction spans start from, nil for root spans
+}
+
+// atDepth returns the container resolving services at the given resolution depth,
+// and starting their construction spans from the given trace context.
+func (c *containerImpl) atDepth(depth int, trace context.Context) Container {
+	return &depthContainer{containerImpl: c, depth: depth, trace: trace}
+}
+
+// Resolve resolves the service identified by the given key at the resolution depth of the container.
+func (d *depthContainer) Resolve(key string, ctx LifecycleContext) (interface{}, error) {
+	return d.resolveAtDepth(key, ctx, d.depth, d.trace)
+}
+
+// withTrace returns the container resolving services at the same resolution depth,
+// and starting their construction spans from the given trace context.
+func (d *depthContainer) withTrace(trace context.Context) Container {
+	return &depthContainer{containerImpl: d.containerImpl, depth: d.depth, trace: trace}
+}
@@ -0,0 +1,119 @@
+package di
+
+import (
+	"testing"
+)
+
+type job struct {
+	id int
+}
+
+type worker struct {
+	newJob func() *job
+}
+
+type providerConsumer struct {
+	provider Provider[*depA]
+}
+
+func TestProvider_FuncCreatesTransientInstances(t *testing.T) {
+	c := NewContainer()
+	next := 0
+
+	if err := Register[*job](c, Transient, func() *job {
+		next++
+		return &job{id: next}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*worker](c, Singleton, func(newJob func() *job) *worker {
+		return &worker{newJob: newJob}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.Validate(); err != nil {
+		t.Fatalf("expected no validation error, got: %v", err)
+	}
+
+	w, err := Resolve[*worker](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if next != 0 {
+		t.Fatal("expected no job to be created before the provider is called")
+	}
+
+	first, second := w.newJob(), w.newJob()
+	if first == second || first.id != 1 || second.id != 2 {
+		t.Fatalf("expected distinct transient instances, got %d and %d", first.id, second.id)
+	}
+}
+
+func TestProvider_SharesSingleton(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*providerConsumer](c, Transient, func(provider Provider[*depA]) *providerConsumer {
+		return &providerConsumer{provider: provider}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	consumer, err := Resolve[*providerConsumer](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	first, err := consumer.provider()
+	if err != nil {
+		t.Fatalf("unexpected provider error: %v", err)
+	}
+	second, err := consumer.provider()
+	if err != nil {
+		t.Fatalf("unexpected provider error: %v", err)
+	}
+	if first != second {
+		t.Fatal("expected the provider to return the shared singleton")
+	}
+}
+
+func TestProvider_RequiresTarget(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*worker](c, Transient, func(newJob func() *job) *worker {
+		return &worker{newJob: newJob}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if err := c.Validate(); err == nil {
+		t.Fatal("expected validation error for unregistered provider target")
+	}
+	if _, err := Resolve[*worker](c, nil); err == nil {
+		t.Fatal("expected resolve error for unregistered provider target")
+	}
+}
+
+func TestProvider_RegisteredFuncTypeIsInjectedAsIs(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[func() string](c, Singleton, func() func() string {
+		return func() string { return "registered" }
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depA](c, Transient, func(name func() string) *depA {
+		return &depA{name: name()}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	a, err := Resolve[*depA](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if a.name != "registered" {
+		t.Fatalf("expected the registered function to be injected, got %s", a.name)
+	}
+}
@@ -2,7 +2,9 @@ package di
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
+	"time"
 
 	diutils "github.com/lcrux/go-di/di/di-utils"
 )
@@ -12,6 +14,10 @@ import (
 // The factory function must be a function that returns exactly one value of type T.
 // The scope determines the lifetime of the service instance (Transient, Singleton, Scoped).
 //
+// When T is a value type, such as a struct, the factory function of a Singleton or Scoped service is still called once,
+// and every resolution returns a copy of the cached value. Copies have the same content, but changing the fields
+// of a copy does not change the cached value, register a pointer type to share a mutable instance.
+//
 // Parameters:
 //
 // Container: The container instance in which to register the service.
@@ -20,9 +26,14 @@ import (
 //
 // FactoryFn: The factory function used to create instances of the service.
 func Register[T any](c Container, scope LifecycleScope, factoryFn interface{}) error {
-	serviceType := diutils.TypeOf[T]()
-	key := diutils.NameOfType(serviceType)
-	return RegisterWithKey[T](c, key, scope, factoryFn)
+	return RegisterWithKey[T](c, KeyOf[T](), scope, factoryFn)
+}
+
+// KeyOf returns the key used by Register and Resolve for services of type T, such as
+// "*github.com/org/app/services.UserService". Use it to register or resolve with explicit keys
+// matching the default ones, or to look up the keys reported by container errors.
+func KeyOf[T any]() string {
+	return diutils.NameOfType(diutils.TypeOf[T]())
 }
 
 // RegisterWithKey registers a service of type T with the container using the provided key, factory function, and lifecycle scope.
@@ -41,7 +52,7 @@ func Register[T any](c Container, scope LifecycleScope, factoryFn interface{}) e
 // FactoryFn: The factory function used to create instances of the service.
 func RegisterWithKey[T any](c Container, key string, scope LifecycleScope, factoryFn interface{}) error {
 	if c == nil {
-		return fmt.Errorf("container cannot be nil")
+		return ErrNilContainer
 	}
 	if strings.TrimSpace(key) == "" {
 		return fmt.Errorf("key cannot be empty")
@@ -50,3 +61,309 @@ func RegisterWithKey[T any](c Container, key string, scope LifecycleScope, facto
 	serviceType := diutils.TypeOf[T]()
 	return c.Register(serviceType, key, scope, factoryFn)
 }
+
+// MustRegister registers a service of type T with the container using the provided factory function and lifecycle scope.
+// Panics if the service cannot be registered.
+//
+// Parameters:
+//
+// Container: The container instance in which to register the service.
+//
+// Scope: The lifecycle scope of the service (Transient, Singleton, Scoped).
+//
+// FactoryFn: The factory function used to create instances of the service.
+func MustRegister[T any](c Container, scope LifecycleScope, factoryFn interface{}) {
+	if err := Register[T](c, scope, factoryFn); err != nil {
+		panic(err)
+	}
+}
+
+// MustRegisterWithKey registers a service of type T with the container using the provided key, factory function, and lifecycle scope.
+// Panics if the service cannot be registered.
+//
+// Parameters:
+//
+// Container: The container instance in which to register the service.
+//
+// Key: The key associated with the service to register.
+//
+// Scope: The lifecycle scope of the service (Transient, Singleton, Scoped).
+//
+// FactoryFn: The factory function used to create instances of the service.
+func MustRegisterWithKey[T any](c Container, key string, scope LifecycleScope, factoryFn interface{}) {
+	if err := RegisterWithKey[T](c, key, scope, factoryFn); err != nil {
+		panic(err)
+	}
+}
+
+// RegisterIf registers a service of type T with the container, like Register, only if the condition is true.
+// It returns nil without registering the service if the condition is false.
+//
+// Parameters:
+//
+// Container: The container instance in which to register the service.
+//
+// Cond: Whether the service should be registered.
+//
+// Scope: The lifecycle scope of the service (Transient, Singleton, Scoped).
+//
+// FactoryFn: The factory function used to create instances of the service.
+func RegisterIf[T any](c Container, cond bool, scope LifecycleScope, factoryFn interface{}) error {
+	if !cond {
+		return nil
+	}
+	return Register[T](c, scope, factoryFn)
+}
+
+// RegisterFunc registers a service of type T with the container, like Register, only if the condition function returns true.
+// It returns nil without registering the service if the condition function is nil or returns false.
+//
+// Parameters:
+//
+// Container: The container instance in which to register the service.
+//
+// Cond: The function evaluated to decide whether the service should be registered.
+//
+// Scope: The lifecycle scope of the service (Transient, Singleton, Scoped).
+//
+// FactoryFn: The factory function used to create instances of the service.
+func RegisterFunc[T any](c Container, cond func() bool, scope LifecycleScope, factoryFn interface{}) error {
+	return RegisterIf[T](c, cond != nil && cond(), scope, factoryFn)
+}
+
+// Candidate describes a factory function to register with RegisterFirst when its predicate passes.
+type Candidate struct {
+	When      func() bool // The predicate selecting the candidate, a nil predicate always passes
+	FactoryFn interface{} // The factory function used to create instances of the service
+}
+
+// RegisterFirst registers a service of type T with the factory function of the first candidate whose predicate passes,
+// for example a Redis cache when REDIS_URL is set, and an in-memory cache otherwise.
+// It returns nil without registering the service if no predicate passes.
+//
+// Parameters:
+//
+// Container: The container instance in which to register the service.
+//
+// Scope: The lifecycle scope of the service (Transient, Singleton, Scoped).
+//
+// Candidates: The candidates to evaluate, in order.
+func RegisterFirst[T any](c Container, scope LifecycleScope, candidates ...Candidate) error {
+	for i, candidate := range candidates {
+		if candidate.When != nil && !candidate.When() {
+			continue
+		}
+		if err := Register[T](c, scope, candidate.FactoryFn); err != nil {
+			return fmt.Errorf("candidate %d failed: %w", i, err)
+		}
+		return nil
+	}
+	return nil
+}
+
+// RegisterScopedOrSingleton registers a service of type T with the ScopedOrSingleton scope, for per-request-or-global services.
+//
+// Resolving the service within a lifecycle context created with NewContext caches one instance per context, like Scoped,
+// while resolving it within the background context, or with a nil context, caches a process-wide instance, like Singleton.
+// Unlike a Scoped service, the background resolutions are intended, so they are neither logged as warnings
+// nor rejected by WithStrictScopes.
+//
+// Parameters:
+//
+// Container: The container instance in which to register the service.
+//
+// FactoryFn: The factory function used to create instances of the service.
+func RegisterScopedOrSingleton[T any](c Container, factoryFn interface{}) error {
+	return Register[T](c, ScopedOrSingleton, factoryFn)
+}
+
+// RegisterScopedWithTTL registers a Scoped service of type T whose instances expire after the given time to live,
+// for example a per-tenant handle in a long-lived lifecycle context.
+//
+// An expired instance is evicted when it is next looked up: it is disposed, if it implements LifecycleListener
+// or io.Closer, and a new instance is created by the factory function.
+//
+// Parameters:
+//
+// Container: The container instance in which to register the service.
+//
+// TTL: The time to live of the instances of the service, it must be positive.
+//
+// FactoryFn: The factory function used to create instances of the service.
+func RegisterScopedWithTTL[T any](c Container, ttl time.Duration, factoryFn interface{}) error {
+	if c == nil {
+		return ErrNilContainer
+	}
+	return c.RegisterScopedWithTTL(diutils.TypeOf[T](), diutils.NameOf[T](), ttl, factoryFn)
+}
+
+// Registration describes a service to register with RegisterMany.
+type Registration struct {
+	Type      reflect.Type   // The type of the service
+	Key       string         // The key of the service, the key of its type is used if empty
+	Scope     LifecycleScope // The lifecycle scope of the service (Transient, Singleton, Scoped)
+	FactoryFn interface{}    // The factory function used to create instances of the service
+}
+
+// RegisterMany registers the given services with the container, in order.
+//
+// It stops at the first registration that fails and returns an error reporting its index and key,
+// the services registered before it are kept.
+//
+// Parameters:
+//
+// Container: The container instance in which to register the services.
+//
+// Registrations: The descriptors of the services to register.
+func RegisterMany(c Container, regs ...Registration) error {
+	if c == nil {
+		return ErrNilContainer
+	}
+	for i, reg := range regs {
+		key := reg.Key
+		if key == "" && reg.Type != nil {
+			key = diutils.NameOfType(reg.Type)
+		}
+		if err := c.Register(reg.Type, key, reg.Scope, reg.FactoryFn); err != nil {
+			return fmt.Errorf("registration %d with key '%s' failed: %w", i, key, err)
+		}
+	}
+	return nil
+}
+
+// Bind registers an implementation of type Impl under the key of the interface type Iface,
+// so the service can be resolved as Iface and injected into factories that depend on Iface.
+//
+// The Impl type must be assignable to Iface, and the factory function must return a value of type Impl.
+// The scope determines the lifetime of the service instance (Transient, Singleton, Scoped).
+//
+// Parameters:
+//
+// Container: The container instance in which to register the service.
+//
+// Scope: The lifecycle scope of the service (Transient, Singleton, Scoped).
+//
+// FactoryFn: The factory function used to create instances of the implementation.
+func Bind[Iface any, Impl any](c Container, scope LifecycleScope, factoryFn interface{}) error {
+	if c == nil {
+		return ErrNilContainer
+	}
+
+	if err := checkImplementation[Iface, Impl](factoryFn); err != nil {
+		return err
+	}
+
+	return Register[Iface](c, scope, factoryFn)
+}
+
+// checkImplementation returns an error if the Impl type is not assignable to the Iface type,
+// or if the factory function does not return a value of type Impl. The other checks are left to Register.
+func checkImplementation[Iface any, Impl any](factoryFn interface{}) error {
+	ifaceType := diutils.TypeOf[Iface]()
+	implType := diutils.TypeOf[Impl]()
+	if !implType.AssignableTo(ifaceType) {
+		return fmt.Errorf("%w: %s is not assignable to %s", ErrTypeMismatch, implType.String(), ifaceType.String())
+	}
+
+	factoryFnType := reflect.TypeOf(factoryFn)
+	if factoryFnType != nil && factoryFnType.Kind() == reflect.Func && factoryFnType.NumOut() == 1 &&
+		!factoryFnType.Out(0).AssignableTo(implType) {
+		return fmt.Errorf("factoryFn must return a value of type %s, returning %s", implType.String(), factoryFnType.Out(0).String())
+	}
+	return nil
+}
+
+// ImplOption configures the registration of an implementation with RegisterImplementation.
+type ImplOption func(*implOptions)
+
+// implOptions holds the settings applied by the ImplOption functions.
+type implOptions struct {
+	isDefault bool
+}
+
+// WithDefault makes the implementation the default one, resolved by the key of its interface type,
+// such as with Resolve[Iface] or when a factory function depends on Iface.
+func WithDefault() ImplOption {
+	return func(o *implOptions) {
+		o.isDefault = true
+	}
+}
+
+// RegisterImplementation registers an implementation of type Impl of the interface type Iface under the given name.
+//
+// Several implementations of the same interface can be registered with different names. Each one is resolved
+// by its name with ResolveWithKey[Iface], and ResolveAll[Iface] resolves all of them. The implementation
+// registered with WithDefault is also resolved by the key of Iface, as an alias of its name, so Resolve[Iface]
+// fails with an error wrapping ErrNotRegistered while no default is designated.
+//
+// Parameters:
+//
+// Container: The container instance in which to register the service.
+//
+// Name: The key of the implementation.
+//
+// Scope: The lifecycle scope of the service (Transient, Singleton, Scoped).
+//
+// FactoryFn: The factory function used to create instances of the implementation.
+//
+// Opts: The options of the registration, such as WithDefault.
+func RegisterImplementation[Iface any, Impl any](c Container, name string, scope LifecycleScope, factoryFn interface{}, opts ...ImplOption) error {
+	if c == nil {
+		return ErrNilContainer
+	}
+
+	var options implOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&options)
+		}
+	}
+
+	if err := checkImplementation[Iface, Impl](factoryFn); err != nil {
+		return err
+	}
+
+	if !options.isDefault {
+		return RegisterWithKey[Iface](c, name, scope, factoryFn)
+	}
+
+	// Register the implementation and its default alias atomically, so a failing alias leaves nothing registered
+	ifaceKey := KeyOf[Iface]()
+	if registrar, ok := c.(defaultRegistrar); ok {
+		return registrar.registerDefault(diutils.TypeOf[Iface](), name, ifaceKey, scope, factoryFn)
+	}
+	if _, exists := c.ScopeOf(ifaceKey); exists {
+		return fmt.Errorf("cannot register %s as the default implementation of %s: a default is already registered", name, diutils.TypeOf[Iface]().String())
+	}
+	if err := RegisterWithKey[Iface](c, name, scope, factoryFn); err != nil {
+		return err
+	}
+	return c.RegisterAlias(ifaceKey, name)
+}
+
+// defaultRegistrar is implemented by the containers of this package, which register an implementation
+// along with its default alias under a single registration lock.
+type defaultRegistrar interface {
+	registerDefault(serviceType reflect.Type, key, defaultKey string, scope LifecycleScope, factoryFn interface{}) error
+}
+
+// RegisterToGroup registers a service of type T as a member of the named group, using the provided factory function.
+//
+// Group members are Transient, a new instance is created every time the group is resolved.
+// The order determines the position of the member in the slice returned by ResolveGroup.
+//
+// Parameters:
+//
+// Container: The container instance in which to register the service.
+//
+// Group: The name of the group the service belongs to.
+//
+// Order: The position of the service within the group, members with the same order keep their registration order.
+//
+// FactoryFn: The factory function used to create instances of the service.
+func RegisterToGroup[T any](c Container, group string, order int, factoryFn interface{}) error {
+	if c == nil {
+		return ErrNilContainer
+	}
+	return c.RegisterToGroup(group, order, diutils.TypeOf[T](), factoryFn)
+}
@@ -1,7 +1,15 @@
 package di
 
 import (
+	"errors"
+	htmltemplate "html/template"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	texttemplate "text/template"
+	"time"
 
 	diutils "github.com/lcrux/go-di/di/di-utils"
 )
@@ -64,3 +72,491 @@ func TestRegisterValidation(t *testing.T) {
 		t.Fatal("expected error for duplicate registration")
 	}
 }
+
+type greeter interface {
+	Greet() string
+}
+
+type englishGreeter struct{}
+
+func (g *englishGreeter) Greet() string {
+	return "hello"
+}
+
+type greeterConsumer struct {
+	g greeter
+}
+
+func TestBind_ResolvesInterface(t *testing.T) {
+	c := NewContainer()
+
+	if err := Bind[greeter, *englishGreeter](c, Singleton, func() *englishGreeter { return &englishGreeter{} }); err != nil {
+		t.Fatalf("unexpected bind error: %v", err)
+	}
+	if err := Register[*greeterConsumer](c, Transient, func(g greeter) *greeterConsumer {
+		return &greeterConsumer{g: g}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	g, err := Resolve[greeter](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if g.Greet() != "hello" {
+		t.Fatalf("expected bound implementation, got %T", g)
+	}
+
+	consumer, err := Resolve[*greeterConsumer](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if consumer.g != g {
+		t.Fatal("expected the bound singleton to be injected")
+	}
+}
+
+func TestBind_RejectsNonAssignableImplementation(t *testing.T) {
+	c := NewContainer()
+
+	err := Bind[greeter, *depA](c, Transient, func() *depA { return &depA{} })
+	if !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("expected type mismatch error, got: %v", err)
+	}
+
+	if err := Bind[greeter, *englishGreeter](c, Transient, func() *depA { return &depA{} }); err == nil {
+		t.Fatal("expected error for factory not returning the implementation")
+	}
+	if _, err := Resolve[greeter](c, nil); err == nil {
+		t.Fatal("expected rejected bindings not to be registered")
+	}
+}
+
+func TestRegisterAlias_SharesSingleton(t *testing.T) {
+	c := NewContainer()
+
+	if err := RegisterWithKey[*depA](c, "default-cache", Singleton, func() *depA { return &depA{name: "cache"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.RegisterAlias("primary-cache", "default-cache"); err != nil {
+		t.Fatalf("unexpected alias error: %v", err)
+	}
+
+	viaTarget, err := ResolveWithKey[*depA](c, "default-cache", nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	viaAlias, err := ResolveWithKey[*depA](c, "primary-cache", nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if viaTarget != viaAlias {
+		t.Fatal("expected the alias to resolve the same singleton instance")
+	}
+}
+
+func TestRegisterAlias_InjectsThroughAliasedType(t *testing.T) {
+	c := NewContainer()
+
+	if err := RegisterWithKey[*depA](c, "named-a", Singleton, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.RegisterAlias(diutils.NameOf[*depA](), "named-a"); err != nil {
+		t.Fatalf("unexpected alias error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func(a *depA) *depB { return &depB{name: a.name} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Transient, func(a *depA, b *depB) *depC { return &depC{a: a, b: b} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	instance, err := Resolve[*depC](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	named, err := ResolveWithKey[*depA](c, "named-a", nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if instance.a != named || instance.b.name != "a" {
+		t.Fatal("expected the aliased singleton to be injected")
+	}
+}
+
+func TestRegisterAlias_Errors(t *testing.T) {
+	c := NewContainer()
+
+	if err := c.RegisterAlias("alias", "missing"); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected not registered error, got: %v", err)
+	}
+	if err := Register[*depA](c, Transient, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterWithKey[*depA](c, "taken", Transient, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := c.RegisterAlias("taken", diutils.NameOf[*depA]()); err == nil {
+		t.Fatal("expected error when the alias key is already registered")
+	}
+	if err := c.RegisterAlias(" ", diutils.NameOf[*depA]()); err == nil {
+		t.Fatal("expected error for empty alias key")
+	}
+}
+
+func TestMustRegister_PanicsOnDuplicate(t *testing.T) {
+	c := NewContainer()
+
+	MustRegister[*depA](c, Transient, func() *depA { return &depA{} })
+	MustRegisterWithKey[*depA](c, "depAKey", Transient, func() *depA { return &depA{} })
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Fatal("expected MustRegister to panic on duplicate registration")
+		}
+	}()
+	MustRegister[*depA](c, Transient, func() *depA { return &depA{} })
+}
+
+func TestRegisterMany(t *testing.T) {
+	c := NewContainer()
+
+	err := RegisterMany(c,
+		Registration{Type: diutils.TypeOf[*depA](), Scope: Singleton, FactoryFn: func() *depA { return &depA{name: "a"} }},
+		Registration{Type: diutils.TypeOf[*depB](), Key: "named-b", Scope: Transient, FactoryFn: func() *depB { return &depB{name: "b"} }},
+	)
+	if err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Resolve[*depA](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if _, err := ResolveWithKey[*depB](c, "named-b", nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+}
+
+func TestRegisterMany_ReportsFailingIndex(t *testing.T) {
+	c := NewContainer()
+
+	err := RegisterMany(c,
+		Registration{Type: diutils.TypeOf[*depA](), Scope: Transient, FactoryFn: func() *depA { return &depA{} }},
+		Registration{Type: diutils.TypeOf[*depB](), Key: "bad-b", Scope: Transient, FactoryFn: func() *depA { return &depA{} }},
+		Registration{Type: diutils.TypeOf[*depC](), Scope: Transient, FactoryFn: func() *depC { return &depC{} }},
+	)
+	if err == nil {
+		t.Fatal("expected error for the invalid registration")
+	}
+	if !strings.Contains(err.Error(), "registration 1 with key 'bad-b'") {
+		t.Fatalf("expected the failing index and key in the error, got: %v", err)
+	}
+	if _, err := Resolve[*depC](c, nil); err == nil {
+		t.Fatal("expected registrations after the failing one not to be applied")
+	}
+}
+
+func TestRegister_SameNameInDifferentPackages(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*texttemplate.Template](c, Singleton, func() *texttemplate.Template {
+		return texttemplate.New("text")
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*htmltemplate.Template](c, Singleton, func() *htmltemplate.Template {
+		return htmltemplate.New("html")
+	}); err != nil {
+		t.Fatalf("expected types from different packages not to collide, got: %v", err)
+	}
+
+	text, err := Resolve[*texttemplate.Template](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	html, err := Resolve[*htmltemplate.Template](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if text.Name() != "text" || html.Name() != "html" {
+		t.Fatalf("expected each type to resolve independently, got %s and %s", text.Name(), html.Name())
+	}
+}
+
+func TestRegisterIf_SkipsWhenFalse(t *testing.T) {
+	c := NewContainer()
+
+	if err := RegisterIf[*depA](c, false, Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterFunc[*depB](c, func() bool { return false }, Singleton, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Resolve[*depA](c, nil); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected depA not to be registered, got: %v", err)
+	}
+	if _, err := Resolve[*depB](c, nil); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected depB not to be registered, got: %v", err)
+	}
+
+	if err := RegisterIf[*depA](c, true, Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterFunc[*depB](c, func() bool { return true }, Singleton, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Resolve[*depA](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if _, err := Resolve[*depB](c, nil); err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+}
+
+func TestRegisterFirst_RegistersFirstMatch(t *testing.T) {
+	c := NewContainer()
+
+	err := RegisterFirst[*depA](c, Singleton,
+		Candidate{When: func() bool { return false }, FactoryFn: func() *depA { return &depA{name: "redis"} }},
+		Candidate{When: func() bool { return true }, FactoryFn: func() *depA { return &depA{name: "memory"} }},
+		Candidate{FactoryFn: func() *depA { return &depA{name: "fallback"} }},
+	)
+	if err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	a, err := Resolve[*depA](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if a.name != "memory" {
+		t.Fatalf("expected the first passing candidate to be registered, got %s", a.name)
+	}
+
+	if err := RegisterFirst[*depB](c, Singleton, Candidate{When: func() bool { return false }, FactoryFn: func() *depB { return &depB{} }}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Resolve[*depB](c, nil); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected no candidate to be registered, got: %v", err)
+	}
+}
+
+func TestRegisterScopedWithTTL_RecreatesExpiredInstances(t *testing.T) {
+	c := NewContainer()
+	disposed := int32(0)
+
+	if err := RegisterScopedWithTTL[*listenerDep](c, 20*time.Millisecond, func() *listenerDep {
+		return &listenerDep{called: &disposed}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	ctx := c.NewContext()
+	defer c.RemoveContext(ctx)
+
+	first, err := Resolve[*listenerDep](c, ctx)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	cached, err := Resolve[*listenerDep](c, ctx)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if cached != first {
+		t.Fatal("expected the instance to be cached before it expires")
+	}
+
+	time.Sleep(40 * time.Millisecond)
+
+	renewed, err := Resolve[*listenerDep](c, ctx)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if renewed == first {
+		t.Fatal("expected a new instance after expiry")
+	}
+	if atomic.LoadInt32(&disposed) != 1 {
+		t.Fatalf("expected the expired instance to be disposed, got %d", disposed)
+	}
+}
+
+func TestRegisterScopedWithTTL_RejectsNonPositiveTTL(t *testing.T) {
+	c := NewContainer()
+
+	if err := RegisterScopedWithTTL[*depA](c, 0, func() *depA { return &depA{} }); err == nil {
+		t.Fatal("expected error for a zero ttl")
+	}
+}
+
+func TestKeyOf_MatchesDefaultRegistrationKey(t *testing.T) {
+	c := NewContainer()
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	key := KeyOf[*depA]()
+	if key != "*github.com/lcrux/go-di/di.depA" {
+		t.Fatalf("unexpected key: %s", key)
+	}
+	if got := c.KeyFor(reflect.TypeOf(&depA{})); got != key {
+		t.Fatalf("expected KeyFor to return %s, got %s", key, got)
+	}
+	if _, ok := c.ScopeOf(key); !ok {
+		t.Fatalf("expected a service registered with key %s", key)
+	}
+
+	a, err := ResolveWithKey[*depA](c, key, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if a.name != "a" {
+		t.Fatalf("expected the registered service, got %s", a.name)
+	}
+
+	if err := RegisterWithKey[*depB](c, KeyOf[*depB](), Transient, func() *depB { return &depB{name: "b"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if b, err := Resolve[*depB](c, nil); err != nil || b.name != "b" {
+		t.Fatalf("expected Resolve to find the service registered with KeyOf, got %v, %v", b, err)
+	}
+}
+
+func TestRegisterImplementation_DefaultAndNamed(t *testing.T) {
+	c := NewContainer()
+	if err := RegisterImplementation[namedCache, memoryCache](c, "cache.memory", Singleton, func() memoryCache { return memoryCache{} }, WithDefault()); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterImplementation[namedCache, diskCache](c, "cache.disk", Singleton, func() diskCache { return diskCache{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	def, err := Resolve[namedCache](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if def.Name() != "memory" {
+		t.Fatalf("expected the default implementation, got %s", def.Name())
+	}
+
+	named, err := ResolveWithKey[namedCache](c, "cache.disk", nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if named.Name() != "disk" {
+		t.Fatalf("expected the named implementation, got %s", named.Name())
+	}
+
+	all, err := ResolveAll[namedCache](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if len(all) != 2 || all[0].Name() != "disk" || all[1].Name() != "memory" {
+		t.Fatalf("expected both implementations sorted by key, got %v", all)
+	}
+
+	if err := RegisterImplementation[namedCache, diskCache](c, "cache.other", Singleton, func() diskCache { return diskCache{} }, WithDefault()); err == nil {
+		t.Fatal("expected an error when registering a second default implementation")
+	}
+	if err := RegisterImplementation[namedCache, *depA](c, "cache.invalid", Singleton, func() *depA { return &depA{} }); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("expected ErrTypeMismatch, got %v", err)
+	}
+}
+
+func TestRegisterImplementation_DefaultTakenRegistersNothing(t *testing.T) {
+	c := NewContainer()
+	if err := RegisterWithKey[namedCache](c, KeyOf[namedCache](), Singleton, func() namedCache { return memoryCache{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if err := RegisterImplementation[namedCache, diskCache](c, "cache.disk", Singleton, func() diskCache { return diskCache{} }, WithDefault()); err == nil {
+		t.Fatal("expected an error when the default key is taken")
+	}
+	if _, exists := c.ScopeOf("cache.disk"); exists {
+		t.Fatal("expected the implementation not to be registered when its default alias fails")
+	}
+}
+
+func TestRegisterImplementation_ConcurrentDefaults(t *testing.T) {
+	c := NewContainer()
+	names := []string{"cache.a", "cache.b", "cache.c", "cache.d", "cache.e", "cache.f", "cache.g", "cache.h"}
+
+	var registered int32
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			if err := RegisterImplementation[namedCache, diskCache](c, name, Singleton, func() diskCache { return diskCache{} }, WithDefault()); err == nil {
+				atomic.AddInt32(&registered, 1)
+			}
+		}(name)
+	}
+	wg.Wait()
+
+	if registered != 1 {
+		t.Fatalf("expected a single default implementation to be registered, got %d", registered)
+	}
+	count := 0
+	for _, name := range names {
+		if _, exists := c.ScopeOf(name); exists {
+			count++
+		}
+	}
+	if count != 1 {
+		t.Fatalf("expected only the default implementation to be registered, got %d", count)
+	}
+}
+
+func TestRegisterImplementation_WithoutDefault(t *testing.T) {
+	c := NewContainer()
+	if err := RegisterImplementation[namedCache, diskCache](c, "cache.disk", Transient, func() diskCache { return diskCache{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if _, err := Resolve[namedCache](c, nil); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected ErrNotRegistered without a default implementation, got %v", err)
+	}
+	if all, err := ResolveAll[namedCache](c, nil); err != nil || len(all) != 1 {
+		t.Fatalf("expected the named implementation, got %v, %v", all, err)
+	}
+}
+
+func TestRegisterScopedOrSingleton(t *testing.T) {
+	c := NewContainer(WithStrictScopes())
+	created := int32(0)
+	if err := RegisterScopedOrSingleton[*depA](c, func() *depA {
+		atomic.AddInt32(&created, 1)
+		return &depA{}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if scope, _ := c.ScopeOf(KeyOf[*depA]()); scope != ScopedOrSingleton || scope.String() != "ScopedOrSingleton" {
+		t.Fatalf("expected the ScopedOrSingleton scope, got %v", scope)
+	}
+
+	global, err := Resolve[*depA](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if again, _ := Resolve[*depA](c, c.BackgroundContext()); again != global {
+		t.Fatal("expected one instance shared within the background context")
+	}
+
+	first, second := c.NewContext(), c.NewContext()
+	a1, err := Resolve[*depA](c, first)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	a2, err := Resolve[*depA](c, second)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if again, _ := Resolve[*depA](c, first); again != a1 {
+		t.Fatal("expected one instance shared within a context")
+	}
+	if a1 == a2 || a1 == global || a2 == global {
+		t.Fatal("expected distinct instances per context and for the background context")
+	}
+	if atomic.LoadInt32(&created) != 3 {
+		t.Fatalf("expected 3 instances to be created, got %d", created)
+	}
+}
@@ -0,0 +1,44 @@
+package di
+
+import (
+	"reflect"
+	"slices"
+)
+
+// RegistrationInfo describes a registered service, as reported by GetRegistration.
+type RegistrationInfo struct {
+	Key          string         // The registry key of the service, the target key for aliases
+	ServiceType  reflect.Type   // The type of the service
+	Scope        LifecycleScope // The lifecycle scope of the service
+	Dependencies []reflect.Type // The parameter types of the factory function, in order
+}
+
+// GetRegistration returns the details of the service registered with the given key,
+// and a boolean indicating whether the service is registered.
+//
+// The returned value is a copy, changing it does not change the registration.
+func (c *containerImpl) GetRegistration(key string) (RegistrationInfo, bool) {
+	entry, exists := c.registry.Get(key)
+	if !exists || key == loggerReflectedKey {
+		return RegistrationInfo{}, false
+	}
+	return RegistrationInfo{
+		Key:          entry.key,
+		ServiceType:  entry.serviceType,
+		Scope:        entry.scope,
+		Dependencies: slices.Clone(entry.factoryFnParams),
+	}, true
+}
+
+// GetRegistration returns the details of the service of type T registered with its default key,
+// and a boolean indicating whether the service is registered.
+//
+// Parameters:
+//
+// Container: The container instance holding the registration.
+func GetRegistration[T any](c Container) (RegistrationInfo, bool) {
+	if c == nil {
+		return RegistrationInfo{}, false
+	}
+	return c.GetRegistration(KeyOf[T]())
+}
@@ -0,0 +1,52 @@
+package di
+
+import (
+	"reflect"
+	"testing"
+
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+func TestContainer_GetRegistration(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Scoped, func(a *depA, b *depB, ctx LifecycleContext) *depC {
+		return &depC{a: a, b: b}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	info, ok := GetRegistration[*depC](c)
+	if !ok {
+		t.Fatal("expected the registration to be found")
+	}
+	if info.Key != KeyOf[*depC]() || info.ServiceType != diutils.TypeOf[*depC]() || info.Scope != Scoped {
+		t.Fatalf("unexpected registration info: %+v", info)
+	}
+	expected := []reflect.Type{diutils.TypeOf[*depA](), diutils.TypeOf[*depB](), diutils.TypeOf[LifecycleContext]()}
+	if !reflect.DeepEqual(info.Dependencies, expected) {
+		t.Fatalf("expected dependencies %v, got %v", expected, info.Dependencies)
+	}
+
+	// Changing the returned dependencies must not change the registration
+	info.Dependencies[0] = nil
+	if again, _ := c.GetRegistration(KeyOf[*depC]()); again.Dependencies[0] != diutils.TypeOf[*depA]() {
+		t.Fatalf("expected the registration to be unchanged, got %v", again.Dependencies)
+	}
+
+	if info, ok := GetRegistration[*depA](c); !ok || len(info.Dependencies) != 0 || info.Scope != Singleton {
+		t.Fatalf("unexpected registration info: %+v, %v", info, ok)
+	}
+	if _, ok := c.GetRegistration("missing"); ok {
+		t.Fatal("expected a missing registration not to be found")
+	}
+	if _, ok := GetRegistration[*depA](nil); ok {
+		t.Fatal("expected a nil container to report no registration")
+	}
+}
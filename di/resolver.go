@@ -1,6 +1,7 @@
 package di
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 
@@ -36,17 +37,14 @@ func Resolve[T any](c Container, ctx LifecycleContext) (T, error) {
 func ResolveWithKey[T any](c Container, key string, ctx LifecycleContext) (T, error) {
 	var zero T
 	if c == nil {
-		return zero, fmt.Errorf("container cannot be nil")
+		return zero, ErrNilContainer
 	}
 	if strings.TrimSpace(key) == "" {
 		return zero, fmt.Errorf("key cannot be empty")
 	}
 
-	// If the provided context is nil, use the container's background context
-	if ctx == nil {
-		ctx = c.BackgroundContext()
-	}
-
+	// If the provided context is nil, the container resolves within its background context,
+	// or within the context of the factory function it is injected into
 	inst, err := c.Resolve(key, ctx)
 	if err != nil {
 		return zero, fmt.Errorf("failed to resolve service with key %v: %w", key, err)
@@ -58,7 +56,7 @@ func ResolveWithKey[T any](c Container, key string, ctx LifecycleContext) (T, er
 
 	val, ok := inst.(T)
 	if !ok {
-		return zero, fmt.Errorf("resolved instance is not of type %v", diutils.TypeOf[T]())
+		return zero, fmt.Errorf("%w: resolved instance is not of type %v", ErrTypeMismatch, diutils.TypeOf[T]())
 	}
 	return val, nil
 }
@@ -81,6 +79,25 @@ func MustResolve[T any](c Container, ctx LifecycleContext) T {
 	return instance
 }
 
+// ResolveOrDefault resolves a service of type T from the container using the provided lifecycle context,
+// and returns the fallback if the service cannot be resolved, for any reason, or if the container is nil.
+// If the context is nil, it uses the container's background context.
+//
+// Parameters:
+//
+// Container: The container instance from which to resolve the service.
+//
+// LifecycleContext: The lifecycle context to use for resolving the service. If nil, the container's background context is used.
+//
+// Fallback: The value returned when the service cannot be resolved.
+func ResolveOrDefault[T any](c Container, ctx LifecycleContext, fallback T) T {
+	instance, err := Resolve[T](c, ctx)
+	if err != nil {
+		return fallback
+	}
+	return instance
+}
+
 // MustResolveWithKey resolves a service of type T from the container using the provided key and lifecycle context.
 // If the context is nil, it uses the container's background context.
 // Panics if the service cannot be resolved or parameters are invalid.
@@ -99,3 +116,154 @@ func MustResolveWithKey[T any](c Container, key string, ctx LifecycleContext) T
 	}
 	return instance
 }
+
+// ResolveWithKeyOrType resolves a service of type T from the container using the provided key,
+// falling back to the default key of T, as returned by KeyOf, when no service is registered with the key.
+// It eases the gradual adoption of keyed registrations, as consumers can ask for a key before it is registered.
+// If the context is nil, it uses the container's background context.
+//
+// Only a missing registration for the key itself falls back, other errors, such as a type mismatch,
+// a failing factory or a missing dependency of the keyed service, are returned as is.
+//
+// Parameters:
+//
+// Container: The container instance from which to resolve the service.
+//
+// Key: The key associated with the service to resolve.
+//
+// LifecycleContext: The lifecycle context to use for resolving the service. If nil, the container's background context is used.
+func ResolveWithKeyOrType[T any](c Container, key string, ctx LifecycleContext) (T, error) {
+	instance, err := ResolveWithKey[T](c, key, ctx)
+	var notRegistered *NotRegisteredError
+	if err == nil || !errors.As(err, &notRegistered) || notRegistered.Key != key || key == KeyOf[T]() {
+		return instance, err
+	}
+	return ResolveWithKey[T](c, KeyOf[T](), ctx)
+}
+
+// ResolveWithArgs creates an instance of the Transient service of type T, passing the given arguments to the
+// factory function parameters that are not registered services, such as a request ID or a tenant.
+// The arguments are matched by type, while the registered dependencies are resolved as usual.
+// If the context is nil, it uses the container's background context.
+//
+// It returns an error if the service is Singleton or Scoped, as caching an instance created from arguments would be wrong.
+//
+// Parameters:
+//
+// Container: The container instance from which to resolve the service.
+//
+// LifecycleContext: The lifecycle context to use for resolving the dependencies. If nil, the container's background context is used.
+//
+// Args: The values of the factory function parameters that are not registered services.
+func ResolveWithArgs[T any](c Container, ctx LifecycleContext, args ...interface{}) (T, error) {
+	var zero T
+	if c == nil {
+		return zero, ErrNilContainer
+	}
+
+	key := diutils.NameOf[T]()
+	inst, err := c.ResolveWithArgs(key, ctx, args...)
+	if err != nil {
+		return zero, fmt.Errorf("failed to resolve service with key %v: %w", key, err)
+	}
+
+	val, ok := inst.(T)
+	if !ok {
+		return zero, fmt.Errorf("%w: resolved instance is not of type %v", ErrTypeMismatch, diutils.TypeOf[T]())
+	}
+	return val, nil
+}
+
+// ResolveMany resolves the services of type T registered with the given keys, sharing the resolution of their common dependencies.
+// If the context is nil, it uses the container's background context.
+//
+// It returns the resolved instances keyed by their keys. If some services cannot be resolved, or are not of type T,
+// the other instances are still returned along with an error joining the errors of the failing services.
+//
+// Parameters:
+//
+// Container: The container instance from which to resolve the services.
+//
+// Keys: The keys associated with the services to resolve.
+//
+// LifecycleContext: The lifecycle context to use for resolving the services. If nil, the container's background context is used.
+func ResolveMany[T any](c Container, keys []string, ctx LifecycleContext) (map[string]T, error) {
+	if c == nil {
+		return nil, ErrNilContainer
+	}
+
+	instances, err := c.ResolveMany(keys, ctx)
+	errs := []error{err}
+	values := make(map[string]T, len(instances))
+	for key, inst := range instances {
+		val, ok := inst.(T)
+		if !ok {
+			errs = append(errs, fmt.Errorf("%w: service with key %s is not of type %v", ErrTypeMismatch, key, diutils.TypeOf[T]()))
+			continue
+		}
+		values[key] = val
+	}
+	return values, errors.Join(errs...)
+}
+
+// ResolveGroup resolves all the members of the named group as instances of type T, sorted by their order.
+// If the context is nil, it uses the container's background context.
+//
+// Parameters:
+//
+// Container: The container instance from which to resolve the group.
+//
+// Group: The name of the group to resolve.
+//
+// LifecycleContext: The lifecycle context to use for resolving the members. If nil, the container's background context is used.
+func ResolveGroup[T any](c Container, group string, ctx LifecycleContext) ([]T, error) {
+	if c == nil {
+		return nil, ErrNilContainer
+	}
+
+	instances, err := c.ResolveGroup(group, ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]T, 0, len(instances))
+	for _, inst := range instances {
+		val, ok := inst.(T)
+		if !ok {
+			return nil, fmt.Errorf("%w: group %s member is not of type %v", ErrTypeMismatch, group, diutils.TypeOf[T]())
+		}
+		values = append(values, val)
+	}
+	return values, nil
+}
+
+// ResolveAll resolves every service of type T, whatever its key, such as the implementations of an interface
+// registered with RegisterImplementation. If the context is nil, it uses the container's background context.
+//
+// It returns the instances sorted by key, or an empty slice if no service of type T is registered.
+//
+// Parameters:
+//
+// Container: The container instance from which to resolve the services.
+//
+// LifecycleContext: The lifecycle context to use for resolving the services. If nil, the container's background context is used.
+func ResolveAll[T any](c Container, ctx LifecycleContext) ([]T, error) {
+	if c == nil {
+		return nil, ErrNilContainer
+	}
+
+	instances, err := c.ResolveAll(diutils.TypeOf[T](), ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]T, 0, len(instances))
+	for _, inst := range instances {
+		val, ok := inst.(T)
+		if !ok {
+			return nil, fmt.Errorf("%w: service is not of type %v", ErrTypeMismatch, diutils.TypeOf[T]())
+		}
+		values = append(values, val)
+	}
+	return values, nil
+}
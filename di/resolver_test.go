@@ -1,8 +1,17 @@
 package di
 
 import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
+
+	diutils "github.com/lcrux/go-di/di/di-utils"
 )
 
 func TestResolve_TransientDifferentInstances(t *testing.T) {
@@ -134,8 +143,10 @@ func TestResolve_FactoryReceivesContainer(t *testing.T) {
 	if instance == nil || instance.c == nil {
 		t.Fatal("expected container to be injected")
 	}
-	if instance.c != c {
-		t.Fatal("expected injected container to be the same instance")
+	// The injected container is the container, bound to the context the factory is resolved within
+	scoped, ok := instance.c.(*scopedContainer)
+	if !ok || scoped.containerImpl != c || scoped.ctx != ctx {
+		t.Fatalf("expected injected container to be bound to the context, got %T", instance.c)
 	}
 }
 
@@ -178,14 +189,77 @@ func TestResolve_FactoryReceivesContainerAndLifecycleContext(t *testing.T) {
 	if instance == nil || instance.c == nil || instance.ctx == nil {
 		t.Fatal("expected container and lifecycle context to be injected")
 	}
-	if instance.c != c {
-		t.Fatal("expected injected container to be the same instance")
+	if scoped, ok := instance.c.(*scopedContainer); !ok || scoped.containerImpl != c {
+		t.Fatalf("expected injected container to be bound to the context, got %T", instance.c)
 	}
 	if instance.ctx.ID() != ctx.ID() {
 		t.Fatal("expected injected context to match the provided context")
 	}
 }
 
+func TestResolve_FactoryResolvesWithinItsContext(t *testing.T) {
+	c := NewContainer()
+	ctx := c.NewContext()
+
+	if err := Register[*depA](c, Scoped, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Scoped, func() *depB { return &depB{name: "b"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	// The factory resolves its dependency during construction, without passing the context
+	if err := Register[*depC](c, Scoped, func(c Container) *depC {
+		a, err := Resolve[*depA](c, nil)
+		if err != nil {
+			panic(err)
+		}
+		return &depC{a: a}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	// A singleton resolves within the background context, as it outlives the context it is resolved within
+	if err := Register[*depD](c, Singleton, func(c Container) *depD {
+		if _, err := Resolve[*depB](c, nil); err != nil {
+			panic(err)
+		}
+		return &depD{}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := c.ResolveMany([]string{diutils.NameOf[*depC](), diutils.NameOf[*depD]()}, ctx)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the nested resolutions not to deadlock")
+	}
+
+	instance, err := Resolve[*depC](c, ctx)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	a, err := Resolve[*depA](c, ctx)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if instance.a != a {
+		t.Fatal("expected the nested resolution to use the context of the factory")
+	}
+	if c.BackgroundContext().Has(diutils.NameOf[*depA]()) {
+		t.Fatal("expected the nested resolution not to use the background context")
+	}
+	if ctx.Has(diutils.NameOf[*depB]()) || !c.BackgroundContext().Has(diutils.NameOf[*depB]()) {
+		t.Fatal("expected the nested resolution of a singleton to use the background context")
+	}
+}
+
 func TestResolve_CircularDependenciesReturnsError(t *testing.T) {
 	c := NewContainer()
 	ctx := c.NewContext()
@@ -201,9 +275,18 @@ func TestResolve_CircularDependenciesReturnsError(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for circular dependency")
 	}
-	if !strings.Contains(err.Error(), "circular dependency") {
+	if !errors.Is(err, ErrCircularDependency) {
 		t.Fatalf("expected circular dependency error, got: %v", err)
 	}
+
+	var cycleErr *CircularDependencyError
+	if !errors.As(err, &cycleErr) {
+		t.Fatalf("expected a *CircularDependencyError, got: %T", err)
+	}
+	expected := []string{"*di.depA", "*di.depB", "*di.depA"}
+	if strings.Join(cycleErr.Path, ",") != strings.Join(expected, ",") {
+		t.Fatalf("expected cycle path %v, got %v", expected, cycleErr.Path)
+	}
 }
 
 func TestResolve_UnregisteredServiceReturnsError(t *testing.T) {
@@ -214,6 +297,14 @@ func TestResolve_UnregisteredServiceReturnsError(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error when resolving unregistered service")
 	}
+	if !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected not registered error, got: %v", err)
+	}
+
+	var notRegisteredErr *NotRegisteredError
+	if !errors.As(err, &notRegisteredErr) || notRegisteredErr.Key != diutils.NameOf[*depA]() {
+		t.Fatalf("expected the missing key to be reported, got: %v", err)
+	}
 }
 
 func TestResolve_UnregisteredDependencyReturnsError(t *testing.T) {
@@ -228,6 +319,9 @@ func TestResolve_UnregisteredDependencyReturnsError(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error when dependency is not registered")
 	}
+	if !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected not registered error, got: %v", err)
+	}
 }
 
 func TestResolveWithKey_CustomKey(t *testing.T) {
@@ -249,8 +343,8 @@ func TestResolveWithKey_CustomKey(t *testing.T) {
 
 func TestResolve_NilContainerReturnsError(t *testing.T) {
 	_, err := Resolve[*depA](nil, nil)
-	if err == nil {
-		t.Fatal("expected error when container is nil")
+	if !errors.Is(err, ErrNilContainer) {
+		t.Fatalf("expected nil container error, got: %v", err)
 	}
 }
 
@@ -360,9 +454,63 @@ func TestResolveWithKey_TypeMismatchReturnsError(t *testing.T) {
 	if err == nil {
 		t.Fatal("expected error for type mismatch")
 	}
-	if !strings.Contains(err.Error(), "not of type") {
+	if !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("expected type mismatch error, got: %v", err)
+	}
+}
+
+func TestResolveWithKeyOrType_FallsBackToTypeKey(t *testing.T) {
+	c := NewContainer()
+	ctx := c.NewContext()
+
+	if err := Register[*depA](c, Transient, func() *depA { return &depA{name: "default"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	instance, err := ResolveWithKeyOrType[*depA](c, "depA.primary", ctx)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if instance.name != "default" {
+		t.Fatalf("expected the default registration, got %s", instance.name)
+	}
+
+	if err := RegisterWithKey[*depA](c, "depA.primary", Transient, func() *depA { return &depA{name: "primary"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	instance, err = ResolveWithKeyOrType[*depA](c, "depA.primary", ctx)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if instance.name != "primary" {
+		t.Fatalf("expected the keyed registration, got %s", instance.name)
+	}
+}
+
+func TestResolveWithKeyOrType_DoesNotFallBackOnOtherErrors(t *testing.T) {
+	c := NewContainer()
+	ctx := c.NewContext()
+
+	if err := Register[*depB](c, Transient, func() *depB { return &depB{name: "default"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterWithKey[*depA](c, "mismatch.key", Transient, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	// The keyed service is registered, but one of its dependencies is not
+	if err := RegisterWithKey[*depB](c, "depB.broken", Transient, func(c *depC) *depB { return &depB{name: "broken"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if _, err := ResolveWithKeyOrType[*depB](c, "mismatch.key", ctx); !errors.Is(err, ErrTypeMismatch) {
 		t.Fatalf("expected type mismatch error, got: %v", err)
 	}
+	if _, err := ResolveWithKeyOrType[*depB](c, "depB.broken", ctx); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected the missing dependency error, got: %v", err)
+	}
+	if _, err := ResolveWithKeyOrType[*depC](c, "depC.primary", ctx); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected not registered error without a default registration, got: %v", err)
+	}
 }
 
 func TestMustResolve_PanicsOnNilContainer(t *testing.T) {
@@ -407,3 +555,867 @@ func TestMustResolve_Succeeds(t *testing.T) {
 		t.Fatal("expected to resolve instance")
 	}
 }
+
+func TestResolve_SingletonConcurrentConstructsOnce(t *testing.T) {
+	c := NewContainer()
+	created := int32(0)
+
+	if err := Register[*depA](c, Singleton, func() *depA {
+		atomic.AddInt32(&created, 1)
+		time.Sleep(10 * time.Millisecond)
+		return &depA{name: "singleton"}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	const goroutines = 100
+	results := make([]*depA, goroutines)
+	errs := make([]error, goroutines)
+	start := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			results[i], errs[i] = Resolve[*depA](c, c.NewContext())
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	for i := 0; i < goroutines; i++ {
+		if errs[i] != nil {
+			t.Fatalf("unexpected resolve error: %v", errs[i])
+		}
+		if results[i] != results[0] {
+			t.Fatal("expected all goroutines to receive the same singleton instance")
+		}
+	}
+	if got := atomic.LoadInt32(&created); got != 1 {
+		t.Fatalf("expected factory to be called once, got %d", got)
+	}
+}
+
+func TestResolve_SingletonConcurrentStoreReturnsCanonicalInstance(t *testing.T) {
+	c := NewContainer()
+	constructing := make(chan struct{})
+	proceed := make(chan struct{})
+	if err := Register[*depA](c, Singleton, func() *depA {
+		close(constructing)
+		<-proceed
+		return &depA{name: "constructed"}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Transient, func(a *depA, b *depB) *depC { return &depC{a: a, b: b} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	var resolved *depC
+	var resolveErr error
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		resolved, resolveErr = Resolve[*depC](c, nil)
+	}()
+
+	// Store an instance while the factory runs, it must win over the constructed one
+	<-constructing
+	canonical := &depA{name: "stored"}
+	if err := c.BackgroundContext().SetInstance(KeyOf[*depA](), reflect.ValueOf(canonical)); err != nil {
+		t.Fatalf("unexpected set instance error: %v", err)
+	}
+	close(proceed)
+	<-done
+
+	if resolveErr != nil {
+		t.Fatalf("unexpected resolve error: %v", resolveErr)
+	}
+	if resolved.a != canonical {
+		t.Fatalf("expected the dependency to be the stored instance, got %v", resolved.a)
+	}
+	a, err := Resolve[*depA](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if a != canonical {
+		t.Fatalf("expected the stored instance to be resolved, got %v", a)
+	}
+}
+
+func TestResolve_SingletonConcurrentDependentsShareInstance(t *testing.T) {
+	c := NewContainer()
+	created := int32(0)
+	if err := Register[*depA](c, Singleton, func() *depA {
+		atomic.AddInt32(&created, 1)
+		time.Sleep(5 * time.Millisecond)
+		return &depA{name: "singleton"}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Scoped, func(a *depA, b *depB) *depC { return &depC{a: a, b: b} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	const goroutines = 50
+	results := make([]*depC, goroutines)
+	errs := make([]error, goroutines)
+	start := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			results[i], errs[i] = Resolve[*depC](c, c.NewContext())
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	a, err := Resolve[*depA](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	for i := 0; i < goroutines; i++ {
+		if errs[i] != nil {
+			t.Fatalf("unexpected resolve error: %v", errs[i])
+		}
+		if results[i].a != a {
+			t.Fatal("expected all dependents to share the stored singleton instance")
+		}
+	}
+	if got := atomic.LoadInt32(&created); got != 1 {
+		t.Fatalf("expected factory to be called once, got %d", got)
+	}
+}
+
+// registerWideGraph registers width independent transient services, each taking the given time to construct,
+// and a *depA aggregator depending on all of them. It returns a pointer to the number of constructed services.
+func registerWideGraph(tb testing.TB, c Container, width int, work time.Duration) *int32 {
+	tb.Helper()
+	created := int32(0)
+
+	params := make([]reflect.Type, width)
+	for i := 0; i < width; i++ {
+		// Arrays of distinct lengths are distinct types, giving each service a unique key
+		serviceType := reflect.ArrayOf(i, reflect.TypeOf(byte(0)))
+		params[i] = serviceType
+
+		factoryType := reflect.FuncOf(nil, []reflect.Type{serviceType}, false)
+		factory := reflect.MakeFunc(factoryType, func(_ []reflect.Value) []reflect.Value {
+			atomic.AddInt32(&created, 1)
+			time.Sleep(work)
+			return []reflect.Value{reflect.New(serviceType).Elem()}
+		})
+		if err := c.Register(serviceType, diutils.NameOfType(serviceType), Transient, factory.Interface()); err != nil {
+			tb.Fatalf("unexpected register error: %v", err)
+		}
+	}
+
+	aggregatorType := reflect.FuncOf(params, []reflect.Type{reflect.TypeOf(&depA{})}, false)
+	aggregator := reflect.MakeFunc(aggregatorType, func(args []reflect.Value) []reflect.Value {
+		return []reflect.Value{reflect.ValueOf(&depA{name: strconv.Itoa(len(args))})}
+	})
+	if err := Register[*depA](c, Transient, aggregator.Interface()); err != nil {
+		tb.Fatalf("unexpected register error: %v", err)
+	}
+	return &created
+}
+
+func TestResolve_WideGraphResolvesConcurrently(t *testing.T) {
+	c := NewContainer()
+	created := registerWideGraph(t, c, 20, time.Millisecond)
+
+	wg := sync.WaitGroup{}
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			instance, err := Resolve[*depA](c, c.NewContext())
+			if err == nil && instance.name != "20" {
+				err = fmt.Errorf("expected aggregator to receive 20 dependencies, got %s", instance.name)
+			}
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(created); got != 200 {
+		t.Fatalf("expected 200 constructed dependencies, got %d", got)
+	}
+}
+
+func TestResolve_ConcurrentLevelPropagatesFactoryPanic(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Transient, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func() *depB { panic("boom") }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Transient, func(a *depA, b *depB) *depC { return &depC{a: a, b: b} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Fatalf("expected factory panic to reach the caller, got %v", r)
+		}
+	}()
+	_, _ = Resolve[*depC](c, nil)
+}
+
+func BenchmarkResolve_WideGraph(b *testing.B) {
+	for _, bc := range []struct {
+		name           string
+		maxConcurrency int
+	}{
+		{name: "Sequential", maxConcurrency: 1},
+		{name: "Parallel", maxConcurrency: 0},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			c := NewContainer(WithMaxConcurrency(bc.maxConcurrency))
+			registerWideGraph(b, c, 20, 100*time.Microsecond)
+
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := Resolve[*depA](c, nil); err != nil {
+					b.Fatalf("unexpected resolve error: %v", err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkResolve_TransientMultipleDependencies(b *testing.B) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Transient, func() *depA { return &depA{name: "a"} }); err != nil {
+		b.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func() *depB { return &depB{name: "b"} }); err != nil {
+		b.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Transient, func(a *depA, b *depB) *depC { return &depC{a: a, b: b} }); err != nil {
+		b.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depD](c, Transient, func(ca *depC) *depD { return &depD{c: ca} }); err != nil {
+		b.Fatalf("unexpected register error: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := Resolve[*depD](c, nil); err != nil {
+			b.Fatalf("unexpected resolve error: %v", err)
+		}
+	}
+}
+
+func BenchmarkResolve_SingletonCacheHitParallel(b *testing.B) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "a"} }); err != nil {
+		b.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := Resolve[*depA](c, nil); err != nil {
+		b.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := Resolve[*depA](c, nil); err != nil {
+				b.Errorf("unexpected resolve error: %v", err)
+				return
+			}
+		}
+	})
+}
+
+func BenchmarkResolve_RepeatedMiss(b *testing.B) {
+	for _, bc := range []struct {
+		name    string
+		options []ContainerOption
+	}{
+		{"NoCache", nil},
+		{"NegativeCache", []ContainerOption{WithNegativeCache()}},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			c := NewContainer(bc.options...)
+			key := KeyOf[*depA]()
+
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := c.Resolve(key, nil); err == nil {
+					b.Fatal("expected a not registered error")
+				}
+			}
+		})
+	}
+}
+
+// resolveWithTimeout resolves T in a separate goroutine and fails the test if it does not complete in time.
+func resolveWithTimeout[T any](t *testing.T, c Container, ctx LifecycleContext) T {
+	t.Helper()
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		value, err := Resolve[T](c, ctx)
+		done <- result{value: value, err: err}
+	}()
+
+	select {
+	case r := <-done:
+		if r.err != nil {
+			t.Fatalf("unexpected resolve error: %v", r.err)
+		}
+		return r.value
+	case <-time.After(2 * time.Second):
+		t.Fatal("resolution did not complete, possible deadlock")
+	}
+	var zero T
+	return zero
+}
+
+func TestResolve_SingletonFactoryResolvesSiblingSingleton(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depB](c, Singleton, func() *depB { return &depB{name: "b"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depA](c, Singleton, func(c Container) *depA {
+		b := MustResolve[*depB](c, nil)
+		return &depA{name: "a:" + b.name}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Singleton, func(a *depA, c Container) *depC {
+		return &depC{a: a, b: MustResolve[*depB](c, nil)}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	instance := resolveWithTimeout[*depC](t, c, nil)
+	if instance.a.name != "a:b" {
+		t.Fatalf("expected sibling singleton to be injected, got %s", instance.a.name)
+	}
+	if instance.b != MustResolve[*depB](c, nil) {
+		t.Fatal("expected sibling singleton to be shared")
+	}
+}
+
+func TestResolve_ScopedFactoryResolvesSameServiceInAnotherContext(t *testing.T) {
+	c := NewContainer()
+	ctx := c.NewContext()
+
+	if err := Register[*depA](c, Scoped, func(c Container, lctx LifecycleContext) *depA {
+		if lctx.ID() == c.BackgroundContext().ID() {
+			return &depA{name: "background"}
+		}
+		return &depA{name: "scoped:" + MustResolve[*depA](c, c.BackgroundContext()).name}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	instance := resolveWithTimeout[*depA](t, c, ctx)
+	if instance.name != "scoped:background" {
+		t.Fatalf("expected nested resolution in the background context, got %s", instance.name)
+	}
+}
+
+func TestResolve_ScopedConcurrentConstructsOncePerContext(t *testing.T) {
+	c := NewContainer()
+	ctx := c.NewContext()
+	created := int32(0)
+
+	if err := Register[*depA](c, Scoped, func() *depA {
+		atomic.AddInt32(&created, 1)
+		time.Sleep(10 * time.Millisecond)
+		return &depA{name: "scoped"}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := Resolve[*depA](c, ctx); err != nil {
+				t.Errorf("unexpected resolve error: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&created); got != 1 {
+		t.Fatalf("expected factory to be called once, got %d", got)
+	}
+}
+
+func TestResolve_MissingDependencyErrorIncludesPath(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depD](c, Transient, func(c *depC) *depD { return &depD{c: c} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Transient, func(a *depA, b *depB) *depC { return &depC{a: a, b: b} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func() *depB { return &depB{name: "b"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	_, err := Resolve[*depD](c, nil)
+	if !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected not registered error, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "failed to resolve *di.depD: *di.depC: *di.depA: service with key") {
+		t.Fatalf("expected the dependency path in the error, got: %v", err)
+	}
+}
+
+func TestResolve_FailingDependencyErrorIncludesPath(t *testing.T) {
+	c := NewContainer()
+	ctx := c.NewContext()
+
+	if err := Register[*depD](c, Transient, func(c *depC) *depD { return &depD{c: c} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Transient, func(a *depA, b *depB) *depC { return &depC{a: a, b: b} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func() *depB { return &depB{name: "b"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depA](c, Scoped, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	// Scoped instances cannot be stored in a closed context
+	_ = ctx.Shutdown()
+
+	_, err := Resolve[*depD](c, ctx)
+	if err == nil {
+		t.Fatal("expected error when resolving with a closed context")
+	}
+	if !strings.Contains(err.Error(), "failed to resolve *di.depD: *di.depC: *di.depA: ") {
+		t.Fatalf("expected the dependency path in the error, got: %v", err)
+	}
+}
+
+func TestResolve_CircularDependencyErrorIncludesPath(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depD](c, Transient, func(c *depC) *depD { return &depD{c: c} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depC](c, Transient, func(a *depA) *depC { return &depC{a: a} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depA](c, Transient, func(c *depC) *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	_, err := Resolve[*depD](c, nil)
+	if !errors.Is(err, ErrCircularDependency) {
+		t.Fatalf("expected circular dependency error, got: %v", err)
+	}
+	if !strings.Contains(err.Error(), "failed to resolve *di.depD: *di.depC: *di.depA: *di.depC: circular dependency detected: *di.depC -> *di.depA -> *di.depC") {
+		t.Fatalf("expected the visiting chain in the error, got: %v", err)
+	}
+}
+
+func TestResolveGroup_SortedByOrder(t *testing.T) {
+	c := NewContainer()
+
+	for _, member := range []struct {
+		name  string
+		order int
+	}{{"third", 30}, {"first", 10}, {"second", 20}, {"second-bis", 20}} {
+		name := member.name
+		if err := RegisterToGroup[*depA](c, "middlewares", member.order, func() *depA { return &depA{name: name} }); err != nil {
+			t.Fatalf("unexpected register error: %v", err)
+		}
+	}
+
+	members, err := ResolveGroup[*depA](c, "middlewares", nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	names := make([]string, len(members))
+	for i, member := range members {
+		names[i] = member.name
+	}
+	if strings.Join(names, ",") != "first,second,second-bis,third" {
+		t.Fatalf("expected members sorted by order, got %v", names)
+	}
+}
+
+func TestResolveGroup_Isolation(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Transient, func() *depA { return &depA{name: "plain"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterToGroup[*depA](c, "group-a", 0, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterToGroup[*depA](c, "group-b", 0, func(a *depA) *depA { return &depA{name: "b-" + a.name} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	groupA, err := ResolveGroup[*depA](c, "group-a", nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	groupB, err := ResolveGroup[*depA](c, "group-b", nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if len(groupA) != 1 || groupA[0].name != "a" {
+		t.Fatalf("expected group-a to only hold its member, got %v", groupA)
+	}
+	if len(groupB) != 1 || groupB[0].name != "b-plain" {
+		t.Fatalf("expected group-b to only hold its member, got %v", groupB)
+	}
+
+	plain, err := Resolve[*depA](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if plain.name != "plain" {
+		t.Fatalf("expected group members not to replace the type registration, got %s", plain.name)
+	}
+
+	empty, err := ResolveGroup[*depA](c, "missing", nil)
+	if err != nil || len(empty) != 0 {
+		t.Fatalf("expected an empty group, got %v, %v", empty, err)
+	}
+	if _, err := ResolveGroup[*depB](c, "group-a", nil); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("expected type mismatch error, got: %v", err)
+	}
+}
+
+func TestResolve_ConcurrentAfterPrecompute(t *testing.T) {
+	c := NewContainer()
+	registerWideGraph(t, c, 20, 0)
+
+	if err := c.PrecomputeDependencyTrees(); err != nil {
+		t.Fatalf("unexpected precompute error: %v", err)
+	}
+
+	keys := c.(*containerImpl).registry.Keys()
+	wg := sync.WaitGroup{}
+	errs := make(chan error, len(keys)*5)
+	for i := 0; i < 5; i++ {
+		for _, key := range keys {
+			wg.Add(1)
+			go func(key string) {
+				defer wg.Done()
+				if _, err := c.Resolve(key, nil); err != nil {
+					errs <- err
+				}
+			}(key)
+		}
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+}
+
+func TestPrecomputeDependencyTrees_ReportsErrors(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Transient, func(b *depB) *depA { return &depA{name: b.name} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depB](c, Transient, func(a *depA) *depB { return &depB{name: a.name} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*depD](c, Transient, func(c *depC) *depD { return &depD{c: c} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	err := c.PrecomputeDependencyTrees()
+	if !errors.Is(err, ErrCircularDependency) {
+		t.Fatalf("expected circular dependency error, got: %v", err)
+	}
+	if !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected not registered error, got: %v", err)
+	}
+}
+
+type tenantService struct {
+	tenant string
+	a      *depA
+}
+
+func TestResolveWithArgs_InjectsArguments(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*tenantService](c, Transient, func(a *depA, tenant string) *tenantService {
+		return &tenantService{tenant: tenant, a: a}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	service, err := ResolveWithArgs[*tenantService](c, nil, "acme")
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if service.tenant != "acme" || service.a == nil || service.a.name != "a" {
+		t.Fatalf("expected the argument and the registered dependency to be injected, got %+v", service)
+	}
+
+	if _, err := ResolveWithArgs[*tenantService](c, nil); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected not registered error for a missing argument, got: %v", err)
+	}
+	if _, err := ResolveWithArgs[*tenantService](c, nil, "acme", 42); err == nil {
+		t.Fatal("expected error for an unused argument")
+	}
+}
+
+func TestResolveWithArgs_RejectsCachedScopes(t *testing.T) {
+	c := NewContainer()
+
+	if err := Register[*tenantService](c, Singleton, func(tenant string) *tenantService {
+		return &tenantService{tenant: tenant}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if _, err := ResolveWithArgs[*tenantService](c, nil, "acme"); err == nil {
+		t.Fatal("expected error when resolving a singleton with arguments")
+	}
+}
+
+type valueConfig struct {
+	Name   string
+	Labels map[string]string
+}
+
+func TestResolve_ValueTypeSingletonIsShared(t *testing.T) {
+	c := NewContainer()
+	created := 0
+
+	if err := Register[valueConfig](c, Singleton, func() valueConfig {
+		created++
+		return valueConfig{Name: "config", Labels: map[string]string{"env": "test"}}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	first, err := Resolve[valueConfig](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	second, err := Resolve[valueConfig](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if created != 1 {
+		t.Fatalf("expected the factory to be called once, got %d", created)
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Fatalf("expected the same content, got %+v and %+v", first, second)
+	}
+
+	// Each resolution returns a copy, reference fields still point to the same data
+	first.Name = "changed"
+	first.Labels["env"] = "prod"
+	third, err := Resolve[valueConfig](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if third.Name != "config" {
+		t.Fatalf("expected the cached value not to change, got %s", third.Name)
+	}
+	if third.Labels["env"] != "prod" {
+		t.Fatal("expected the copies to share the same map")
+	}
+}
+
+func TestResolve_ValueTypeScopedIsSharedWithinContext(t *testing.T) {
+	c := NewContainer()
+	created := 0
+
+	if err := Register[valueConfig](c, Scoped, func() valueConfig {
+		created++
+		return valueConfig{Name: fmt.Sprintf("config-%d", created)}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	ctx1, ctx2 := c.NewContext(), c.NewContext()
+	first, err := Resolve[valueConfig](c, ctx1)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	second, err := Resolve[valueConfig](c, ctx1)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	other, err := Resolve[valueConfig](c, ctx2)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+
+	if first.Name != second.Name {
+		t.Fatalf("expected the same value within a context, got %s and %s", first.Name, second.Name)
+	}
+	if first.Name == other.Name || created != 2 {
+		t.Fatalf("expected a value per context, got %s and %s", first.Name, other.Name)
+	}
+}
+
+func TestResolveOrDefault(t *testing.T) {
+	fallback := &depA{name: "fallback"}
+
+	if got := ResolveOrDefault[*depA](nil, nil, fallback); got != fallback {
+		t.Fatal("expected the fallback for a nil container")
+	}
+
+	c := NewContainer()
+	if got := ResolveOrDefault[*depA](c, nil, fallback); got != fallback {
+		t.Fatal("expected the fallback for an unregistered service")
+	}
+
+	if err := Register[*depB](c, Transient, func(a *depA) *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if got := ResolveOrDefault[*depB](c, nil, nil); got != nil {
+		t.Fatal("expected the fallback for a service with a missing dependency")
+	}
+
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "registered"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if got := ResolveOrDefault[*depA](c, nil, fallback); got.name != "registered" {
+		t.Fatalf("expected the registered service, got %s", got.name)
+	}
+}
+
+func TestResolveMany_SharesOverlappingDependencies(t *testing.T) {
+	c := NewContainer()
+	var created int32
+	if err := Register[*depA](c, Transient, func() *depA {
+		atomic.AddInt32(&created, 1)
+		return &depA{name: "a"}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterWithKey[*depC](c, "c1", Transient, func(a *depA) *depC { return &depC{a: a} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterWithKey[*depC](c, "c2", Transient, func(a *depA) *depC { return &depC{a: a} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	instances, err := ResolveMany[*depC](c, []string{"c1", "c2"}, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if len(instances) != 2 {
+		t.Fatalf("expected 2 instances, got %d", len(instances))
+	}
+	if instances["c1"].a != instances["c2"].a {
+		t.Fatal("expected the overlapping dependency to be shared")
+	}
+	if got := atomic.LoadInt32(&created); got != 1 {
+		t.Fatalf("expected the overlapping dependency to be created once, got %d", got)
+	}
+}
+
+func TestResolveMany_ReportsPartialFailures(t *testing.T) {
+	c := NewContainer(WithStrictScopes())
+	if err := RegisterWithKey[*depA](c, "a", Transient, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterWithKey[*depD](c, "d", Transient, func(c *depC) *depD { return &depD{c: c} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := RegisterWithKey[*depB](c, "scoped", Scoped, func() *depB { return &depB{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	instances, err := c.ResolveMany([]string{"a", "d", "scoped", "missing"}, nil)
+	if err == nil {
+		t.Fatal("expected an error for the failing services")
+	}
+	if !errors.Is(err, ErrNotRegistered) || !errors.Is(err, ErrScopeRequired) {
+		t.Fatalf("expected the error to join the errors of the failing services, got %v", err)
+	}
+	for _, name := range []string{"depD", "depB", "missing"} {
+		if !strings.Contains(err.Error(), name) {
+			t.Fatalf("expected the error to report the failing service %s, got %v", name, err)
+		}
+	}
+	if len(instances) != 1 || instances["a"].(*depA).name != "a" {
+		t.Fatalf("expected only the resolvable service to be returned, got %v", instances)
+	}
+
+	if _, err := ResolveMany[*depD](c, []string{"a"}, nil); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("expected ErrTypeMismatch, got %v", err)
+	}
+}
+
+func TestContainer_ResolveType(t *testing.T) {
+	c := NewContainer()
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{name: "a"} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	inst, err := c.ResolveType(reflect.TypeOf(&depA{}), nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if a, ok := inst.(*depA); !ok || a.name != "a" {
+		t.Fatalf("expected the registered *depA, got %v", inst)
+	}
+
+	// A service of another type registered with the key of the requested type is a type mismatch
+	if err := RegisterWithKey[*depA](c, KeyOf[*depB](), Transient, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if _, err := c.ResolveType(reflect.TypeOf(&depB{}), nil); !errors.Is(err, ErrTypeMismatch) {
+		t.Fatalf("expected ErrTypeMismatch, got %v", err)
+	}
+
+	if _, err := c.ResolveType(reflect.TypeOf(&depC{}), nil); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected ErrNotRegistered, got %v", err)
+	}
+	if _, err := c.ResolveType(nil, nil); err == nil {
+		t.Fatal("expected an error for a nil type")
+	}
+}
@@ -0,0 +1,50 @@
+package di
+
+import "context"
+
+// scopeContextKey is the key of the lifecycle context attached to a context.Context by AttachScope.
+type scopeContextKey struct{}
+
+// traceable is implemented by the containers of this package, which start the construction spans of their resolutions
+// from a trace context.
+type traceable interface {
+	// withTrace returns a view of the container starting its construction spans from the given trace context.
+	withTrace(trace context.Context) Container
+}
+
+// AttachScope returns a copy of the parent context carrying the given lifecycle context,
+// so the scope flows through call chains taking a context.Context, such as gRPC handlers or background jobs.
+// Retrieve it with ScopeFromContext, or resolve services within it with ResolveCtx.
+func AttachScope(parent context.Context, lc LifecycleContext) context.Context {
+	if parent == nil {
+		parent = context.Background()
+	}
+	return context.WithValue(parent, scopeContextKey{}, lc)
+}
+
+// ScopeFromContext returns the lifecycle context attached to the given context by AttachScope,
+// and a boolean indicating whether one was found.
+func ScopeFromContext(ctx context.Context) (LifecycleContext, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	lc, ok := ctx.Value(scopeContextKey{}).(LifecycleContext)
+	return lc, ok && lc != nil
+}
+
+// ResolveCtx resolves a service of type T from the container within the lifecycle context attached to the given context
+// by AttachScope. If no lifecycle context is attached, it uses the container's background context.
+// When the container has a tracer, the construction spans start from the given context.
+//
+// Parameters:
+//
+// Container: The container instance from which to resolve the service.
+//
+// Context: The context carrying the lifecycle context to use for resolving the service.
+func ResolveCtx[T any](c Container, ctx context.Context) (T, error) {
+	lc, _ := ScopeFromContext(ctx)
+	if t, ok := c.(traceable); ok && ctx != nil {
+		c = t.withTrace(ctx)
+	}
+	return Resolve[T](c, lc)
+}
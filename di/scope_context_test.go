@@ -0,0 +1,70 @@
+package di
+
+import (
+	"context"
+	"testing"
+)
+
+type otherContextKey struct{}
+
+func TestAttachScope_RoundTrip(t *testing.T) {
+	c := NewContainer()
+	lctx := c.NewContext()
+
+	// The scope survives values added on top of it, and cancellations
+	ctx := AttachScope(context.Background(), lctx)
+	ctx = context.WithValue(ctx, otherContextKey{}, "value")
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	scope, ok := ScopeFromContext(ctx)
+	if !ok || scope != lctx {
+		t.Fatalf("expected the attached scope, got %v, %t", scope, ok)
+	}
+	if ctx.Value(otherContextKey{}) != "value" {
+		t.Fatal("expected the other values to be preserved")
+	}
+
+	// The innermost scope wins
+	inner := c.NewContext()
+	if scope, _ := ScopeFromContext(AttachScope(ctx, inner)); scope != inner {
+		t.Fatalf("expected the innermost scope, got %v", scope)
+	}
+
+	if _, ok := ScopeFromContext(context.Background()); ok {
+		t.Fatal("expected no scope in a context without one")
+	}
+	if _, ok := ScopeFromContext(AttachScope(context.Background(), nil)); ok {
+		t.Fatal("expected no scope when a nil scope is attached")
+	}
+}
+
+func TestResolveCtx(t *testing.T) {
+	c := NewContainer()
+	if err := Register[*depA](c, Scoped, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	lctx := c.NewContext()
+	ctx := context.WithValue(AttachScope(context.Background(), lctx), otherContextKey{}, "value")
+	instance, err := ResolveCtx[*depA](c, ctx)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if scoped, _ := Resolve[*depA](c, lctx); scoped != instance {
+		t.Fatal("expected the service to be resolved within the attached scope")
+	}
+
+	// Without a scope, the background context is used
+	background, err := ResolveCtx[*depA](c, context.Background())
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if background == instance || !c.BackgroundContext().Has(KeyOf[*depA]()) {
+		t.Fatal("expected the service to be resolved within the background context")
+	}
+
+	if _, err := ResolveCtx[*depA](nil, ctx); err != ErrNilContainer {
+		t.Fatalf("expected ErrNilContainer, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package di
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// isSliceDependency indicates whether the given factory parameter is a slice injected with every service
+// assignable to its element type, such as []Handler. A registration of the slice type itself takes precedence,
+// and a slice without any assignable service is a regular dependency, reported as not registered.
+func (c *containerImpl) isSliceDependency(paramType reflect.Type, key string) bool {
+	if paramType.Kind() != reflect.Slice || c.isProvided(key) {
+		return false
+	}
+	return len(c.sliceElementKeys(paramType)) > 0
+}
+
+// sliceElementKeys returns the keys of the services assignable to the element type of the given slice type, sorted by key.
+// Aliases are skipped, so each registration is injected once, and group members are only injected with their group.
+func (c *containerImpl) sliceElementKeys(sliceType reflect.Type) []string {
+	elemType := sliceType.Elem()
+	keys := make([]string, 0)
+	c.registry.Range(func(key string, entry *containerEntry) bool {
+		if entry.key == key && !strings.HasPrefix(key, groupKeyPrefix) && entry.serviceType.AssignableTo(elemType) {
+			keys = append(keys, key)
+		}
+		return true
+	})
+	sort.Strings(keys)
+	return keys
+}
+
+// newSliceEntry creates the dependency tree entry standing for a slice dependency, depending on the services assignable
+// to its element type.
+func (c *containerImpl) newSliceEntry(paramType reflect.Type, key string) *containerEntry {
+	entry := &containerEntry{
+		serviceType: paramType,
+		key:         key,
+		scope:       Transient,
+		slice:       true,
+	}
+	for _, elemKey := range c.sliceElementKeys(paramType) {
+		entry.factoryFnParams = append(entry.factoryFnParams, paramType.Elem())
+		entry.factoryFnParamKeys = append(entry.factoryFnParamKeys, elemKey)
+	}
+	return entry
+}
+
+// buildSlice returns a slice of the given type holding the instance returned by resolve for each of the given keys,
+// which are the keys of the services assignable to its element type.
+func (c *containerImpl) buildSlice(sliceType reflect.Type, keys []string, resolve func(key string) (reflect.Value, error)) (reflect.Value, error) {
+	slice := reflect.MakeSlice(sliceType, 0, len(keys))
+	for _, key := range keys {
+		instance, err := resolve(key)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if !instance.IsValid() {
+			instance = reflect.Zero(sliceType.Elem())
+		}
+		slice = reflect.Append(slice, instance)
+	}
+	return slice, nil
+}
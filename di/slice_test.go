@@ -0,0 +1,106 @@
+package di
+
+import (
+	"errors"
+	"testing"
+)
+
+type cacheSet struct {
+	caches []namedCache
+}
+
+func cacheNames(caches []namedCache) []string {
+	names := make([]string, 0, len(caches))
+	for _, cache := range caches {
+		names = append(names, cache.Name())
+	}
+	return names
+}
+
+func TestSlice_InjectsAssignableServices(t *testing.T) {
+	c := NewContainer()
+	registerNamedCaches(t, c)
+	if err := Register[*diskCache](c, Transient, func() *diskCache { return &diskCache{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	// Aliases are not injected twice
+	if err := c.RegisterAlias("cache.default", "cache.fast"); err != nil {
+		t.Fatalf("unexpected alias error: %v", err)
+	}
+	if err := Register[*cacheSet](c, Transient, func(caches []namedCache) *cacheSet { return &cacheSet{caches: caches} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatalf("expected the slice dependency to be valid, got: %v", err)
+	}
+
+	set, err := Resolve[*cacheSet](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	// The services are injected in the order of their keys
+	names := cacheNames(set.caches)
+	if len(names) != 3 || names[0] != "disk" || names[1] != "memory" || names[2] != "disk" {
+		t.Fatalf("expected the services assignable to the element type, got %v", names)
+	}
+	if fast, _ := ResolveWithKey[namedCache](c, "cache.fast", nil); set.caches[1] != fast {
+		t.Fatal("expected the singleton instances to be injected")
+	}
+
+	deps := c.DependencyGraph()[KeyOf[*cacheSet]()]
+	if len(deps) != 3 || deps[0] != KeyOf[*diskCache]() || deps[1] != "cache.fast" || deps[2] != "cache.slow" {
+		t.Fatalf("expected the slice dependency to depend on the assignable services, got %v", deps)
+	}
+}
+
+func TestSlice_RegisteredSliceTakesPrecedence(t *testing.T) {
+	c := NewContainer()
+	registerNamedCaches(t, c)
+	if err := Register[[]namedCache](c, Singleton, func() []namedCache { return []namedCache{diskCache{}} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*cacheSet](c, Transient, func(caches []namedCache) *cacheSet { return &cacheSet{caches: caches} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	set, err := Resolve[*cacheSet](c, nil)
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if names := cacheNames(set.caches); len(names) != 1 || names[0] != "disk" {
+		t.Fatalf("expected the registered slice to be injected, got %v", names)
+	}
+}
+
+func TestSlice_WithoutAssignableServices(t *testing.T) {
+	c := NewContainer()
+	if err := Register[*cacheSet](c, Transient, func(caches []namedCache) *cacheSet { return &cacheSet{caches: caches} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if err := c.Validate(); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected not registered error, got: %v", err)
+	}
+	if _, err := Resolve[*cacheSet](c, nil); !errors.Is(err, ErrNotRegistered) {
+		t.Fatalf("expected not registered error, got: %v", err)
+	}
+}
+
+func TestSlice_ResolveWithArgs(t *testing.T) {
+	c := NewContainer()
+	registerNamedCaches(t, c)
+	if err := Register[*cacheSet](c, Transient, func(caches []namedCache, name string) *cacheSet {
+		return &cacheSet{caches: caches}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	set, err := ResolveWithArgs[*cacheSet](c, nil, "name")
+	if err != nil {
+		t.Fatalf("unexpected resolve error: %v", err)
+	}
+	if names := cacheNames(set.caches); len(names) != 2 {
+		t.Fatalf("expected the services assignable to the element type, got %v", names)
+	}
+}
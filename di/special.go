@@ -0,0 +1,203 @@
+package di
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	diutils "github.com/lcrux/go-di/di/di-utils"
+)
+
+// specialType describes a custom special type registered with RegisterSpecial.
+type specialType struct {
+	typ      reflect.Type                                        // The type injected by the provider
+	provider func(c Container, ctx LifecycleContext) interface{} // Supplies the value injected for each resolution
+}
+
+// RegisterSpecial registers a special type, injected into factory functions without being created by a factory,
+// like Container and LifecycleContext. It is meant for ambient values, such as the user of the current request.
+//
+// When a factory function declares a parameter of the given type, the provider is called with the container
+// and the lifecycle context of the resolution, and its result is injected. A nil result injects the zero value.
+// Special types are satisfied dependencies for Validate, and are not cached.
+// It returns an error if the container is frozen, or if the type is already registered.
+func (c *containerImpl) RegisterSpecial(t reflect.Type, provider func(c Container, ctx LifecycleContext) interface{}) error {
+	if t == nil {
+		return fmt.Errorf("special type cannot be nil")
+	}
+	if provider == nil {
+		return fmt.Errorf("provider of special type %s cannot be nil", t.String())
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	key := diutils.NameOfType(t)
+	if c.closed.Load() {
+		return fmt.Errorf("cannot register special type %s: %w", t.String(), ErrContainerClosed)
+	}
+	if c.frozen {
+		return fmt.Errorf("cannot register special type %s: %w", t.String(), ErrFrozen)
+	}
+	if _, exists := c.registry.Get(key); exists || c.isSpecial(key) {
+		return fmt.Errorf("service already registered with key: %s", key)
+	}
+
+	c.specials.Set(key, specialType{typ: t, provider: provider})
+	c.invalidateDependencyTrees()
+
+	c.logger.Debugf("Registered special type: %s", t.String())
+	return nil
+}
+
+// RegisterSpecial registers the special type T, whose values are supplied by the provider for each resolution.
+// See Container.RegisterSpecial.
+//
+// Parameters:
+//
+// Container: The container instance in which to register the special type.
+//
+// Provider: The function supplying the value injected for each resolution.
+func RegisterSpecial[T any](c Container, provider func(c Container, ctx LifecycleContext) T) error {
+	if c == nil {
+		return ErrNilContainer
+	}
+	if provider == nil {
+		return fmt.Errorf("provider of special type %s cannot be nil", diutils.TypeOf[T]().String())
+	}
+	return c.RegisterSpecial(diutils.TypeOf[T](), func(c Container, ctx LifecycleContext) interface{} {
+		return provider(c, ctx)
+	})
+}
+
+// isSpecial indicates whether the given key is the key of a special type, which is Container, LifecycleContext,
+// or a type registered with RegisterSpecial.
+func (c *containerImpl) isSpecial(key string) bool {
+	_, ok := c.specialTypeOf(key)
+	return ok
+}
+
+// specialTypeOf returns the special type with the given key, and a boolean indicating whether the key is special.
+func (c *containerImpl) specialTypeOf(key string) (reflect.Type, bool) {
+	switch key {
+	case containerReflectedKey:
+		return diutils.TypeOf[Container](), true
+	case lifecycleContextReflectedKey:
+		return diutils.TypeOf[LifecycleContext](), true
+	}
+	if special, ok := c.specials.Get(key); ok {
+		return special.typ, true
+	}
+	return nil, false
+}
+
+// resolveSpecial checks if the given key corresponds to a special type (Container, LifecycleContext, or a custom special type).
+// If it does, it returns the corresponding instance and true. Otherwise, it returns nil and false.
+func (c *containerImpl) resolveSpecial(key string, ctx LifecycleContext) (interface{}, bool) {
+	switch key {
+	case containerReflectedKey:
+		return c.boundTo(ctx), true
+	case lifecycleContextReflectedKey:
+		return ctx, true
+	}
+	if special, ok := c.specials.Get(key); ok {
+		return special.provider(c, ctx), true
+	}
+	return nil, false
+}
+
+// scopedContainer is the container injected into the factory functions resolved within a lifecycle context
+// other than the background context. Its resolutions default to that context instead of the background context,
+// so the services a factory function resolves while it runs, passing a nil context, share its scope.
+// Once the context is closed, its resolutions default to the background context again.
+//
+// When tracing, it is also injected into the factory functions resolved within the background context,
+// with a nil context, and its Resolve method starts the construction spans from the span of the factory function.
+type scopedContainer struct {
+	*containerImpl
+	ctx   LifecycleContext
+	trace context.Context // Context the construction spans start from, nil for root spans
+}
+
+// boundTo returns the container resolving services within the given lifecycle context by default.
+// It returns the container itself for a nil context or the background context.
+func (c *containerImpl) boundTo(ctx LifecycleContext) Container {
+	if ctx == nil || ctx.ID() == c.BackgroundContext().ID() {
+		return c
+	}
+	return &scopedContainer{containerImpl: c, ctx: ctx}
+}
+
+// tracedAt returns the container resolving services within the given lifecycle context by default,
+// or the background context if it is nil, and starting their construction spans from the given trace context.
+func (c *containerImpl) tracedAt(ctx LifecycleContext, trace context.Context) Container {
+	if ctx != nil && ctx.ID() == c.BackgroundContext().ID() {
+		ctx = nil
+	}
+	return &scopedContainer{containerImpl: c, ctx: ctx, trace: trace}
+}
+
+// withTrace returns the container resolving services within the background context by default,
+// and starting their construction spans from the given trace context.
+func (c *containerImpl) withTrace(trace context.Context) Container {
+	return c.tracedAt(nil, trace)
+}
+
+// withTrace returns the container resolving services within the same context by default,
+// and starting their construction spans from the given trace context.
+func (s *scopedContainer) withTrace(trace context.Context) Container {
+	return &scopedContainer{containerImpl: s.containerImpl, ctx: s.ctx, trace: trace}
+}
+
+// context returns the given lifecycle context if it is not nil, and the bound context otherwise.
+func (s *scopedContainer) context(ctx LifecycleContext) LifecycleContext {
+	if ctx != nil || s.ctx == nil || s.ctx.IsClosed() {
+		return ctx
+	}
+	return s.ctx
+}
+
+// Resolve resolves the service identified by the given key within the given context, or the bound context if it is nil.
+func (s *scopedContainer) Resolve(key string, ctx LifecycleContext) (interface{}, error) {
+	return s.resolveTraced(key, s.context(ctx), s.trace)
+}
+
+// ResolveType resolves the service registered with the key of the given type within the given context,
+// or the bound context if it is nil.
+func (s *scopedContainer) ResolveType(t reflect.Type, ctx LifecycleContext) (interface{}, error) {
+	return s.containerImpl.ResolveType(t, s.context(ctx))
+}
+
+// ResolveWithArgs resolves the service identified by the given key with the given arguments within the given context,
+// or the bound context if it is nil.
+func (s *scopedContainer) ResolveWithArgs(key string, ctx LifecycleContext, args ...interface{}) (interface{}, error) {
+	return s.containerImpl.ResolveWithArgs(key, s.context(ctx), args...)
+}
+
+// ResolveMany resolves the services registered with the given keys within the given context, or the bound context if it is nil.
+func (s *scopedContainer) ResolveMany(keys []string, ctx LifecycleContext) (map[string]interface{}, error) {
+	return s.containerImpl.ResolveMany(keys, s.context(ctx))
+}
+
+// ResolveGroup resolves the members of the given group within the given context, or the bound context if it is nil.
+func (s *scopedContainer) ResolveGroup(group string, ctx LifecycleContext) ([]interface{}, error) {
+	return s.containerImpl.ResolveGroup(group, s.context(ctx))
+}
+
+// ResolveAll resolves the services of the given type within the given context, or the bound context if it is nil.
+func (s *scopedContainer) ResolveAll(serviceType reflect.Type, ctx LifecycleContext) ([]interface{}, error) {
+	return s.containerImpl.ResolveAll(serviceType, s.context(ctx))
+}
+
+// ResolutionPlan reports the resolution plan of the given key within the given context, or the bound context if it is nil.
+func (s *scopedContainer) ResolutionPlan(key string, ctx LifecycleContext) ([]PlanStep, error) {
+	return s.containerImpl.ResolutionPlan(key, s.context(ctx))
+}
+
+// specialValue returns the reflected value of the given special instance, or the zero value of the type if it is nil.
+func specialValue(typ reflect.Type, instance interface{}) reflect.Value {
+	if instance == nil {
+		return reflect.Zero(typ)
+	}
+	return reflect.ValueOf(instance)
+}
@@ -0,0 +1,78 @@
+package di
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+type requestID string
+
+type requestHandler struct {
+	id requestID
+	a  *depA
+}
+
+func TestContainer_RegisterSpecial(t *testing.T) {
+	c := NewContainer()
+	ids := map[string]requestID{}
+	if err := RegisterSpecial[requestID](c, func(_ Container, ctx LifecycleContext) requestID {
+		return ids[ctx.ID()]
+	}); err != nil {
+		t.Fatalf("unexpected register special error: %v", err)
+	}
+	if err := Register[*depA](c, Singleton, func() *depA { return &depA{} }); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+	if err := Register[*requestHandler](c, Scoped, func(id requestID, a *depA) *requestHandler {
+		return &requestHandler{id: id, a: a}
+	}); err != nil {
+		t.Fatalf("unexpected register error: %v", err)
+	}
+
+	if err := c.Validate(); err != nil {
+		t.Fatalf("expected the special type to satisfy validation, got %v", err)
+	}
+
+	first, second := c.NewContext(), c.NewContext()
+	ids[first.ID()], ids[second.ID()] = "req-1", "req-2"
+	for ctx, expected := range map[LifecycleContext]requestID{first: "req-1", second: "req-2"} {
+		handler, err := Resolve[*requestHandler](c, ctx)
+		if err != nil {
+			t.Fatalf("unexpected resolve error: %v", err)
+		}
+		if handler.id != expected || handler.a == nil {
+			t.Fatalf("expected request ID %q, got %+v", expected, handler)
+		}
+	}
+
+	if id, err := Resolve[requestID](c, first); err != nil || id != "req-1" {
+		t.Fatalf("expected the special type to be resolvable, got %q, %v", id, err)
+	}
+	if dot, err := c.DependencyGraphDOT(); err != nil || !strings.Contains(dot, `"`+KeyOf[requestID]()+`" [shape=box, style=dashed];`) {
+		t.Fatalf("expected the special type to be a dashed node, got %v\n%s", err, dot)
+	}
+}
+
+func TestContainer_RegisterSpecial_Errors(t *testing.T) {
+	c := NewContainer()
+	provider := func(Container, LifecycleContext) requestID { return "" }
+
+	if err := RegisterSpecial[requestID](c, provider); err != nil {
+		t.Fatalf("unexpected register special error: %v", err)
+	}
+	if err := RegisterSpecial[requestID](c, provider); err == nil {
+		t.Fatal("expected an error registering a special type twice")
+	}
+	if err := Register[requestID](c, Transient, func() requestID { return "" }); err == nil {
+		t.Fatal("expected an error registering a service with the key of a special type")
+	}
+	if err := RegisterSpecial[*depA](c, nil); err == nil {
+		t.Fatal("expected an error for a nil provider")
+	}
+
+	c.Freeze()
+	if err := RegisterSpecial[*depB](c, func(Container, LifecycleContext) *depB { return nil }); !errors.Is(err, ErrFrozen) {
+		t.Fatalf("expected ErrFrozen, got %v", err)
+	}
+}